@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"text/tabwriter"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/monitor"
+)
+
+// benchmarkResult is what --benchmark measured for one detector.
+type benchmarkResult struct {
+	Lines      int
+	Matches    int
+	Bytes      int64
+	Elapsed    time.Duration
+	Allocs     uint64 // heap allocations during the run
+	AllocBytes uint64
+}
+
+func (r benchmarkResult) MatchRate() float64 {
+	if r.Lines == 0 {
+		return 0
+	}
+	return float64(r.Matches) / float64(r.Lines)
+}
+
+func (r benchmarkResult) LinesPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Lines) / r.Elapsed.Seconds()
+}
+
+func (r benchmarkResult) NsPerLine() float64 {
+	if r.Lines == 0 {
+		return 0
+	}
+	return float64(r.Elapsed.Nanoseconds()) / float64(r.Lines)
+}
+
+func (r benchmarkResult) perLine(n uint64) float64 {
+	if r.Lines == 0 {
+		return 0
+	}
+	return float64(n) / float64(r.Lines)
+}
+
+// benchmarkDetector runs det over every line of r the way a monitor's scan
+// loop does: the same scanner buffer, and context extraction for matches.
+func benchmarkDetector(det detectors.Detector, r io.Reader) (benchmarkResult, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, monitor.MaxScanTokenSize), monitor.MaxScanTokenSize)
+	extractor, _ := det.(detectors.ContextExtractor)
+
+	var res benchmarkResult
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		res.Lines++
+		res.Bytes += int64(len(line)) + 1
+		if det.Detect(line) {
+			res.Matches++
+			if extractor != nil {
+				extractor.GetContext(line)
+			}
+		}
+	}
+	res.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+	res.Allocs = after.Mallocs - before.Mallocs
+	res.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	return res, scanner.Err()
+}
+
+// runBenchmarks runs each monitor's detector over path and writes a table of
+// the results to w. With cpuProfile set, a CPU profile of the runs is written
+// there for `go tool pprof`.
+func runBenchmarks(w io.Writer, cfg *config.Config, path, cpuProfile string) error {
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "MONITOR\tLINES\tMATCHES\tMATCH RATE\tLINES/SEC\tNS/LINE\tALLOCS/LINE\tB/LINE\t")
+	for _, monCfg := range cfg.Monitors {
+		det, err := newDetector(monCfg)
+		if err != nil {
+			return fmt.Errorf("monitor '%s': %w", monCfg.Name, err)
+		}
+		if det == nil {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\t-\t-\t-\t\n", monCfg.Name) // sequence only
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		res, err := benchmarkDetector(det, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("monitor '%s': %w", monCfg.Name, err)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f%%\t%.0f\t%.0f\t%.2f\t%.1f\t\n",
+			monCfg.Name, res.Lines, res.Matches, res.MatchRate()*100,
+			res.LinesPerSec(), res.NsPerLine(), res.perLine(res.Allocs), res.perLine(res.AllocBytes))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/detectors"
+)
+
+func TestBenchmarkDetector(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 10; i++ {
+		if i%3 == 0 {
+			input.WriteString("ERROR: disk full\n")
+		} else {
+			input.WriteString("INFO: all good\n")
+		}
+	}
+
+	det, err := detectors.NewGenericDetector("ERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := benchmarkDetector(det, strings.NewReader(input.String()))
+	if err != nil {
+		t.Fatalf("benchmarkDetector failed: %v", err)
+	}
+	if res.Lines != 10 || res.Matches != 4 {
+		t.Errorf("Expected 10 lines and 4 matches, got %d and %d", res.Lines, res.Matches)
+	}
+	if res.Bytes != int64(input.Len()) {
+		t.Errorf("Expected %d bytes, got %d", input.Len(), res.Bytes)
+	}
+	if res.MatchRate() != 0.4 {
+		t.Errorf("Expected match rate 0.4, got %v", res.MatchRate())
+	}
+	if res.LinesPerSec() <= 0 || res.NsPerLine() <= 0 {
+		t.Errorf("Expected a throughput, got %v lines/sec and %v ns/line", res.LinesPerSec(), res.NsPerLine())
+	}
+}
+
+func TestRunBenchmarks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("error one\nfine\nerror two\nfine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Monitors: []config.MonitorConfig{
+		{Name: "app", Type: "file", Path: path, Pattern: "error"},
+		{Name: "steps", Type: "file", Path: path, Sequence: []string{"one", "two"}, SequenceWithin: "1m"},
+	}}
+
+	var out strings.Builder
+	if err := runBenchmarks(&out, cfg, path, ""); err != nil {
+		t.Fatalf("runBenchmarks failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got:\n%s", out.String())
+	}
+	fields := strings.Fields(lines[1])
+	if fields[0] != "app" || fields[1] != "4" || fields[2] != "2" || fields[3] != "50.00%" {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); fields[0] != "steps" || fields[1] != "-" {
+		t.Errorf("Expected no measurement for a sequence-only monitor, got %q", lines[2])
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/getsentry/sentry-go"
+)
+
+// statusRecorder wraps an http.RoundTripper and records the outcome of the last request,
+// since the Sentry transports do not report delivery errors to the caller.
+type statusRecorder struct {
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	status int
+	err    error
+}
+
+func (r *statusRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+	if resp != nil {
+		r.status = resp.StatusCode
+	}
+	return resp, err
+}
+
+// checkDSN sends a single test event to Sentry using a synchronous transport
+// and returns the round-trip latency if Sentry accepted it.
+func checkDSN(sentryCfg config.SentryConfig, timeout time.Duration) (time.Duration, error) {
+	if sentryCfg.DSN == "" {
+		return 0, fmt.Errorf("Sentry DSN is required. Set via --dsn flag, SENTRY_DSN environment variable, or config file")
+	}
+
+	recorder := &statusRecorder{base: http.DefaultTransport}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         sentryCfg.DSN,
+		Environment: sentryCfg.Environment,
+		Release:     sentryCfg.Release,
+		Dist:        sentryCfg.Dist,
+		Transport:   sentry.NewHTTPSyncTransport(),
+		HTTPClient: &http.Client{
+			Transport: recorder,
+			Timeout:   timeout,
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("invalid DSN: %w", err)
+	}
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	var eventID *sentry.EventID
+	start := time.Now()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("alert_type", "dsn_check")
+		scope.SetLevel(sentry.LevelInfo)
+		eventID = hub.CaptureMessage("sentrylogmon: DSN connectivity check")
+	})
+	latency := time.Since(start)
+
+	if eventID == nil {
+		return latency, fmt.Errorf("test event was dropped before sending")
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.err != nil {
+		return latency, fmt.Errorf("failed to reach Sentry: %w", recorder.err)
+	}
+	if recorder.status == 0 {
+		return latency, fmt.Errorf("no request was sent to Sentry")
+	}
+	if recorder.status < 200 || recorder.status > 299 {
+		return latency, fmt.Errorf("Sentry rejected the test event: HTTP %d", recorder.status)
+	}
+	return latency, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+)
+
+// newMockSentry starts a server that mimics cmd/sentry-mock's envelope endpoint.
+func newMockSentry(t *testing.T, status int) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var bodies []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/envelope/") && !strings.HasSuffix(r.URL.Path, "/store/") {
+			http.NotFound(w, r)
+			return
+		}
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "bad gzip", http.StatusBadRequest)
+				return
+			}
+			defer gz.Close()
+			reader = gz
+		}
+		body, _ := io.ReadAll(reader)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":"c9938dbd8dd54b778e741a8d0869aacd"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), bodies...)
+	}
+}
+
+func mockDSN(srv *httptest.Server) string {
+	return strings.Replace(srv.URL, "http://", "http://public@", 1) + "/1"
+}
+
+func TestCheckDSN_Success(t *testing.T) {
+	srv, received := newMockSentry(t, http.StatusOK)
+
+	latency, err := checkDSN(config.SentryConfig{DSN: mockDSN(srv), Environment: "test"}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("checkDSN failed: %v", err)
+	}
+	if latency <= 0 {
+		t.Errorf("Expected positive latency, got %v", latency)
+	}
+
+	bodies := received()
+	if len(bodies) != 1 {
+		t.Fatalf("Expected 1 event received, got %d", len(bodies))
+	}
+	if !strings.Contains(bodies[0], "dsn_check") {
+		t.Errorf("Expected event to be tagged alert_type=dsn_check, got: %s", bodies[0])
+	}
+}
+
+func TestCheckDSN_Rejected(t *testing.T) {
+	srv, _ := newMockSentry(t, http.StatusForbidden)
+
+	_, err := checkDSN(config.SentryConfig{DSN: mockDSN(srv)}, 5*time.Second)
+	if err == nil {
+		t.Fatal("Expected error for rejected event")
+	}
+	if !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected error to mention status 403, got: %v", err)
+	}
+}
+
+func TestCheckDSN_Unreachable(t *testing.T) {
+	srv, _ := newMockSentry(t, http.StatusOK)
+	dsn := mockDSN(srv)
+	srv.Close()
+
+	if _, err := checkDSN(config.SentryConfig{DSN: dsn}, 2*time.Second); err == nil {
+		t.Fatal("Expected error for unreachable server")
+	}
+}
+
+func TestCheckDSN_Empty(t *testing.T) {
+	if _, err := checkDSN(config.SentryConfig{}, time.Second); err == nil {
+		t.Fatal("Expected error for empty DSN")
+	}
+}
@@ -1,14 +1,23 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/exporters"
+	"github.com/angch/sentrylogmon/fingerprint"
+	"github.com/angch/sentrylogmon/sources"
 	"github.com/angch/sentrylogmon/sysstat"
 	"gopkg.in/yaml.v3"
 )
@@ -17,48 +26,327 @@ type SentryConfig struct {
 	DSN         string `yaml:"dsn"`
 	Environment string `yaml:"environment"`
 	Release     string `yaml:"release"`
+	Dist        string `yaml:"dist"` // distribution of the release, e.g. a build number
+	// SampleRate is the fraction of events the Sentry SDK sends, between 0 and 1.
+	// Unset (0) sends all events.
+	SampleRate float64 `yaml:"sample_rate"`
+}
+
+// validateSampleRate checks that a sample_rate is within [0, 1].
+func (s SentryConfig) validateSampleRate() error {
+	if s.SampleRate < 0 || s.SampleRate > 1 {
+		return fmt.Errorf("sample_rate must be between 0 and 1, got %g", s.SampleRate)
+	}
+	return nil
+}
+
+// SSHConfig configures how an ssh monitor connects to the remote host.
+type SSHConfig struct {
+	KeyFile        string `yaml:"key_file"`
+	KnownHostsFile string `yaml:"known_hosts_file"`
+	Port           int    `yaml:"port"`
+}
+
+// K8sConfig selects the pods a k8s monitor streams logs from. Inside a cluster
+// the API server and credentials default to the pod's service account.
+type K8sConfig struct {
+	Namespace     string `yaml:"namespace"`      // default: the pod's own namespace in-cluster, otherwise all
+	LabelSelector string `yaml:"label_selector"` // e.g. app=web
+	Container     string `yaml:"container"`      // default: all containers
+	APIServer     string `yaml:"api_server"`     // e.g. https://10.0.0.1:6443
+	TokenFile     string `yaml:"token_file"`
+	CAFile        string `yaml:"ca_file"`
+	Resync        string `yaml:"resync"` // how often to look for new pods (default: 10s)
+}
+
+// DecodeFieldConfig names a JSON field holding base64 or hex encoded text.
+type DecodeFieldConfig struct {
+	Name     string `yaml:"name"`
+	Encoding string `yaml:"encoding"` // base64 or hex
+}
+
+// ForwardSyslogConfig forwards the events a monitor reports to a syslog
+// collector as well, e.g. the local rsyslog.
+type ForwardSyslogConfig struct {
+	Address  string `yaml:"address"`  // as for a syslog monitor, e.g. udp:127.0.0.1:514
+	Facility string `yaml:"facility"` // default: user
+	Format   string `yaml:"format"`   // rfc3164 (default) or rfc5424
+	Tag      string `yaml:"tag"`      // default: sentrylogmon
+}
+
+// ForwardWebhookConfig posts the events a monitor reports to an HTTP
+// endpoint as well, e.g. a Splunk HEC or a chat webhook relay.
+type ForwardWebhookConfig struct {
+	URL      string            `yaml:"url"`
+	Headers  map[string]string `yaml:"headers"`  // e.g. Authorization
+	Compress string            `yaml:"compress"` // gzip, or unset to send JSON as is
+}
+
+// UserFromConfig names the context keys holding the Sentry user of an event.
+type UserFromConfig struct {
+	ID       string `yaml:"id"`
+	Email    string `yaml:"email"`
+	Username string `yaml:"username"`
 }
 
 type MonitorConfig struct {
-	Name            string       `yaml:"name"`
-	Type            string       `yaml:"type"`            // file, journalctl, dmesg, command
-	Path            string       `yaml:"path"`            // for file
-	Args            string       `yaml:"args"`            // for journalctl or command
-	Pattern         string       `yaml:"pattern"`         // regex pattern for custom format
-	Format          string       `yaml:"format"`          // dmesg, nginx, custom (default: custom if pattern set)
-	ExcludePattern  string       `yaml:"exclude_pattern"` // regex pattern to exclude from reporting
-	MaxInactivity   string       `yaml:"max_inactivity"`  // max duration of inactivity before alerting
-	RateLimitBurst  int          `yaml:"rate_limit_burst"`
-	RateLimitWindow string       `yaml:"rate_limit_window"`
-	Sentry          SentryConfig `yaml:"sentry"` // Override global Sentry config
+	Name              string                 `yaml:"name"`
+	Environments      []string               `yaml:"environments"`    // only run when the Sentry environment is listed (empty: always)
+	Type              string                 `yaml:"type"`            // file, journalctl, dmesg, command, syslog, ssh, k8s, http
+	Path              string                 `yaml:"path"`            // for file, or [user@]host:/path for ssh
+	Addresses         []string               `yaml:"addresses"`       // for syslog: more addresses to listen on besides path, e.g. [udp:0.0.0.0:514, tcp:0.0.0.0:514]
+	IgnoreGlobs       []string               `yaml:"ignore_globs"`    // for file globs: skip matches of these patterns (file name, or full path if it has a /)
+	FromStart         bool                   `yaml:"from_start"`      // for file: read existing content before tailing
+	Follow            string                 `yaml:"follow"`          // for file: name (default) or descriptor, as in tail --follow
+	Replay            bool                   `yaml:"replay"`          // for file: read once, pacing lines by their recorded timestamps
+	ReplaySpeed       float64                `yaml:"replay_speed"`    // for replay: speed-up factor (default: 1, real time)
+	Args              string                 `yaml:"args"`            // for journalctl or command
+	PollInterval      string                 `yaml:"poll_interval"`   // for command: run it this often instead of streaming its output (e.g. 5m for zpool status)
+	PollNewLines      bool                   `yaml:"poll_new_lines"`  // for poll_interval: only pass on lines that were not in the previous run's output
+	Pattern           string                 `yaml:"pattern"`         // regex pattern for custom format
+	Format            string                 `yaml:"format"`          // dmesg, nginx, custom (default: custom if pattern set)
+	ExcludePattern    string                 `yaml:"exclude_pattern"` // regex pattern to exclude from reporting
+	ExtraPattern      string                 `yaml:"extra_pattern"`   // regex matched in addition to a built-in format's own pattern (e.g. timeout for dmesg)
+	PatternFile       string                 `yaml:"pattern_file"`    // file of regexes, one per line (# comments), any of which reports a line
+	ExcludeFile       string                 `yaml:"exclude_file"`    // file of regexes, one per line, any of which excludes a line
+	Redact            []string               `yaml:"redact"`          // regexes replaced with [REDACTED] before lines are reported
+	MaxInactivity     string                 `yaml:"max_inactivity"`  // max duration of inactivity before alerting
+	ReadTimeout       string                 `yaml:"read_timeout"`    // restart a streaming source whose read returns nothing this long (e.g. a hung ssh)
+	ExpectPattern     string                 `yaml:"expect_pattern"`  // regex for a healthy line that must keep appearing
+	ExpectWithin      string                 `yaml:"expect_within"`   // max duration without expect_pattern before alerting
+	Sequence          []string               `yaml:"sequence"`        // regexes that must match in order to report one combined event
+	SequenceWithin    string                 `yaml:"sequence_within"` // max duration from the first to the last line of sequence
+	ContextPattern    string                 `yaml:"context_pattern"` // regex whose named groups are remembered and added to later events
+	ContextWithin     string                 `yaml:"context_within"`  // how long context_pattern captures are kept (default: 1m)
+	RateLimitBurst    int                    `yaml:"rate_limit_burst"`
+	RateLimitWindow   string                 `yaml:"rate_limit_window"`
+	RateLimitAlerts   bool                   `yaml:"rate_limit_alerts"`   // warn when rate_limit_burst starts dropping events, and note when it stops
+	RealertInterval   string                 `yaml:"realert_interval"`    // min time between two max_inactivity or expect_within warnings, against a flapping source
+	Cooldown          string                 `yaml:"cooldown"`            // after an event, only count matches for this long, then send one summary
+	DedupLifetime     bool                   `yaml:"dedup_lifetime"`      // send one event per distinct message for the whole run, only counting repeats
+	Warmup            string                 `yaml:"warmup"`              // after start, only count matches for this long (e.g. boot-time noise)
+	GroupBy           string                 `yaml:"group_by"`            // context key (e.g. JSON field or named regex group) to batch lines by
+	MaxEventBytes     int                    `yaml:"max_event_bytes"`     // serialized event size limit before trimming (default: 1MB)
+	MaxFingerprints   int                    `yaml:"max_fingerprints"`    // max distinct group_by keys buffered at once, and dedup_lifetime messages remembered (default: 100)
+	MaxBatchLines     int                    `yaml:"max_batch_lines"`     // lines per batched event before it is sent early (default: 1000)
+	MaxBatchBytes     int                    `yaml:"max_batch_bytes"`     // bytes per batched event before it is sent early (default: 256KB)
+	MaxBatchAge       string                 `yaml:"max_batch_age"`       // send a batch this long after its first line even if lines keep coming (e.g. 30s)
+	GroupWindow       string                 `yaml:"group_window"`        // batch timestamped lines this close to the batch's first line (default: 5s; 0: same timestamp only)
+	IncludeRawLine    *bool                  `yaml:"include_raw_line"`    // attach the raw_line extra (default: true)
+	AttachSysstat     *bool                  `yaml:"attach_sysstat"`      // attach the host's "Server State" context (default: true)
+	RawLineMaxBytes   int                    `yaml:"raw_line_max_bytes"`  // truncate the raw_line extra to this many bytes (default: no limit)
+	MinLineLength     int                    `yaml:"min_line_length"`     // skip lines shorter than this many bytes after trimming whitespace
+	CollapseRepeats   bool                   `yaml:"collapse_repeats"`    // pass on runs of identical consecutive lines as one, counted in the repeated_lines extra
+	IgnoreSubstrings  []string               `yaml:"ignore_substrings"`   // literal substrings; lines containing any are skipped before matching
+	NoMatchLines      int                    `yaml:"no_match_lines"`      // send a one-time hint after this many lines without a single match (a likely wrong pattern)
+	StartupDeadline   string                 `yaml:"startup_deadline"`    // warn once if not a single line is read this long after start (e.g. a wrong path)
+	StartupUnhealthy  bool                   `yaml:"startup_unhealthy"`   // with startup_deadline: also fail /healthz for this source until a line is read
+	FormatDrift       bool                   `yaml:"format_drift"`        // warn when the share of lines in the expected format (JSON, or timestamped) drops sharply
+	MatchDebug        bool                   `yaml:"match_debug"`         // tag events with why their lines matched (match_reason: pattern, context or continuation)
+	AllowMatchAll     bool                   `yaml:"allow_match_all"`     // allow a pattern that matches the empty string, and so every line (e.g. .*)
+	ReadBufferSize    int                    `yaml:"read_buffer_size"`    // for file: bytes per read; for syslog: socket receive buffer
+	ParallelDetect    int                    `yaml:"parallel_detect"`     // run the detector on this many lines at once, for CPU-heavy formats (e.g. json)
+	MessageFrom       string                 `yaml:"message_from"`        // context key (e.g. JSON "msg") used as the event message instead of the raw line
+	FingerprintFrom   string                 `yaml:"fingerprint_from"`    // context key (e.g. JSON "error_code") whose value groups events in Sentry
+	TitleFrom         string                 `yaml:"title_from"`          // first_line, fingerprint or pattern: a concise title, set as the title tag
+	MinSendLevel      string                 `yaml:"min_send_level"`      // drop events whose final Sentry level is below this (debug, info, warning, error, fatal)
+	BatchSeparator    string                 `yaml:"batch_separator"`     // joins the lines of a batched event (default: newline)
+	BatchOrder        string                 `yaml:"batch_order"`         // asc (default, oldest line first) or desc
+	StripSyslogHeader bool                   `yaml:"strip_syslog_header"` // report only the message of "Oct 11 22:14:15 host tag[pid]: message" lines
+	TraceIDFrom       string                 `yaml:"trace_id_from"`       // context key holding a trace ID, to link events to Sentry traces
+	SpanIDFrom        string                 `yaml:"span_id_from"`        // context key holding a span ID, used with trace_id_from
+	UserFrom          *UserFromConfig        `yaml:"user_from"`           // context keys for the event's Sentry user, e.g. {id: user_id, email: email}
+	DecodeField       *DecodeFieldConfig     `yaml:"decode_field"`        // for json: field decoded before matching and context extraction
+	StatusClasses     []string               `yaml:"status_classes"`      // for nginx: report access log lines with these statuses, e.g. [4xx, 5xx] (default: 5xx)
+	IgnoreStatus      []int                  `yaml:"ignore_status"`       // for nginx: status codes never reported, e.g. [404]
+	OnlyAfter         string                 `yaml:"only_after"`          // RFC3339; skip lines timestamped before this
+	OnlyBefore        string                 `yaml:"only_before"`         // RFC3339; skip lines timestamped after this
+	UntimedLines      string                 `yaml:"untimed_lines"`       // include (default) or exclude lines without a timestamp when only_after/only_before is set
+	Context           map[string]interface{} `yaml:"context"`             // static context attached to every event (e.g. runbook_url, team)
+	Tags              map[string]string      `yaml:"tags"`                // static Sentry tags attached to every event
+	TagAllowlist      []string               `yaml:"tag_allowlist"`       // extracted fields also set as Sentry tags, e.g. [region, status] ("*": all)
+	TagDenylist       []string               `yaml:"tag_denylist"`        // fields and labels never set as tags, e.g. [request_id]
+	K8s               K8sConfig              `yaml:"k8s"`                 // for k8s: which pods to stream logs from
+	SSH               SSHConfig              `yaml:"ssh"`                 // for ssh: key and known_hosts to connect with
+	Sentry            SentryConfig           `yaml:"sentry"`              // Override global Sentry config
+	SentryTargets     []SentryConfig         `yaml:"sentry_targets"`      // additional Sentry projects to mirror events to
+	Routes            []RouteConfig          `yaml:"routes"`              // Sentry projects to send events to by level instead
+	ForwardSyslog     *ForwardSyslogConfig   `yaml:"forward_syslog"`      // also send reported events to this syslog collector
+	ForwardWebhook    *ForwardWebhookConfig  `yaml:"forward_webhook"`     // also post reported events to this HTTP endpoint
+	OutputFile        string                 `yaml:"output_file"`         // also append reported events to this file as JSON lines
+	OutputMaxSize     int                    `yaml:"output_max_size"`     // rotate output_file before it grows past this many bytes (default: no limit)
+	OutputMaxFiles    int                    `yaml:"output_max_files"`    // rotated output files to keep (default: 5)
+	OutputRotateDaily bool                   `yaml:"output_rotate_daily"` // also rotate output_file on the first event of each day
+
+	// Escape control characters in reported lines as \xNN (e.g. a tab as \x09), against broken rendering and log injection
+	NormalizeControlChars bool `yaml:"normalize_control_chars"`
+
+	// Group events in Sentry by their first line normalized with these: whitespace, numbers, uuids, hex
+	FingerprintNormalizers []string `yaml:"fingerprint_normalizers"`
+
+	// Field and label values longer than this are kept in context only, not set as tags (default: 200)
+	MaxTagValueLength int `yaml:"max_tag_value_length"`
+
+	// For file: attach this many lines before and after each event's first line, reread from the file, as context.log
+	ContextAttachLines int `yaml:"context_attach_lines"`
 }
 
+// RouteConfig sends events whose level is between MinLevel and MaxLevel to
+// another Sentry project, e.g. errors to a paging project and warnings to a
+// dashboard one. Either bound may be left open.
+type RouteConfig struct {
+	MinLevel     string `yaml:"min_level"`
+	MaxLevel     string `yaml:"max_level"`
+	SentryConfig `yaml:",inline"`
+}
+
+// routeLevels orders the levels a route can be bounded by.
+var routeLevels = map[string]int{"debug": 0, "info": 1, "warning": 2, "warn": 2, "error": 3, "fatal": 4}
+
 type Config struct {
 	Sentry      SentryConfig    `yaml:"sentry"`
 	Monitors    []MonitorConfig `yaml:"monitors"`
 	Verbose     bool            `yaml:"-"`
 	OneShot     bool            `yaml:"-"`
 	MetricsPort int             `yaml:"metrics_port"`
+	// Write up to this many redacted matched lines to SampleOut instead of reporting (no DSN needed)
+	SampleMatches int    `yaml:"-"`
+	SampleOut     string `yaml:"-"`
+	// Run each monitor's detector over this file and report throughput instead of monitoring (no DSN needed)
+	Benchmark string `yaml:"-"`
+	// /healthz fails if no monitor has read a line for this long (default: disabled)
+	HealthStaleness string `yaml:"health_staleness"`
+
+	// Send an info event to Sentry when sentrylogmon starts and stops cleanly
+	LifecycleEvents bool `yaml:"lifecycle_events"`
+
+	// Global rate limit shared by all monitors, applied after each monitor's own limit
+	GlobalRateLimitBurst  int    `yaml:"global_rate_limit_burst"`
+	GlobalRateLimitWindow string `yaml:"global_rate_limit_window"` // default: 1s
+
+	// Sentry environment by hostname prefix, when neither sentry.environment nor --environment is set
+	EnvironmentFromHostname []HostnameEnvironment `yaml:"environment_from_hostname"`
+
+	// JSON object of deploy metadata (e.g. commit, build time, deployer) written by CI,
+	// attached to every event as the "Deploy" context
+	DeployInfoFile string `yaml:"deploy_info_file"`
+	// DeployInfo is the content of DeployInfoFile, read when the configuration is loaded
+	DeployInfo map[string]interface{} `yaml:"-"`
+}
+
+// ReadDeployInfo reads a deploy info file, which must hold a JSON object.
+func ReadDeployInfo(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deploy_info_file: %w", err)
+	}
+	var info map[string]interface{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("deploy_info_file %s is not a JSON object: %w", path, err)
+	}
+	return info, nil
+}
+
+// HostnameEnvironment sets the Sentry environment of hosts whose name starts
+// with Prefix, e.g. {prefix: prod-, environment: production}.
+type HostnameEnvironment struct {
+	Prefix      string `yaml:"prefix"`
+	Environment string `yaml:"environment"`
+}
+
+// hostname is os.Hostname, replaced in tests.
+var hostname = os.Hostname
+
+// environmentFromHostname returns the environment of the first rule whose
+// prefix the hostname starts with (ignoring case), or "" if none does.
+func environmentFromHostname(rules []HostnameEnvironment) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	host, err := hostname()
+	if err != nil {
+		log.Printf("Cannot apply environment_from_hostname: %v", err)
+		return ""
+	}
+	host = strings.ToLower(host)
+	for _, r := range rules {
+		if strings.HasPrefix(host, strings.ToLower(r.Prefix)) {
+			return r.Environment
+		}
+	}
+	return ""
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
 var (
-	configFile     = flag.String("config", "", "Path to configuration file")
+	configFiles    = newListFlag("config", "Path to configuration file; repeat to overlay files in order (later settings win, monitors are added)")
 	dsn            = flag.String("dsn", os.Getenv("SENTRY_DSN"), "Sentry DSN")
 	useDmesg       = flag.Bool("dmesg", false, "Monitor dmesg output")
 	inputFile      = flag.String("file", "", "Monitor a log file")
+	follow         = flag.String("follow", "name", "File follow mode: name (reopen on rotation) or descriptor (keep reading the rotated file)")
+	replay         = flag.Bool("replay", false, "Replay --file once at the pace of its recorded timestamps instead of tailing it")
+	replaySpeed    = flag.Float64("replay-speed", 1, "Speed-up factor for --replay (e.g. 60 replays an hour in a minute)")
 	journalctl     = flag.String("journalctl", "", "Monitor journalctl output (pass args)")
 	command        = flag.String("command", "", "Monitor custom command output")
 	syslogAddr     = flag.String("syslog", "", "Syslog address (e.g. udp:127.0.0.1:5514 or :5514)")
-	format         = flag.String("format", "", "Detector format (dmesg, nginx, custom)")
+	sshTarget      = flag.String("ssh", "", "Tail a remote file over SSH (e.g. user@host:/var/log/syslog)")
+	sshKey         = flag.String("ssh-key", "", "Private key file for --ssh")
+	sshKnownHosts  = flag.String("ssh-known-hosts", "", "known_hosts file for --ssh")
+	format         = flag.String("format", "", "Detector format (dmesg, nginx, nginx-error, json, haproxy, custom)")
 	pattern        = flag.String("pattern", "Error", "Pattern to match (case sensitive)")
 	excludePattern = flag.String("exclude", "", "Pattern to exclude from reporting (case sensitive)")
+	redact         = flag.String("redact", "", "Pattern replaced with [REDACTED] before lines are reported")
 	environment    = flag.String("environment", "production", "Sentry environment")
 	release        = flag.String("release", "", "Sentry release version")
+	dist           = flag.String("dist", "", "Sentry release distribution (e.g. build number)")
 	verbose        = flag.Bool("verbose", false, "Verbose logging")
 	oneshot        = flag.Bool("oneshot", false, "Run once and exit when input stream ends")
+	sampleMatches  = flag.Int("sample-matches", 0, "Read inputs once, write up to N redacted matched lines to --sample-out and exit without reporting to Sentry")
+	sampleOut      = flag.String("sample-out", "sentrylogmon-sample.txt", "File written by --sample-matches")
+	benchmark      = flag.String("benchmark", "", "Run each monitor's detector over this file, report lines/sec, match rate and allocations, and exit without reporting to Sentry")
 	metricsPort    = flag.Int("metrics-port", 0, "Port to expose Prometheus metrics (0 to disable)")
+	healthStale    = flag.String("health-staleness", "", "Fail /healthz if no monitor has read a line for this long (e.g. 10m)")
+	onlyAfter      = flag.String("only-after", "", "Skip lines timestamped before this RFC3339 time")
+	onlyBefore     = flag.String("only-before", "", "Skip lines timestamped after this RFC3339 time")
+	allowMatchAll  = flag.Bool("allow-match-all", false, "Allow a --pattern that matches every line (e.g. .*)")
 )
 
+// listFlag is a flag that may be given several times, collecting each value.
+type listFlag []string
+
+func newListFlag(name, usage string) *listFlag {
+	l := &listFlag{}
+	flag.Var(l, name, usage)
+	return l
+}
+
+func (l *listFlag) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// Files returns the configuration files given with --config, in order.
+func Files() []string {
+	return *configFiles
+}
+
 // ParseFlags parses the command line flags.
 // It must be called before Load.
 func ParseFlags() {
@@ -77,6 +365,8 @@ func ParseFlags() {
 			fmt.Fprintf(out, "  sentrylogmon --dsn=... --journalctl=\"--unit=nginx -f\"\n\n")
 			fmt.Fprintf(out, "  # Monitor syslog\n")
 			fmt.Fprintf(out, "  sentrylogmon --dsn=... --syslog=:5514\n\n")
+			fmt.Fprintf(out, "  # Tail a remote file over SSH\n")
+			fmt.Fprintf(out, "  sentrylogmon --dsn=... --ssh=admin@db1:/var/log/syslog --ssh-key=/etc/sentrylogmon/id_ed25519\n\n")
 			fmt.Fprintf(out, "Flags:\n")
 			flag.PrintDefaults()
 		}
@@ -89,41 +379,38 @@ func Load() (*Config, error) {
 	ParseFlags()
 
 	cfg := &Config{
-		Verbose: *verbose,
-		OneShot: *oneshot,
+		Verbose:       *verbose,
+		OneShot:       *oneshot,
+		SampleMatches: *sampleMatches,
+		SampleOut:     *sampleOut,
+		Benchmark:     *benchmark,
 	}
 
-	if *configFile != "" {
+	if len(*configFiles) > 0 {
 		if *verbose {
-			log.Printf("Loading configuration from %s", *configFile)
-		}
-		data, err := os.ReadFile(*configFile)
-		if err != nil {
-			return nil, err
+			log.Printf("Loading configuration from %s", strings.Join(*configFiles, ", "))
 		}
-		if err := yaml.Unmarshal(data, cfg); err != nil {
+		if err := loadFiles(*configFiles, cfg); err != nil {
 			return nil, err
 		}
 
-		// Fallback to flags/env if missing in config
-		if cfg.Sentry.DSN == "" {
-			cfg.Sentry.DSN = *dsn
-		}
-		if cfg.Sentry.Environment == "" {
-			cfg.Sentry.Environment = *environment
-		}
-		if cfg.Sentry.Release == "" {
-			cfg.Sentry.Release = *release
-		}
-
 		// Flags override config file
 		if *metricsPort != 0 {
 			cfg.MetricsPort = *metricsPort
 		}
+		if *healthStale != "" {
+			cfg.HealthStaleness = *healthStale
+		}
 
 		// Verbose flag always overrides
 		cfg.Verbose = *verbose
 		cfg.OneShot = *oneshot
+		cfg.SampleMatches = *sampleMatches
+		cfg.SampleOut = *sampleOut
+		cfg.Benchmark = *benchmark
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("configuration validation failed: %w", err)
+		}
 		return cfg, nil
 	}
 
@@ -132,14 +419,22 @@ func Load() (*Config, error) {
 		DSN:         *dsn,
 		Environment: *environment,
 		Release:     *release,
+		Dist:        *dist,
 	}
 
 	cfg.MetricsPort = *metricsPort
+	cfg.HealthStaleness = *healthStale
 
 	monitor := MonitorConfig{
 		Pattern:        *pattern,
 		ExcludePattern: *excludePattern,
 		Format:         *format,
+		OnlyAfter:      *onlyAfter,
+		OnlyBefore:     *onlyBefore,
+		AllowMatchAll:  *allowMatchAll,
+	}
+	if *redact != "" {
+		monitor.Redact = []string{*redact}
 	}
 
 	if *useDmesg {
@@ -149,6 +444,9 @@ func Load() (*Config, error) {
 		monitor.Name = "file"
 		monitor.Type = "file"
 		monitor.Path = *inputFile
+		monitor.Follow = *follow
+		monitor.Replay = *replay
+		monitor.ReplaySpeed = *replaySpeed
 	} else if *journalctl != "" {
 		monitor.Name = "journalctl"
 		monitor.Type = "journalctl"
@@ -161,6 +459,18 @@ func Load() (*Config, error) {
 		monitor.Name = "syslog"
 		monitor.Type = "syslog"
 		monitor.Path = *syslogAddr
+	} else if *sshTarget != "" {
+		monitor.Name = "ssh"
+		monitor.Type = "ssh"
+		monitor.Path = *sshTarget
+		monitor.SSH = SSHConfig{KeyFile: *sshKey, KnownHostsFile: *sshKnownHosts}
+	}
+
+	// --benchmark on its own measures the --pattern/--format detector against its file
+	if monitor.Type == "" && *benchmark != "" {
+		monitor.Name = "benchmark"
+		monitor.Type = "file"
+		monitor.Path = *benchmark
 	}
 
 	if monitor.Type != "" {
@@ -174,62 +484,648 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// ReadFiles reads the configuration files in order, each overlaid on the ones
+// before it: settings it sets replace earlier ones, nested ones such as
+// sentry.environment one by one, and its monitors are added after theirs.
+// Unlike Load, it does not apply flags.
+func ReadFiles(paths ...string) (*Config, error) {
+	cfg := &Config{}
+	for _, path := range paths {
+		if err := mergeFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func mergeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// Decoding into cfg keeps what this file doesn't set, except for lists,
+	// which it would replace
+	monitors := cfg.Monitors
+	cfg.Monitors = nil
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.Monitors = append(monitors, cfg.Monitors...)
+	return nil
+}
+
+// LoadFiles reads and validates the configuration files like Load does for
+// --config, including the flag and environment fallbacks for Sentry
+// settings, e.g. to compare them with a running configuration.
+func LoadFiles(paths ...string) (*Config, error) {
+	ParseFlags()
+	cfg := &Config{}
+	if err := loadFiles(paths, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadFiles reads the YAML config files into cfg (see ReadFiles) and applies
+// flag/env fallbacks for the global Sentry settings.
+func loadFiles(paths []string, cfg *Config) error {
+	for _, path := range paths {
+		if err := mergeFile(path, cfg); err != nil {
+			return err
+		}
+	}
+
+	// Fallback to flags/env if missing in config
+	if cfg.Sentry.DSN == "" {
+		cfg.Sentry.DSN = *dsn
+	}
+	if cfg.Sentry.Environment == "" && !flagSet("environment") {
+		cfg.Sentry.Environment = environmentFromHostname(cfg.EnvironmentFromHostname)
+	}
+	if cfg.Sentry.Environment == "" {
+		cfg.Sentry.Environment = *environment
+	}
+	if cfg.Sentry.Release == "" {
+		cfg.Sentry.Release = *release
+	}
+	if cfg.Sentry.Dist == "" {
+		cfg.Sentry.Dist = *dist
+	}
+
+	// Read here rather than when sending, so a reload picks up a new deploy
+	if cfg.DeployInfoFile != "" {
+		info, err := ReadDeployInfo(cfg.DeployInfoFile)
+		if err != nil {
+			return err
+		}
+		cfg.DeployInfo = info
+	}
+	return nil
+}
+
+// LoadSentry returns the global Sentry configuration without requiring any monitors.
+// It is used by modes that only talk to Sentry, such as --check-dsn.
+func LoadSentry() (SentryConfig, error) {
+	ParseFlags()
+
+	if len(*configFiles) > 0 {
+		cfg := &Config{}
+		if err := loadFiles(*configFiles, cfg); err != nil {
+			return SentryConfig{}, err
+		}
+		return cfg.Sentry, nil
+	}
+
+	return SentryConfig{
+		DSN:         *dsn,
+		Environment: *environment,
+		Release:     *release,
+		Dist:        *dist,
+	}, nil
+}
+
 // Validate checks the configuration for errors.
+// All problems are reported at once, joined with errors.Join, each prefixed
+// with the monitor index and name it belongs to.
 func (c *Config) Validate() error {
-	if c.Sentry.DSN == "" {
-		return fmt.Errorf("Sentry DSN is required")
+	var errs []error
+	if c.Sentry.DSN == "" && c.SampleMatches == 0 && c.Benchmark == "" {
+		errs = append(errs, fmt.Errorf("Sentry DSN is required (set sentry.dsn in the config file, --dsn, or SENTRY_DSN)"))
+	}
+	if err := c.Sentry.validateSampleRate(); err != nil {
+		errs = append(errs, fmt.Errorf("sentry: %w", err))
 	}
 	if len(c.Monitors) == 0 {
-		return fmt.Errorf("no monitors configured")
+		errs = append(errs, fmt.Errorf("no monitors configured (add an entry under monitors:, or use --file, --journalctl, --dmesg, --command, --syslog or --ssh)"))
+	}
+	if c.HealthStaleness != "" {
+		if _, err := time.ParseDuration(c.HealthStaleness); err != nil {
+			errs = append(errs, fmt.Errorf("invalid health_staleness: %w (use a duration such as 30s, 5m or 1h)", err))
+		}
+	}
+	if c.GlobalRateLimitBurst < 0 {
+		errs = append(errs, fmt.Errorf("global_rate_limit_burst must not be negative"))
+	}
+	if c.GlobalRateLimitWindow != "" {
+		if _, err := time.ParseDuration(c.GlobalRateLimitWindow); err != nil {
+			errs = append(errs, fmt.Errorf("invalid global_rate_limit_window: %w (use a duration such as 30s, 5m or 1h)", err))
+		}
+	}
+	for i, r := range c.EnvironmentFromHostname {
+		if r.Prefix == "" || r.Environment == "" {
+			errs = append(errs, fmt.Errorf("environment_from_hostname[%d] needs both prefix and environment", i))
+		}
 	}
 	for i, m := range c.Monitors {
-		if err := m.Validate(); err != nil {
-			return fmt.Errorf("monitor %d ('%s') invalid: %w", i, m.Name, err)
+		for _, err := range m.validate() {
+			errs = append(errs, fmt.Errorf("monitor %d ('%s'): %w", i, m.Name, err))
+		}
+		// Names label metrics and IPC status, so two monitors that can run together must not share one
+		for j, other := range c.Monitors[:i] {
+			if m.Name != "" && m.Name == other.Name && environmentsOverlap(m.Environments, other.Environments) {
+				errs = append(errs, fmt.Errorf("monitor %d ('%s'): duplicate monitor name, also used by monitor %d", i, m.Name, j))
+				break
+			}
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
+// Warnings returns problems that don't stop the configuration from being
+// used, prefixed like the errors of Validate. Call it after Validate.
+func (c *Config) Warnings() []string {
+	var warnings []string
+	for i, m := range c.Monitors {
+		if !m.AllowMatchAll {
+			continue
+		}
+		for _, p := range m.matchAllPatterns() {
+			warnings = append(warnings, fmt.Sprintf("monitor %d ('%s'): pattern '%s' matches every line, so every line will be reported", i, m.Name, p))
+		}
+	}
+	return warnings
+}
+
+// matchAllPatterns returns the patterns of pattern and pattern_file that
+// match the empty string, and so every line, such as ".*" or "error|". Such a
+// pattern is usually a mistake that floods Sentry. Invalid patterns are left
+// to validate.
+func (m MonitorConfig) matchAllPatterns() []string {
+	patterns := []string{m.Pattern}
+	if m.PatternFile != "" {
+		if fromFile, err := detectors.ReadPatternFile(m.PatternFile); err == nil {
+			patterns = append(patterns, fromFile...)
+		}
+	}
+	var matchAll []string
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		if re, err := regexp.Compile(p); err == nil && re.MatchString("") {
+			matchAll = append(matchAll, p)
+		}
+	}
+	return matchAll
+}
+
+// DetectorFormat returns the detector format the monitor runs: format if set,
+// otherwise "custom" for a pattern, "dmesg" for a dmesg monitor, or the
+// monitor's name if it is a detector's canonical name (e.g. "nginx"), and
+// "custom" for anything else.
+func (m MonitorConfig) DetectorFormat() string {
+	if m.Format != "" {
+		return m.Format
+	}
+	// If pattern is present, assume custom (GenericDetector).
+	// This allows overriding the default dmesg detector for dmesg source if a custom pattern is provided.
+	if m.Pattern != "" {
+		return "custom"
+	}
+
+	// Infer detector format from monitor type.
+	// Specifically, 'dmesg' source type defaults to 'dmesg' detector format.
+	if m.Type == "dmesg" {
+		return "dmesg"
+	}
+
+	// Infer detector format from monitor name if it matches a known detector (e.g. "nginx").
+	// Only canonical names count: aliases such as "kernel" or "apache" are
+	// common monitor names that don't say which format the lines are in.
+	if name := strings.ToLower(strings.TrimSpace(m.Name)); detectors.NormalizeFormat(name) == name && detectors.IsKnownDetector(name) {
+		return name
+	}
+	return "custom"
+}
+
+// environmentsOverlap reports whether monitors with these environments lists
+// can run at the same time. An empty list means every environment.
+func environmentsOverlap(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var monitorTypes = []string{"file", "journalctl", "dmesg", "command", "syslog", "ssh", "k8s", "http"}
+
+// Valid values of the monitor settings that take one of a fixed set, checked
+// by validate and listed in the Schema. An empty value means the default.
+var (
+	followModes      = []string{"name", "descriptor"}
+	untimedLineModes = []string{"include", "exclude"}
+	batchOrders      = []string{"asc", "desc"}
+	titleFromValues  = []string{"first_line", "fingerprint", "pattern"}
+	decodeEncodings  = []string{"base64", "hex"}
+)
+
 // Validate checks the monitor configuration for errors.
 func (m MonitorConfig) Validate() error {
+	return errors.Join(m.validate()...)
+}
+
+func (m MonitorConfig) validate() []error {
+	var errs []error
 	if m.Name == "" {
-		return fmt.Errorf("monitor name is required")
+		errs = append(errs, fmt.Errorf("monitor name is required"))
 	}
-	switch m.Type {
-	case "file", "journalctl", "dmesg", "command", "syslog":
-		// ok
-	default:
-		return fmt.Errorf("unknown monitor type: %s", m.Type)
+	if !slices.Contains(monitorTypes, m.Type) {
+		errs = append(errs, fmt.Errorf("unknown monitor type '%s' (valid types: %s)", m.Type, strings.Join(monitorTypes, ", ")))
 	}
 
 	if m.Type == "file" && m.Path == "" {
-		return fmt.Errorf("path is required for file monitor")
+		errs = append(errs, fmt.Errorf("path is required for file monitor (e.g. path: /var/log/app.log)"))
+	}
+	if m.Type == "ssh" {
+		if host, path, ok := strings.Cut(m.Path, ":"); !ok || host == "" || path == "" {
+			errs = append(errs, fmt.Errorf("path is required for ssh monitor as [user@]host:/path (e.g. path: admin@db1:/var/log/syslog)"))
+		}
+	}
+	if m.Type == "http" && !strings.HasPrefix(m.Path, "http://") && !strings.HasPrefix(m.Path, "https://") {
+		errs = append(errs, fmt.Errorf("path is required for http monitor as an http:// or https:// URL (e.g. path: https://logs.example.com/stream)"))
+	}
+	if m.Type == "k8s" && m.K8s.Resync != "" {
+		if _, err := time.ParseDuration(m.K8s.Resync); err != nil {
+			errs = append(errs, fmt.Errorf("invalid k8s.resync: %w (use a duration such as 10s or 1m)", err))
+		}
 	}
 	if m.Type == "command" && m.Args == "" {
-		return fmt.Errorf("command args are required")
+		errs = append(errs, fmt.Errorf("command args are required (e.g. args: \"tail -f /var/log/app.log\")"))
+	}
+	if m.PollInterval != "" {
+		if m.Type != "command" {
+			errs = append(errs, fmt.Errorf("poll_interval only applies to command monitors"))
+		} else if d, err := time.ParseDuration(m.PollInterval); err != nil {
+			errs = append(errs, fmt.Errorf("invalid poll_interval: %w (use a duration such as 30s or 5m)", err))
+		} else if d <= 0 {
+			errs = append(errs, fmt.Errorf("poll_interval must be positive"))
+		}
+	}
+	if m.PollNewLines && m.PollInterval == "" {
+		errs = append(errs, fmt.Errorf("poll_new_lines requires poll_interval"))
+	}
+
+	for i, p := range m.IgnoreGlobs {
+		if _, err := filepath.Match(p, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid ignore_globs[%d] pattern '%s': %w", i, p, err))
+		}
+	}
+	if len(m.Addresses) > 0 && m.Type != "syslog" {
+		errs = append(errs, fmt.Errorf("addresses only applies to syslog monitors"))
+	}
+	if m.Type == "syslog" {
+		addresses := m.Addresses
+		if m.Path != "" || len(addresses) == 0 {
+			addresses = append([]string{m.Path}, addresses...)
+		}
+		for _, address := range addresses {
+			if _, _, err := sources.ParseSyslogAddress(address); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if m.Replay && m.Type != "file" {
+		errs = append(errs, fmt.Errorf("replay only applies to file monitors"))
+	}
+	if m.ReplaySpeed < 0 {
+		errs = append(errs, fmt.Errorf("replay_speed must not be negative"))
+	}
+	if m.Follow != "" && !slices.Contains(followModes, m.Follow) {
+		errs = append(errs, fmt.Errorf("unknown follow mode '%s' (valid modes: %s)", m.Follow, strings.Join(followModes, ", ")))
 	}
 
 	if m.Pattern != "" {
 		if _, err := regexp.Compile(m.Pattern); err != nil {
-			return fmt.Errorf("invalid pattern regex: %w", err)
+			errs = append(errs, fmt.Errorf("invalid pattern regex: %w (RE2 syntax; lookarounds and backreferences are not supported)", err))
+		}
+	}
+	if !m.AllowMatchAll {
+		for _, p := range m.matchAllPatterns() {
+			errs = append(errs, fmt.Errorf("pattern '%s' matches the empty string, so every line would be reported (set allow_match_all: true if that is intended)", p))
 		}
 	}
 	if m.ExcludePattern != "" {
 		if _, err := regexp.Compile(m.ExcludePattern); err != nil {
-			return fmt.Errorf("invalid exclude_pattern regex: %w", err)
+			errs = append(errs, fmt.Errorf("invalid exclude_pattern regex: %w (RE2 syntax; lookarounds and backreferences are not supported)", err))
+		}
+	}
+	if m.ExtraPattern != "" {
+		if _, err := regexp.Compile(m.ExtraPattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid extra_pattern regex: %w (RE2 syntax; lookarounds and backreferences are not supported)", err))
+		} else if m.PatternFile != "" || (m.Pattern != "" && m.Format == "") {
+			errs = append(errs, fmt.Errorf("extra_pattern extends a built-in format's pattern (e.g. format: dmesg); add it to pattern instead"))
+		}
+	}
+	for i, sub := range m.IgnoreSubstrings {
+		if sub == "" {
+			errs = append(errs, fmt.Errorf("ignore_substrings[%d] is empty, which would ignore every line", i))
+		}
+	}
+	for i, p := range m.Redact {
+		if _, err := regexp.Compile(p); err != nil {
+			errs = append(errs, fmt.Errorf("invalid redact[%d] regex: %w (RE2 syntax; lookarounds and backreferences are not supported)", i, err))
 		}
 	}
 	if m.MaxInactivity != "" {
 		if _, err := time.ParseDuration(m.MaxInactivity); err != nil {
-			return fmt.Errorf("invalid max_inactivity: %w", err)
+			errs = append(errs, fmt.Errorf("invalid max_inactivity: %w (use a duration such as 30s, 5m or 1h)", err))
+		}
+	}
+	if m.RealertInterval != "" {
+		if d, err := time.ParseDuration(m.RealertInterval); err != nil {
+			errs = append(errs, fmt.Errorf("invalid realert_interval: %w (use a duration such as 30s, 5m or 1h)", err))
+		} else if d < 0 {
+			errs = append(errs, fmt.Errorf("realert_interval must not be negative"))
+		} else if m.MaxInactivity == "" && m.ExpectWithin == "" {
+			errs = append(errs, fmt.Errorf("realert_interval requires max_inactivity or expect_within"))
+		}
+	}
+	if m.ReadTimeout != "" {
+		switch m.Type {
+		case "command", "journalctl", "dmesg", "ssh", "k8s":
+			if d, err := time.ParseDuration(m.ReadTimeout); err != nil {
+				errs = append(errs, fmt.Errorf("invalid read_timeout: %w (use a duration such as 30s, 5m or 1h)", err))
+			} else if d <= 0 {
+				errs = append(errs, fmt.Errorf("read_timeout must be positive, got %s", m.ReadTimeout))
+			}
+		default:
+			// Files and listeners are legitimately quiet, and are not restarted by closing them
+			errs = append(errs, fmt.Errorf("read_timeout only applies to command, journalctl, dmesg, ssh and k8s monitors (use max_inactivity to alert on a quiet source)"))
+		}
+	}
+	if m.StartupDeadline != "" {
+		if d, err := time.ParseDuration(m.StartupDeadline); err != nil {
+			errs = append(errs, fmt.Errorf("invalid startup_deadline: %w (use a duration such as 30s, 5m or 1h)", err))
+		} else if d <= 0 {
+			errs = append(errs, fmt.Errorf("startup_deadline must be positive, got %s", m.StartupDeadline))
+		}
+	}
+	if m.StartupUnhealthy && m.StartupDeadline == "" {
+		errs = append(errs, fmt.Errorf("startup_unhealthy requires startup_deadline"))
+	}
+	if (m.ExpectPattern == "") != (m.ExpectWithin == "") {
+		errs = append(errs, fmt.Errorf("expect_pattern and expect_within must be set together (e.g. expect_pattern: \"backup completed\", expect_within: 25h)"))
+	}
+	if m.ExpectPattern != "" {
+		if _, err := regexp.Compile(m.ExpectPattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid expect_pattern regex: %w (RE2 syntax; lookarounds and backreferences are not supported)", err))
+		}
+	}
+	if m.ExpectWithin != "" {
+		if _, err := time.ParseDuration(m.ExpectWithin); err != nil {
+			errs = append(errs, fmt.Errorf("invalid expect_within: %w (use a duration such as 30s, 5m or 1h)", err))
 		}
 	}
 	if m.RateLimitWindow != "" {
 		if _, err := time.ParseDuration(m.RateLimitWindow); err != nil {
-			return fmt.Errorf("invalid rate_limit_window: %w", err)
+			errs = append(errs, fmt.Errorf("invalid rate_limit_window: %w (use a duration such as 30s, 5m or 1h)", err))
 		}
 	}
-	return nil
+	if m.RateLimitAlerts && m.RateLimitBurst <= 0 {
+		errs = append(errs, fmt.Errorf("rate_limit_alerts requires rate_limit_burst"))
+	}
+	if err := m.Sentry.validateSampleRate(); err != nil {
+		errs = append(errs, fmt.Errorf("sentry: %w", err))
+	}
+	for i, t := range m.SentryTargets {
+		if t.DSN == "" {
+			errs = append(errs, fmt.Errorf("sentry_targets[%d]: dsn is required", i))
+		}
+		if err := t.validateSampleRate(); err != nil {
+			errs = append(errs, fmt.Errorf("sentry_targets[%d]: %w", i, err))
+		}
+	}
+	for i, r := range m.Routes {
+		if r.DSN == "" {
+			errs = append(errs, fmt.Errorf("routes[%d]: dsn is required", i))
+		}
+		if err := r.validateSampleRate(); err != nil {
+			errs = append(errs, fmt.Errorf("routes[%d]: %w", i, err))
+		}
+		minRank, minOK := routeLevels[strings.ToLower(r.MinLevel)]
+		maxRank, maxOK := routeLevels[strings.ToLower(r.MaxLevel)]
+		if r.MinLevel != "" && !minOK {
+			errs = append(errs, fmt.Errorf("routes[%d]: unknown min_level '%s' (valid levels: debug, info, warning, error, fatal)", i, r.MinLevel))
+		}
+		if r.MaxLevel != "" && !maxOK {
+			errs = append(errs, fmt.Errorf("routes[%d]: unknown max_level '%s' (valid levels: debug, info, warning, error, fatal)", i, r.MaxLevel))
+		}
+		if minOK && maxOK && minRank > maxRank {
+			errs = append(errs, fmt.Errorf("routes[%d]: min_level '%s' is above max_level '%s'", i, r.MinLevel, r.MaxLevel))
+		}
+	}
+	if m.DecodeField != nil {
+		if detectors.NormalizeFormat(m.Format) != "json" {
+			errs = append(errs, fmt.Errorf("decode_field requires format: json"))
+		}
+		if m.DecodeField.Name == "" {
+			errs = append(errs, fmt.Errorf("decode_field.name is required (e.g. name: stack)"))
+		}
+		if !slices.Contains(decodeEncodings, m.DecodeField.Encoding) {
+			errs = append(errs, fmt.Errorf("unknown decode_field.encoding '%s' (valid encodings: %s)", m.DecodeField.Encoding, strings.Join(decodeEncodings, ", ")))
+		}
+	}
+	if len(m.StatusClasses) > 0 || len(m.IgnoreStatus) > 0 {
+		if detectors.NormalizeFormat(m.Format) != "nginx" {
+			errs = append(errs, fmt.Errorf("status_classes and ignore_status require format: nginx"))
+		}
+		if _, err := detectors.NewStatusFilter(m.StatusClasses, m.IgnoreStatus); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var after, before time.Time
+	if m.OnlyAfter != "" {
+		t, err := time.Parse(time.RFC3339, m.OnlyAfter)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid only_after: %w (use RFC3339, e.g. 2026-01-02T15:04:05Z)", err))
+		}
+		after = t
+	}
+	if m.OnlyBefore != "" {
+		t, err := time.Parse(time.RFC3339, m.OnlyBefore)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid only_before: %w (use RFC3339, e.g. 2026-01-02T15:04:05Z)", err))
+		}
+		before = t
+	}
+	if !after.IsZero() && !before.IsZero() && before.Before(after) {
+		errs = append(errs, fmt.Errorf("only_before must not be earlier than only_after"))
+	}
+	if m.UntimedLines != "" && !slices.Contains(untimedLineModes, m.UntimedLines) {
+		errs = append(errs, fmt.Errorf("unknown untimed_lines '%s' (valid values: %s)", m.UntimedLines, strings.Join(untimedLineModes, ", ")))
+	}
+	if m.BatchOrder != "" && !slices.Contains(batchOrders, m.BatchOrder) {
+		errs = append(errs, fmt.Errorf("unknown batch_order '%s' (valid values: %s)", m.BatchOrder, strings.Join(batchOrders, ", ")))
+	}
+	if _, ok := routeLevels[strings.ToLower(m.MinSendLevel)]; m.MinSendLevel != "" && !ok {
+		errs = append(errs, fmt.Errorf("unknown min_send_level '%s' (valid levels: debug, info, warning, error, fatal)", m.MinSendLevel))
+	}
+	if m.TitleFrom != "" && !slices.Contains(titleFromValues, m.TitleFrom) {
+		errs = append(errs, fmt.Errorf("unknown title_from '%s' (valid values: %s)", m.TitleFrom, strings.Join(titleFromValues, ", ")))
+	}
+	if _, err := fingerprint.New(m.FingerprintNormalizers); err != nil {
+		errs = append(errs, err)
+	}
+	if m.GroupBy != "" {
+		// Lines are grouped by a context key, which only context extractors provide
+		if info, ok := detectors.Describe(m.DetectorFormat()); ok && !info.Context {
+			errs = append(errs, fmt.Errorf("group_by cannot be used with format: %s, which extracts no context", info.Name))
+		}
+	}
+	if m.PatternFile != "" {
+		if f := detectors.NormalizeFormat(m.Format); f != "" && f != "custom" {
+			errs = append(errs, fmt.Errorf("pattern_file cannot be used with format: %s", m.Format))
+		}
+		if _, err := detectors.ReadPatternFile(m.PatternFile); err != nil {
+			errs = append(errs, fmt.Errorf("invalid pattern_file: %w", err))
+		}
+	}
+	if m.ExcludeFile != "" {
+		if _, err := detectors.ReadPatternFile(m.ExcludeFile); err != nil {
+			errs = append(errs, fmt.Errorf("invalid exclude_file: %w", err))
+		}
+	}
+	if len(m.Sequence) > 0 {
+		if len(m.Sequence) < 2 {
+			errs = append(errs, fmt.Errorf("sequence needs at least 2 patterns (use pattern for a single one)"))
+		}
+		for i, p := range m.Sequence {
+			if _, err := regexp.Compile(p); err != nil {
+				errs = append(errs, fmt.Errorf("invalid sequence[%d] regex: %w (RE2 syntax; lookarounds and backreferences are not supported)", i, err))
+			}
+		}
+		if m.SequenceWithin == "" {
+			errs = append(errs, fmt.Errorf("sequence_within is required with sequence (e.g. sequence_within: 30s)"))
+		} else if _, err := time.ParseDuration(m.SequenceWithin); err != nil {
+			errs = append(errs, fmt.Errorf("invalid sequence_within: %w (use a duration such as 30s, 5m or 1h)", err))
+		}
+	}
+	if m.Cooldown != "" {
+		if d, err := time.ParseDuration(m.Cooldown); err != nil {
+			errs = append(errs, fmt.Errorf("invalid cooldown: %w (use a duration such as 30s, 5m or 1h)", err))
+		} else if d <= 0 {
+			errs = append(errs, fmt.Errorf("cooldown must be positive"))
+		}
+	}
+	if m.Warmup != "" {
+		if d, err := time.ParseDuration(m.Warmup); err != nil {
+			errs = append(errs, fmt.Errorf("invalid warmup: %w (use a duration such as 30s, 5m or 1h)", err))
+		} else if d <= 0 {
+			errs = append(errs, fmt.Errorf("warmup must be positive"))
+		}
+	}
+	if m.ContextPattern != "" {
+		if re, err := regexp.Compile(m.ContextPattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid context_pattern regex: %w (RE2 syntax; lookarounds and backreferences are not supported)", err))
+		} else if !slices.ContainsFunc(re.SubexpNames(), func(name string) bool { return name != "" }) {
+			errs = append(errs, fmt.Errorf("context_pattern needs a named group to capture, e.g. request_id=(?P<request_id>\\w+)"))
+		}
+	}
+	if m.ContextWithin != "" {
+		if _, err := time.ParseDuration(m.ContextWithin); err != nil {
+			errs = append(errs, fmt.Errorf("invalid context_within: %w (use a duration such as 30s, 5m or 1h)", err))
+		}
+	}
+	if u := m.UserFrom; u != nil && u.ID == "" && u.Email == "" && u.Username == "" {
+		errs = append(errs, fmt.Errorf("user_from needs at least one of id, email or username"))
+	}
+	if f := m.ForwardSyslog; f != nil {
+		if f.Address == "" {
+			errs = append(errs, fmt.Errorf("forward_syslog.address is required (e.g. address: udp:127.0.0.1:514)"))
+		} else if _, err := exporters.NewSyslogExporter(f.Address, exporters.SyslogOptions{Facility: f.Facility, Format: f.Format, Tag: f.Tag}); err != nil {
+			errs = append(errs, fmt.Errorf("invalid forward_syslog: %w", err))
+		}
+	}
+	if f := m.ForwardWebhook; f != nil {
+		if f.URL == "" {
+			errs = append(errs, fmt.Errorf("forward_webhook.url is required"))
+		} else if _, err := exporters.NewWebhookExporter(f.URL, exporters.WebhookOptions{Compress: f.Compress}); err != nil {
+			errs = append(errs, fmt.Errorf("invalid forward_webhook: %w", err))
+		}
+	}
+	if m.OutputMaxSize < 0 {
+		errs = append(errs, fmt.Errorf("output_max_size must not be negative"))
+	}
+	if m.OutputMaxFiles < 0 {
+		errs = append(errs, fmt.Errorf("output_max_files must not be negative"))
+	}
+	if m.OutputFile == "" && (m.OutputMaxSize != 0 || m.OutputMaxFiles != 0 || m.OutputRotateDaily) {
+		errs = append(errs, fmt.Errorf("output_max_size, output_max_files and output_rotate_daily require output_file"))
+	}
+	if m.SpanIDFrom != "" && m.TraceIDFrom == "" {
+		errs = append(errs, fmt.Errorf("span_id_from requires trace_id_from"))
+	}
+	if m.MinLineLength < 0 {
+		errs = append(errs, fmt.Errorf("min_line_length must not be negative"))
+	}
+	if m.NoMatchLines < 0 {
+		errs = append(errs, fmt.Errorf("no_match_lines must not be negative"))
+	}
+	if m.RawLineMaxBytes < 0 {
+		errs = append(errs, fmt.Errorf("raw_line_max_bytes must not be negative"))
+	}
+	if m.ReadBufferSize != 0 {
+		if m.Type != "file" && m.Type != "syslog" {
+			errs = append(errs, fmt.Errorf("read_buffer_size only applies to file and syslog monitors"))
+		} else if m.ReadBufferSize < sources.MinReadBufferSize || m.ReadBufferSize > sources.MaxReadBufferSize {
+			errs = append(errs, fmt.Errorf("read_buffer_size must be between %d and %d bytes", sources.MinReadBufferSize, sources.MaxReadBufferSize))
+		}
+	}
+	if m.MaxEventBytes < 0 {
+		errs = append(errs, fmt.Errorf("max_event_bytes must not be negative (omit it to use the 1MB default)"))
+	}
+	if m.MaxFingerprints < 0 {
+		errs = append(errs, fmt.Errorf("max_fingerprints must not be negative (omit it to use the default of 100)"))
+	}
+	if m.ParallelDetect < 0 {
+		errs = append(errs, fmt.Errorf("parallel_detect must not be negative"))
+	} else if m.ParallelDetect > 1 {
+		switch f := detectors.NormalizeFormat(m.Format); {
+		case f == "dmesg" || f == "iis":
+			errs = append(errs, fmt.Errorf("parallel_detect can't be used with format %s, whose detection depends on line order", f))
+		case f == "" && m.Pattern == "" && m.PatternFile == "" && m.Type == "dmesg":
+			errs = append(errs, fmt.Errorf("parallel_detect can't be used with the default dmesg format, whose detection depends on line order (set a pattern instead)"))
+		}
+	}
+	if m.MaxTagValueLength < 0 {
+		errs = append(errs, fmt.Errorf("max_tag_value_length must not be negative (omit it to use the default of 200)"))
+	}
+	if m.ContextAttachLines < 0 {
+		errs = append(errs, fmt.Errorf("context_attach_lines must not be negative"))
+	} else if m.ContextAttachLines > 0 && (m.Type != "file" || m.Replay) {
+		errs = append(errs, fmt.Errorf("context_attach_lines only applies to file monitors without replay"))
+	}
+	if m.MaxBatchLines < 0 {
+		errs = append(errs, fmt.Errorf("max_batch_lines must not be negative (omit it to use the default of 1000)"))
+	}
+	if m.MaxBatchBytes < 0 {
+		errs = append(errs, fmt.Errorf("max_batch_bytes must not be negative (omit it to use the 256KB default)"))
+	}
+	if m.MaxBatchAge != "" {
+		if d, err := time.ParseDuration(m.MaxBatchAge); err != nil {
+			errs = append(errs, fmt.Errorf("invalid max_batch_age: %w (use a duration such as 30s or 1m)", err))
+		} else if d <= 0 {
+			errs = append(errs, fmt.Errorf("max_batch_age must be positive"))
+		}
+	}
+	if m.GroupWindow != "" {
+		if d, err := time.ParseDuration(m.GroupWindow); err != nil {
+			errs = append(errs, fmt.Errorf("invalid group_window: %w (use a duration such as 1s or 30s, or 0 to only batch lines with the same timestamp)", err))
+		} else if d < 0 {
+			errs = append(errs, fmt.Errorf("group_window must not be negative"))
+		}
+	}
+	return errs
 }
 
 // Redacted returns a deep copy of the configuration with sensitive fields redacted.
@@ -252,6 +1148,24 @@ func (c *Config) Redacted() *Config {
 		if newC.Monitors[i].Sentry.DSN != "" {
 			newC.Monitors[i].Sentry.DSN = "***"
 		}
+		if targets := newC.Monitors[i].SentryTargets; targets != nil {
+			newC.Monitors[i].SentryTargets = make([]SentryConfig, len(targets))
+			for j, t := range targets {
+				if t.DSN != "" {
+					t.DSN = "***"
+				}
+				newC.Monitors[i].SentryTargets[j] = t
+			}
+		}
+		if routes := newC.Monitors[i].Routes; routes != nil {
+			newC.Monitors[i].Routes = make([]RouteConfig, len(routes))
+			for j, r := range routes {
+				if r.DSN != "" {
+					r.DSN = "***"
+				}
+				newC.Monitors[i].Routes[j] = r
+			}
+		}
 		if newC.Monitors[i].Args != "" {
 			parts := strings.Fields(newC.Monitors[i].Args)
 			newC.Monitors[i].Args = sysstat.SanitizeCommand(parts)
@@ -260,3 +1174,30 @@ func (c *Config) Redacted() *Config {
 
 	return &newC
 }
+
+// Equal reports whether two monitor configs would run the same monitor, so a
+// config reload can keep it running (and keep its file position). An empty
+// list or map is the same as an unset one.
+func (m MonitorConfig) Equal(other MonitorConfig) bool {
+	return reflect.DeepEqual(normalizeEmpty(m), normalizeEmpty(other))
+}
+
+// normalizeEmpty returns a copy of m with empty slices and maps set to nil.
+func normalizeEmpty(m MonitorConfig) MonitorConfig {
+	v := reflect.ValueOf(&m).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if (f.Kind() == reflect.Slice || f.Kind() == reflect.Map) && f.Len() == 0 {
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+	return m
+}
+
+// SameGlobals reports whether c and other differ only in their monitors, in
+// which case a reload can be applied in place.
+func (c *Config) SameGlobals(other *Config) bool {
+	a, b := *c, *other
+	a.Monitors, b.Monitors = nil, nil
+	return reflect.DeepEqual(a, b)
+}
@@ -2,14 +2,16 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestLoadConfigFromFile(t *testing.T) {
 	// Set the flag value directly since we are in the same package
 	configPath := "../testdata/config_test.yaml"
-	*configFile = configPath
-	defer func() { *configFile = "" }()
+	*configFiles = listFlag{configPath}
+	defer func() { *configFiles = nil }()
 
 	// Run Load
 	cfg, err := Load()
@@ -44,6 +46,7 @@ func TestLoadConfigFallback(t *testing.T) {
 monitors:
   - name: test
     type: file
+    path: /var/log/test.log
 `
 	tmpfile, err := os.CreateTemp("", "config_fallback_*.yaml")
 	if err != nil {
@@ -58,8 +61,8 @@ monitors:
 		t.Fatal(err)
 	}
 
-	*configFile = tmpfile.Name()
-	defer func() { *configFile = "" }()
+	*configFiles = listFlag{tmpfile.Name()}
+	defer func() { *configFiles = nil }()
 
 	// Set fallback flags
 	expectedDSN := "https://fallback@sentry.io/0"
@@ -103,8 +106,8 @@ monitors:
 	}
 	tmpfile.Close()
 
-	*configFile = tmpfile.Name()
-	defer func() { *configFile = "" }()
+	*configFiles = listFlag{tmpfile.Name()}
+	defer func() { *configFiles = nil }()
 
 	*dsn = "https://example.com"
 	defer func() { *dsn = "" }()
@@ -123,9 +126,100 @@ monitors:
 	}
 }
 
+func TestMonitorConfigContextAndTagsParsing(t *testing.T) {
+	yamlConfig := `
+monitors:
+  - name: payments
+    type: file
+    path: /tmp/test.log
+    tags:
+      team: payments
+    context:
+      runbook_url: https://example.com/runbook
+      priority: 1
+`
+	tmpfile, err := os.CreateTemp("", "config_context_*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.Write([]byte(yamlConfig)); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	*configFiles = listFlag{tmpfile.Name()}
+	defer func() { *configFiles = nil }()
+
+	*dsn = "https://example.com"
+	defer func() { *dsn = "" }()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	mon := cfg.Monitors[0]
+	if mon.Tags["team"] != "payments" {
+		t.Errorf("Expected tag team=payments, got %v", mon.Tags)
+	}
+	if mon.Context["runbook_url"] != "https://example.com/runbook" {
+		t.Errorf("Expected runbook_url in context, got %v", mon.Context)
+	}
+	if mon.Context["priority"] != 1 {
+		t.Errorf("Expected priority 1 in context, got %v", mon.Context["priority"])
+	}
+}
+
+func TestMonitorConfigRoutesParsing(t *testing.T) {
+	yamlConfig := `
+monitors:
+  - name: app
+    type: file
+    path: /tmp/test.log
+    routes:
+      - min_level: error
+        dsn: https://key@sentry.io/paging
+      - max_level: warning
+        dsn: https://key@sentry.io/dashboard
+        environment: staging
+`
+	tmpfile, err := os.CreateTemp("", "config_routes_*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.Write([]byte(yamlConfig)); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	*configFiles = listFlag{tmpfile.Name()}
+	defer func() { *configFiles = nil }()
+
+	*dsn = "https://example.com"
+	defer func() { *dsn = "" }()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	routes := cfg.Monitors[0].Routes
+	if len(routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %d", len(routes))
+	}
+	if routes[0].MinLevel != "error" || routes[0].DSN != "https://key@sentry.io/paging" {
+		t.Errorf("Unexpected first route: %+v", routes[0])
+	}
+	if routes[1].MaxLevel != "warning" || routes[1].Environment != "staging" {
+		t.Errorf("Unexpected second route: %+v", routes[1])
+	}
+}
+
 func TestLoadConfigFromFlags(t *testing.T) {
 	// Reset config file
-	*configFile = ""
+	*configFiles = nil
 
 	// Set flags
 	*inputFile = "/tmp/test.log"
@@ -156,7 +250,7 @@ func TestLoadConfigFromFlags(t *testing.T) {
 
 func TestLoadConfigFromFlags_Format(t *testing.T) {
 	// Reset config file
-	*configFile = ""
+	*configFiles = nil
 
 	// Set flags
 	*inputFile = "/tmp/test.log"
@@ -181,3 +275,327 @@ func TestLoadConfigFromFlags_Format(t *testing.T) {
 		t.Errorf("Expected Format 'nginx', got '%s'", cfg.Monitors[0].Format)
 	}
 }
+
+func TestRedactedSentryTargets(t *testing.T) {
+	cfg := &Config{
+		Monitors: []MonitorConfig{
+			{
+				Name:          "test",
+				SentryTargets: []SentryConfig{{DSN: "https://secret@sentry.io/2", Environment: "org"}},
+				Routes:        []RouteConfig{{MinLevel: "error", SentryConfig: SentryConfig{DSN: "https://secret@sentry.io/3"}}},
+			},
+		},
+	}
+
+	redacted := cfg.Redacted()
+	if got := redacted.Monitors[0].SentryTargets[0].DSN; got != "***" {
+		t.Errorf("Expected target DSN to be redacted, got %q", got)
+	}
+	if got := redacted.Monitors[0].SentryTargets[0].Environment; got != "org" {
+		t.Errorf("Expected target environment to be kept, got %q", got)
+	}
+	if got := redacted.Monitors[0].Routes[0].DSN; got != "***" {
+		t.Errorf("Expected route DSN to be redacted, got %q", got)
+	}
+	if got := cfg.Monitors[0].SentryTargets[0].DSN; got != "https://secret@sentry.io/2" {
+		t.Errorf("Original config was modified: %q", got)
+	}
+}
+
+func TestMonitorConfigEqual(t *testing.T) {
+	a := MonitorConfig{Name: "app", Type: "file", Path: "/var/log/app.log", Redact: []string{}}
+	b := MonitorConfig{Name: "app", Type: "file", Path: "/var/log/app.log"}
+	if !a.Equal(b) {
+		t.Errorf("Expected configs differing only in an empty list to be equal")
+	}
+	b.Tags = map[string]string{"team": "web"}
+	if a.Equal(b) {
+		t.Errorf("Expected configs with different tags to differ")
+	}
+
+	global := &Config{Sentry: SentryConfig{DSN: "https://example.com"}, Monitors: []MonitorConfig{a}}
+	other := &Config{Sentry: SentryConfig{DSN: "https://example.com"}}
+	if !global.SameGlobals(other) {
+		t.Errorf("Expected configs differing only in monitors to have the same globals")
+	}
+	other.MetricsPort = 9100
+	if global.SameGlobals(other) {
+		t.Errorf("Expected a changed metrics port to need a restart")
+	}
+}
+
+func TestMonitorConfigDetectorFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		monCfg   MonitorConfig
+		expected string
+	}{
+		{
+			name: "Explicit format",
+			monCfg: MonitorConfig{
+				Format: "nginx",
+			},
+			expected: "nginx",
+		},
+		{
+			name: "Dmesg type without pattern",
+			monCfg: MonitorConfig{
+				Type: "dmesg",
+			},
+			expected: "dmesg",
+		},
+		{
+			name: "Dmesg type with custom pattern",
+			monCfg: MonitorConfig{
+				Type:    "dmesg",
+				Pattern: "some-error",
+			},
+			expected: "custom",
+		},
+		{
+			name: "File type without pattern",
+			monCfg: MonitorConfig{
+				Type: "file",
+			},
+			expected: "custom",
+		},
+		{
+			name: "File type with pattern",
+			monCfg: MonitorConfig{
+				Type:    "file",
+				Pattern: "some-error",
+			},
+			expected: "custom",
+		},
+		{
+			name: "Explicit format overrides pattern and type",
+			monCfg: MonitorConfig{
+				Format:  "nginx",
+				Type:    "dmesg",
+				Pattern: "some-error",
+			},
+			expected: "nginx",
+		},
+		{
+			name: "Name matching known detector (nginx)",
+			monCfg: MonitorConfig{
+				Name: "nginx",
+				Type: "file",
+			},
+			expected: "nginx",
+		},
+		{
+			name: "Name matching known detector (nginx-error)",
+			monCfg: MonitorConfig{
+				Name: "nginx-error",
+				Type: "file",
+			},
+			expected: "nginx-error",
+		},
+		{
+			name: "Name matching known detector is case-insensitive",
+			monCfg: MonitorConfig{
+				Name: "Nginx",
+				Type: "file",
+			},
+			expected: "nginx",
+		},
+		{
+			name: "Name matching detector alias is not inferred",
+			monCfg: MonitorConfig{
+				Name: "nginx_error",
+				Type: "file",
+			},
+			expected: "custom",
+		},
+		{
+			name: "Name kernel is not inferred as dmesg",
+			monCfg: MonitorConfig{
+				Name: "kernel",
+				Type: "file",
+			},
+			expected: "custom",
+		},
+		{
+			name: "Unknown Name defaults to custom",
+			monCfg: MonitorConfig{
+				Name: "foobar",
+				Type: "file",
+			},
+			expected: "custom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.monCfg.DetectorFormat()
+			if got != tt.expected {
+				t.Errorf("DetectorFormat() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfigOverlayFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	overlay := filepath.Join(dir, "production.yaml")
+	if err := os.WriteFile(base, []byte(`
+sentry:
+  dsn: https://base@sentry.io/1
+  environment: staging
+  release: "1.0"
+metrics_port: 9100
+monitors:
+  - name: syslog
+    type: file
+    path: /var/log/syslog
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overlay, []byte(`
+sentry:
+  environment: production
+metrics_port: 9200
+monitors:
+  - name: app
+    type: file
+    path: /var/log/app.log
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	*configFiles = listFlag{base, overlay}
+	defer func() { *configFiles = nil }()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// The overlay wins where it sets something, field by field
+	if cfg.Sentry.Environment != "production" {
+		t.Errorf("Expected environment from the overlay, got %q", cfg.Sentry.Environment)
+	}
+	if cfg.Sentry.DSN != "https://base@sentry.io/1" || cfg.Sentry.Release != "1.0" {
+		t.Errorf("Expected DSN and release from the base, got %q and %q", cfg.Sentry.DSN, cfg.Sentry.Release)
+	}
+	if cfg.MetricsPort != 9200 {
+		t.Errorf("Expected metrics port from the overlay, got %d", cfg.MetricsPort)
+	}
+
+	// Monitors are added in file order
+	if len(cfg.Monitors) != 2 || cfg.Monitors[0].Name != "syslog" || cfg.Monitors[1].Name != "app" {
+		t.Errorf("Expected monitors [syslog app], got %+v", cfg.Monitors)
+	}
+}
+
+func TestLoadValidatesConfigFiles(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+sentry:
+  dsn: https://key@sentry.io/1
+monitors:
+  - name: app
+    type: file
+    path: /var/log/app.log
+    pattern: ".*"
+`
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	*configFiles = listFlag{tmp}
+	defer func() { *configFiles = nil }()
+
+	want := "pattern '.*' matches the empty string"
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Load: expected an error containing %q, got %v", want, err)
+	}
+	if _, err := LoadFiles(tmp); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("LoadFiles: expected an error containing %q, got %v", want, err)
+	}
+}
+
+func TestEnvironmentFromHostname(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+sentry:
+  dsn: https://key@sentry.io/1
+environment_from_hostname:
+  - prefix: prod-
+    environment: production
+  - prefix: STG-
+    environment: staging
+monitors:
+  - name: test
+    type: file
+    path: /var/log/test.log
+`
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	*configFiles = listFlag{tmp}
+	defer func() { *configFiles = nil }()
+	*environment = "fallback-env"
+	defer func() { *environment = "production" }()
+	defer func() { hostname = os.Hostname }()
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"prod-web-1", "production"},
+		{"stg-db", "staging"},
+		{"dev-laptop", "fallback-env"},
+	}
+	for _, tt := range tests {
+		host := tt.host
+		hostname = func() (string, error) { return host, nil }
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if cfg.Sentry.Environment != tt.want {
+			t.Errorf("host %s: expected environment '%s', got '%s'", tt.host, tt.want, cfg.Sentry.Environment)
+		}
+	}
+}
+
+func TestLoadDeployInfo(t *testing.T) {
+	dir := t.TempDir()
+	deployPath := filepath.Join(dir, "deploy.json")
+	if err := os.WriteFile(deployPath, []byte(`{"commit": "4673c66", "built_at": "2026-10-16T08:00:00Z", "deployer": "ci"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	content := `
+sentry:
+  dsn: https://example@sentry.io/0
+deploy_info_file: ` + deployPath + `
+monitors:
+  - name: test
+    type: file
+    path: /var/log/test.log
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	*configFiles = listFlag{configPath}
+	defer func() { *configFiles = nil }()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.DeployInfo["commit"] != "4673c66" || cfg.DeployInfo["deployer"] != "ci" {
+		t.Errorf("Expected the deploy info file's fields, got %v", cfg.DeployInfo)
+	}
+
+	// A file that isn't a JSON object fails the load
+	if err := os.WriteFile(deployPath, []byte("commit=4673c66"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil {
+		t.Error("Expected an error for an invalid deploy info file")
+	}
+}
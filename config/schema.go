@@ -0,0 +1,137 @@
+package config
+
+import (
+	"cmp"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/exporters"
+	"github.com/angch/sentrylogmon/fingerprint"
+)
+
+// Schema returns a JSON Schema of the configuration file, for editors to
+// complete and check configs with (e.g. through yaml-language-server). It is
+// generated from the yaml tags of Config, with the valid values of the
+// settings that take one of a fixed set and the monitor fields Validate
+// requires. Settings Validate checks in other ways, such as regexes and
+// durations, are plain strings.
+func Schema() map[string]any {
+	s := schemaOf(reflect.TypeOf(Config{}), "")
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = "sentrylogmon configuration"
+	return s
+}
+
+// schemaRequired lists the fields the objects at a path must have. Paths are
+// the yaml keys joined with dots, with list items at their list's path.
+var schemaRequired = map[string][]string{
+	"monitors": {"name", "type"},
+}
+
+// schemaDescriptions describes settings whose schema alone would mislead.
+var schemaDescriptions = map[string]string{
+	"sentry.dsn": "Sentry DSN. Required, unless given with --dsn or SENTRY_DSN.",
+}
+
+// schemaEnums returns the valid values of the settings that take one of a
+// fixed set, by path (see schemaRequired).
+func schemaEnums() map[string][]string {
+	var formats []string
+	for _, d := range detectors.List() {
+		formats = append(formats, d.Name)
+		formats = append(formats, d.Aliases...)
+	}
+	levels := slices.SortedFunc(maps.Keys(routeLevels), func(a, b string) int {
+		return cmp.Or(cmp.Compare(routeLevels[a], routeLevels[b]), strings.Compare(a, b))
+	})
+	facilities := slices.Sorted(maps.Keys(exporters.SyslogFacilities))
+
+	return map[string][]string{
+		"monitors.type":                     monitorTypes,
+		"monitors.format":                   formats,
+		"monitors.follow":                   followModes,
+		"monitors.untimed_lines":            untimedLineModes,
+		"monitors.batch_order":              batchOrders,
+		"monitors.title_from":               titleFromValues,
+		"monitors.min_send_level":           levels,
+		"monitors.routes.min_level":         levels,
+		"monitors.routes.max_level":         levels,
+		"monitors.decode_field.encoding":    decodeEncodings,
+		"monitors.forward_syslog.facility":  facilities,
+		"monitors.forward_syslog.format":    exporters.SyslogFormats,
+		"monitors.forward_webhook.compress": exporters.WebhookCompressions,
+		"monitors.fingerprint_normalizers":  fingerprint.Names,
+	}
+}
+
+// schemaOf returns the schema of a setting of type t at path.
+func schemaOf(t reflect.Type, path string) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem(), path)
+	case reflect.Struct:
+		props := make(map[string]any)
+		addSchemaFields(props, t, path)
+		s := map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+		if required := schemaRequired[path]; required != nil {
+			s["required"] = required
+		}
+		return s
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), path)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), path+".*")}
+	}
+
+	s := make(map[string]any)
+	switch t.Kind() {
+	case reflect.String:
+		s["type"] = "string"
+		if enum := schemaEnums()[path]; enum != nil {
+			s["enum"] = enum
+		}
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		s["type"] = "number"
+	}
+	// Anything else, e.g. the values of context, is left open
+	if d, ok := schemaDescriptions[path]; ok {
+		s["description"] = d
+	}
+	return s
+}
+
+// addSchemaFields adds the schemas of the fields of struct t, at path, to
+// props by their yaml keys, including those of inlined structs.
+func addSchemaFields(props map[string]any, t reflect.Type, path string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if opts == "inline" {
+			addSchemaFields(props, f.Type, path)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		props[name] = schemaOf(f.Type, fieldPath)
+	}
+}
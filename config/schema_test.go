@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	// Round-trip through JSON to check the schema as editors see it
+	data, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatalf("Failed to marshal schema: %v", err)
+	}
+	type node struct {
+		Type                 string           `json:"type"`
+		Enum                 []string         `json:"enum"`
+		Required             []string         `json:"required"`
+		Description          string           `json:"description"`
+		Properties           map[string]*node `json:"properties"`
+		Items                *node            `json:"items"`
+		AdditionalProperties any              `json:"additionalProperties"`
+	}
+	var schema node
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Failed to unmarshal schema: %v", err)
+	}
+
+	monitor := schema.Properties["monitors"].Items
+	if monitor == nil || monitor.Type != "object" {
+		t.Fatalf("Expected monitors to be a list of objects, got %s", data)
+	}
+	if !slices.Equal(monitor.Required, []string{"name", "type"}) {
+		t.Errorf("Expected monitors to require name and type, got %v", monitor.Required)
+	}
+	if monitor.AdditionalProperties != false {
+		t.Errorf("Expected unknown monitor settings to be rejected, got %v", monitor.AdditionalProperties)
+	}
+	typ := monitor.Properties["type"]
+	for _, want := range []string{"file", "journalctl", "syslog", "k8s"} {
+		if !slices.Contains(typ.Enum, want) {
+			t.Errorf("Expected type enum to contain %q, got %v", want, typ.Enum)
+		}
+	}
+	if format := monitor.Properties["format"]; !slices.Contains(format.Enum, "nginx-error") {
+		t.Errorf("Expected format enum to contain the detectors, got %v", format.Enum)
+	}
+	// Inlined Sentry settings of a route
+	if route := monitor.Properties["routes"].Items; route.Properties["dsn"] == nil || route.Properties["min_level"].Enum == nil {
+		t.Errorf("Expected routes to have dsn and an enumerated min_level, got %+v", route.Properties)
+	}
+
+	// The DSN can come from flags, so it isn't required by the schema
+	sentry := schema.Properties["sentry"]
+	dsn := sentry.Properties["dsn"]
+	if dsn == nil || dsn.Type != "string" || dsn.Description == "" {
+		t.Fatalf("Expected sentry.dsn to be a described string, got %+v", dsn)
+	}
+	if slices.Contains(sentry.Required, "dsn") {
+		t.Error("Expected sentry.dsn not to be required, since --dsn or SENTRY_DSN can set it")
+	}
+}
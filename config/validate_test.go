@@ -194,6 +194,936 @@ func TestConfigValidate(t *testing.T) {
 			expectErr: true,
 			errContains: "invalid rate_limit_window",
 		},
+		{
+			name: "Sentry Target Missing DSN",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:          "test",
+						Type:          "dmesg",
+						SentryTargets: []SentryConfig{{Environment: "org"}},
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "sentry_targets[0]: dsn is required",
+		},
+		{
+			name: "Expect Pattern Without Window",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:          "test",
+						Type:          "dmesg",
+						ExpectPattern: "backup completed",
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "expect_pattern and expect_within must be set together",
+		},
+		{
+			name: "SSH Monitor Missing Remote Path",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name: "remote",
+						Type: "ssh",
+						Path: "admin@db1",
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "path is required for ssh monitor",
+		},
+		{
+			name: "HTTP Monitor Without URL",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name: "stream",
+						Type: "http",
+						Path: "logs.example.com/stream",
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "path is required for http monitor",
+		},
+		{
+			name: "Invalid Global Rate Limit Window",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name: "test",
+						Type: "file",
+						Path: "/tmp/test.log",
+					},
+				},
+				GlobalRateLimitBurst:  10,
+				GlobalRateLimitWindow: "soon",
+			},
+			expectErr: true,
+			errContains: "invalid global_rate_limit_window",
+		},
+		{
+			name: "Decode Field Requires JSON Format",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:        "test",
+						Type:        "file",
+						Path:        "/tmp/test.log",
+						Pattern:     "error",
+						DecodeField: &DecodeFieldConfig{Name: "stack", Encoding: "base64"},
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "decode_field requires format: json",
+		},
+		{
+			name: "Time Window Reversed",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:       "test",
+						Type:       "file",
+						Path:       "/tmp/test.log",
+						OnlyAfter:  "2026-03-01T11:00:00Z",
+						OnlyBefore: "2026-03-01T10:00:00Z",
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "only_before must not be earlier than only_after",
+		},
+		{
+			name: "Sequence Without Window",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:     "test",
+						Type:     "file",
+						Path:     "/tmp/test.log",
+						Sequence: []string{"connection lost", "503"},
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "sequence_within is required with sequence",
+		},
+		{
+			name: "Group By Without Context",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:    "kernel",
+						Type:    "dmesg",
+						GroupBy: "device",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "group_by cannot be used with format: dmesg, which extracts no context",
+		},
+		{
+			name: "Group By With Format Inferred From Name",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:    "nginx-error",
+						Type:    "file",
+						Path:    "/var/log/nginx/error.log",
+						GroupBy: "client",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "group_by cannot be used with format: nginx-error, which extracts no context",
+		},
+		{
+			name: "Group By With Named Groups",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:    "kernel",
+						Type:    "dmesg",
+						Pattern: `EXT4-fs error \(device (?P<device>\w+)\)`,
+						GroupBy: "device",
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Duplicate Monitor Names",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name: "app",
+						Type: "file",
+						Path: "/var/log/app.log",
+					},
+					{
+						Name: "app",
+						Type: "file",
+						Path: "/var/log/app-worker.log",
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "monitor 1 ('app'): duplicate monitor name, also used by monitor 0",
+		},
+		{
+			name: "Duplicate Monitor Names In Different Environments",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:         "app",
+						Environments: []string{"production"},
+						Type:         "file",
+						Path:         "/var/log/app.log",
+					},
+					{
+						Name:         "app",
+						Environments: []string{"staging"},
+						Type:         "file",
+						Path:         "/srv/staging/app.log",
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Invalid Redact Regex",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:   "app",
+						Type:   "file",
+						Path:   "/var/log/app.log",
+						Redact: []string{`user=\w+`, "token=(["},
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "invalid redact[1] regex",
+		},
+		{
+			name: "Read Buffer Size Out Of Bounds",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:           "app",
+						Type:           "file",
+						Path:           "/var/log/app.log",
+						ReadBufferSize: 512,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "read_buffer_size must be between 4096 and 16777216 bytes",
+		},
+		{
+			name: "Syslog IPv6 Zone Address",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:      "syslog",
+						Type:      "syslog",
+						Path:      ":5514",
+						Addresses: []string{"udp:[fe80::1%eth0]:514", "tcp://0.0.0.0:6514"},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Syslog Unbracketed IPv6 Address",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:      "syslog",
+						Type:      "syslog",
+						Addresses: []string{"udp:fe80::1%eth0:514"},
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "put IPv6 addresses in brackets",
+		},
+		{
+			name: "Unknown Fingerprint Normalizer",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:                   "app",
+						Type:                   "file",
+						Path:                   "/var/log/app.log",
+						FingerprintNormalizers: []string{"numbers", "digits"},
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "unknown fingerprint normalizer 'digits' (valid values: uuids, hex, numbers, whitespace)",
+		},
+		{
+			name: "Forward Syslog Unknown Facility",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:          "app",
+						Type:          "file",
+						Path:          "/var/log/app.log",
+						ForwardSyslog: &ForwardSyslogConfig{Address: "udp:127.0.0.1:514", Facility: "local8"},
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "invalid forward_syslog: unknown syslog facility 'local8'",
+		},
+		{
+			name: "Forward Webhook Unknown Compression",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:           "app",
+						Type:           "file",
+						Path:           "/var/log/app.log",
+						ForwardWebhook: &ForwardWebhookConfig{URL: "https://hec.example.com/services/collector", Compress: "zstd"},
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "invalid forward_webhook: unknown compression 'zstd' (valid values: gzip)",
+		},
+		{
+			name: "Output Rotation Without Output File",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:          "app",
+						Type:          "file",
+						Path:          "/var/log/app.log",
+						OutputMaxSize: 10000000,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "output_max_size, output_max_files and output_rotate_daily require output_file",
+		},
+		{
+			name: "Negative Max Batch Lines",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:          "app",
+						Type:          "file",
+						Path:          "/var/log/app.log",
+						MaxBatchLines: -1,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "max_batch_lines must not be negative",
+		},
+		{
+			name: "Poll Interval On File Monitor",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:         "app",
+						Type:         "file",
+						Path:         "/var/log/app.log",
+						PollInterval: "5m",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "poll_interval only applies to command monitors",
+		},
+		{
+			name: "Negative Max Tag Value Length",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:              "app",
+						Type:              "file",
+						Path:              "/var/log/app.log",
+						MaxTagValueLength: -1,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "max_tag_value_length must not be negative",
+		},
+		{
+			name: "Match All Pattern",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:    "app",
+						Type:    "file",
+						Path:    "/var/log/app.log",
+						Pattern: ".*",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "pattern '.*' matches the empty string",
+		},
+		{
+			name: "Match All Pattern Allowed",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:    "app",
+						Type:    "file",
+						Path:    "/var/log/app.log",
+						Pattern: ".*",
+						AllowMatchAll: true,
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Read Timeout On File Monitor",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:        "app",
+						Type:        "file",
+						Path:        "/var/log/app.log",
+						ReadTimeout: "5m",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "read_timeout only applies to command, journalctl, dmesg, ssh and k8s monitors",
+		},
+		{
+			name: "Context Attach Lines On Command Monitor",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:               "app",
+						Type:               "command",
+						Args:               "tail -f /var/log/app.log",
+						ContextAttachLines: 10,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "context_attach_lines only applies to file monitors",
+		},
+		{
+			name: "Rate Limit Alerts Without Burst",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:            "app",
+						Type:            "file",
+						Path:            "/var/log/app.log",
+						RateLimitAlerts: true,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "rate_limit_alerts requires rate_limit_burst",
+		},
+		{
+			name: "Negative Group Window",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:        "app",
+						Type:        "file",
+						Path:        "/var/log/app.log",
+						GroupWindow: "-1s",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "group_window must not be negative",
+		},
+		{
+			name: "Realert Interval Without Watchdog",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:            "app",
+						Type:            "file",
+						Path:            "/var/log/app.log",
+						RealertInterval: "30m",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "realert_interval requires max_inactivity or expect_within",
+		},
+		{
+			name: "Parallel Detect With Dmesg Format",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:           "kernel",
+						Type:           "dmesg",
+						ParallelDetect: 4,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "parallel_detect can't be used with the default dmesg format",
+		},
+		{
+			name: "Extra Pattern With Custom Pattern",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:         "app",
+						Type:         "file",
+						Path:         "/var/log/app.log",
+						Pattern:      "ERROR",
+						ExtraPattern: "timeout",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "add it to pattern instead",
+		},
+		{
+			name: "Environment From Hostname Without Environment",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				EnvironmentFromHostname: []HostnameEnvironment{
+					{Prefix: "prod-"},
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name: "app",
+						Type: "file",
+						Path: "/var/log/app.log",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "environment_from_hostname[0] needs both prefix and environment",
+		},
+		{
+			name: "Read Buffer Size On Command Monitor",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:           "cmd",
+						Type:           "command",
+						Args:           "tail -f /var/log/app.log",
+						ReadBufferSize: 65536,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "read_buffer_size only applies to file and syslog monitors",
+		},
+		{
+			name: "Negative Raw Line Max Bytes",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:            "app",
+						Type:            "file",
+						Path:            "/var/log/app.log",
+						RawLineMaxBytes: -1,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "raw_line_max_bytes must not be negative",
+		},
+		{
+			name: "Replay On Non-File Monitor",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:   "kernel",
+						Type:   "dmesg",
+						Replay: true,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "replay only applies to file monitors",
+		},
+		{
+			name: "Context Pattern Without Named Group",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:           "app",
+						Type:           "file",
+						Path:           "/var/log/app.log",
+						ContextPattern: `request_id=\w+`,
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "context_pattern needs a named group",
+		},
+		{
+			name: "Invalid Ignore Glob",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:        "logs",
+						Type:        "file",
+						Path:        "/var/log/*.log",
+						IgnoreGlobs: []string{"*.gz", "[bad"},
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "invalid ignore_globs[1] pattern '[bad'",
+		},
+		{
+			name: "Invalid Cooldown",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:     "app",
+						Type:     "file",
+						Path:     "/var/log/app.log",
+						Cooldown: "5 minutes",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "invalid cooldown",
+		},
+		{
+			name: "Invalid Warmup",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:   "app",
+						Type:   "file",
+						Path:   "/var/log/app.log",
+						Warmup: "-1m",
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "warmup must be positive",
+		},
+		{
+			name: "Valid Status Classes",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:          "access",
+						Type:          "file",
+						Path:          "/var/log/nginx/access.log",
+						Format:        "nginx",
+						StatusClasses: []string{"4xx", "5xx"},
+						IgnoreStatus:  []int{404},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Status Classes Without Nginx Format",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:          "access",
+						Type:          "file",
+						Path:          "/var/log/nginx/access.log",
+						Pattern:       "error",
+						StatusClasses: []string{"5xx"},
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "status_classes and ignore_status require format: nginx",
+		},
+		{
+			name: "Invalid Status Class",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:          "access",
+						Type:          "file",
+						Path:          "/var/log/nginx/access.log",
+						Format:        "nginx",
+						StatusClasses: []string{"50x"},
+					},
+				},
+			},
+			expectErr:   true,
+			errContains: "invalid status class '50x'",
+		},
+		{
+			name: "Benchmark Without DSN",
+			config: Config{
+				Benchmark: "/tmp/app.log",
+				Monitors: []MonitorConfig{
+					{
+						Name: "app",
+						Type: "file",
+						Path: "/var/log/app.log",
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Sample Rate Out Of Range",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN:        "https://example.com",
+					SampleRate: 1.5,
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name: "test",
+						Type: "dmesg",
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "sentry: sample_rate must be between 0 and 1",
+		},
+		{
+			name: "Empty Ignore Substring",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:             "test",
+						Type:             "dmesg",
+						IgnoreSubstrings: []string{"healthcheck", ""},
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "ignore_substrings[1] is empty",
+		},
+		{
+			name: "Unknown Min Send Level",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:         "test",
+						Type:         "dmesg",
+						MinSendLevel: "loud",
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "unknown min_send_level 'loud'",
+		},
+		{
+			name: "Route Without DSN",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:   "test",
+						Type:   "dmesg",
+						Routes: []RouteConfig{{MinLevel: "error"}},
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "routes[0]: dsn is required",
+		},
+		{
+			name: "Route Unknown Level",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:   "test",
+						Type:   "dmesg",
+						Routes: []RouteConfig{{MinLevel: "critical", SentryConfig: SentryConfig{DSN: "https://example.com/2"}}},
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "unknown min_level 'critical'",
+		},
+		{
+			name: "Route Min Level Above Max Level",
+			config: Config{
+				Sentry: SentryConfig{
+					DSN: "https://example.com",
+				},
+				Monitors: []MonitorConfig{
+					{
+						Name:   "test",
+						Type:   "dmesg",
+						Routes: []RouteConfig{{MinLevel: "error", MaxLevel: "warn", SentryConfig: SentryConfig{DSN: "https://example.com/2"}}},
+					},
+				},
+			},
+			expectErr: true,
+			errContains: "min_level 'error' is above max_level 'warn'",
+		},
+		{
+			name: "Sample Mode Without DSN",
+			config: Config{
+				SampleMatches: 20,
+				Monitors: []MonitorConfig{
+					{
+						Name: "app",
+						Type: "file",
+						Path: "/var/log/app.log",
+					},
+				},
+			},
+			expectErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -213,3 +1143,75 @@ func TestConfigValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigValidateReportsAllErrors(t *testing.T) {
+	cfg := Config{
+		Monitors: []MonitorConfig{
+			{
+				Name: "good",
+				Type: "dmesg",
+			},
+			{
+				Name:          "bad-type",
+				Type:          "tail",
+				MaxInactivity: "soon",
+			},
+			{
+				Type:    "file",
+				Pattern: "(",
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected error, got nil")
+	}
+
+	// errors.Join exposes each error via Unwrap() []error
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("Expected joined errors, got %T", err)
+	}
+	if got := len(joined.Unwrap()); got != 6 {
+		t.Errorf("Expected 6 errors, got %d:\n%v", got, err)
+	}
+
+	msg := err.Error()
+	for _, want := range []string{
+		"Sentry DSN is required",
+		"monitor 1 ('bad-type'): unknown monitor type 'tail' (valid types: file, journalctl, dmesg, command, syslog, ssh, k8s, http)",
+		"monitor 1 ('bad-type'): invalid max_inactivity",
+		"monitor 2 (''): monitor name is required",
+		"monitor 2 (''): path is required for file monitor",
+		"monitor 2 (''): invalid pattern regex",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected error to contain %q, got:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "'good'") {
+		t.Errorf("Valid monitor should not be reported, got:\n%s", msg)
+	}
+}
+
+func TestConfigWarnings(t *testing.T) {
+	cfg := Config{
+		Sentry: SentryConfig{DSN: "https://example.com"},
+		Monitors: []MonitorConfig{
+			{Name: "all", Type: "file", Path: "/var/log/all.log", Pattern: ".*", AllowMatchAll: true},
+			{Name: "errors", Type: "file", Path: "/var/log/app.log", Pattern: "error|fatal", AllowMatchAll: true},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	warnings := cfg.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
+	}
+	if want := "monitor 0 ('all'): pattern '.*' matches every line"; !strings.Contains(warnings[0], want) {
+		t.Errorf("Expected warning to contain %q, got %q", want, warnings[0])
+	}
+}
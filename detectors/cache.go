@@ -0,0 +1,25 @@
+package detectors
+
+import (
+	"regexp"
+	"sync"
+)
+
+// regexCache holds compiled patterns shared by all detectors. A compiled
+// *regexp.Regexp is safe for concurrent use, so detectors built from the same
+// pattern (e.g. one per glob-expanded file) only compile it once.
+var regexCache sync.Map // map[string]*regexp.Regexp
+
+// compileCached returns the compiled regex for pattern, compiling it on first use.
+// Patterns that fail to compile are not cached.
+func compileCached(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := regexCache.LoadOrStore(pattern, re)
+	return actual.(*regexp.Regexp), nil
+}
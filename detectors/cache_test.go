@@ -0,0 +1,76 @@
+package detectors
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGetDetectorSharesCompiledRegex(t *testing.T) {
+	d1, err := GetDetector("custom", `timeout after \d+ms`)
+	if err != nil {
+		t.Fatalf("GetDetector failed: %v", err)
+	}
+	d2, err := GetDetector("custom", `timeout after \d+ms`)
+	if err != nil {
+		t.Fatalf("GetDetector failed: %v", err)
+	}
+	g1, g2 := d1.(*GenericDetector), d2.(*GenericDetector)
+	if g1 == g2 {
+		t.Fatal("Expected distinct detector instances")
+	}
+	if g1.pattern != g2.pattern {
+		t.Error("Expected detectors with the same pattern to share the compiled regex")
+	}
+
+	j1, err := GetDetector("json", "level:^(error|fatal)$")
+	if err != nil {
+		t.Fatalf("GetDetector failed: %v", err)
+	}
+	j2, err := GetDetector("json", "level:^(error|fatal)$")
+	if err != nil {
+		t.Fatalf("GetDetector failed: %v", err)
+	}
+	if j1 == j2 {
+		t.Fatal("Expected distinct json detector instances")
+	}
+	if j1.(*JsonDetector).Pattern != j2.(*JsonDetector).Pattern {
+		t.Error("Expected json detectors with the same pattern to share the compiled regex")
+	}
+
+	// State stays per instance
+	j1.(*JsonDetector).Detect([]byte(`{"level":"error"}`))
+	if j2.(*JsonDetector).lastLine != nil {
+		t.Error("Expected json detector state not to be shared")
+	}
+}
+
+func TestGetDetectorInvalidPatternNotCached(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		if _, err := GetDetector("custom", `bad(`); err == nil {
+			t.Fatalf("attempt %d: expected error for invalid pattern", i+1)
+		}
+	}
+	if _, ok := regexCache.Load(`bad(`); ok {
+		t.Error("Expected invalid pattern not to be cached")
+	}
+}
+
+const benchmarkCachePattern = `(?i)(error|fail(ed|ure)?|panic|segfault|oom-killer)\s+\w+`
+
+func BenchmarkGetDetector_Cached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetDetector("custom", benchmarkCachePattern); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRegexpCompile_Uncached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := regexp.Compile(benchmarkCachePattern); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
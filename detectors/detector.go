@@ -23,3 +23,48 @@ type TimestampExtractor interface {
 	// ExtractTimestamp returns the timestamp (unix float), string representation, and success boolean.
 	ExtractTimestamp(line []byte) (float64, string, bool)
 }
+
+// MatchExtractor is an interface for extracting the part of a line a detector matched.
+type MatchExtractor interface {
+	// Match returns the matched part of the line, or nil if it does not match.
+	Match(line []byte) []byte
+}
+
+// Reasons a ReasonDetector gives for a match.
+const (
+	ReasonPattern      = "pattern"      // the line itself matched
+	ReasonContext      = "context"      // a related line following a match, e.g. from the same kernel subsystem
+	ReasonContinuation = "continuation" // a continuation of a matched line, e.g. a stack trace
+)
+
+// ReasonDetector is an optional interface for detectors that can tell why a line matched.
+type ReasonDetector interface {
+	// DetectReason is Detect, also returning the reason for a match (one of
+	// the Reason constants), or "" if the line did not match.
+	DetectReason(line []byte) (bool, string)
+}
+
+// FormatChecker is an optional interface for detectors of a structured format.
+type FormatChecker interface {
+	// ParsesFormat returns true if the line has the expected shape, e.g. is a JSON object.
+	ParsesFormat(line []byte) bool
+	// FormatName describes the expected shape, e.g. "JSON".
+	FormatName() string
+}
+
+// PatternExtender is an optional interface for detectors built around a
+// regex of their own, e.g. dmesg or nginx, that can match an additional
+// pattern while keeping the rest of their logic (timestamps, context lines).
+type PatternExtender interface {
+	// ExtendPattern makes the detector also match lines matching extra.
+	ExtendPattern(extra string) error
+}
+
+// OrderedDetector is an optional interface for detectors whose result for a
+// line depends on the lines before it, e.g. dmesg reporting the lines that
+// follow a match. They must see every line in order, so they can't detect
+// several lines at once (a monitor's parallel_detect).
+type OrderedDetector interface {
+	// DependsOnLineOrder returns true if Detect must see lines in order.
+	DependsOnLineOrder() bool
+}
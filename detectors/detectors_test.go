@@ -127,7 +127,15 @@ func TestIsKnownDetector(t *testing.T) {
 		{"KnownNginxError", "nginx-error", true},
 		{"UnknownFoo", "foo", false},
 		{"UnknownEmpty", "", false},
-		{"UnknownCase", "Nginx", false}, // Currently case sensitive
+		{"KnownHAProxy", "haproxy", true},
+		{"UnknownCase", "Nginx", true},
+		{"KnownUpperCase", "NGINX", true},
+		{"KnownMixedCaseWithSpaces", " Nginx-Error ", true},
+		{"AliasUnderscore", "nginx_error", true},
+		{"AliasJsonl", "jsonl", true},
+		{"AliasNdjsonUpper", "NDJSON", true},
+		{"AliasKernel", "kernel", true},
+		{"UnknownCustom", "custom", false},
 	}
 
 	for _, tt := range tests {
@@ -138,3 +146,106 @@ func TestIsKnownDetector(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"nginx", "nginx"},
+		{"Nginx", "nginx"},
+		{"NGINX_ERROR", "nginx-error"},
+		{"nginx_error", "nginx-error"},
+		{"jsonl", "json"},
+		{"ndjson", "json"},
+		{"Kernel", "dmesg"},
+		{"Custom", "custom"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NormalizeFormat(tt.input); got != tt.expected {
+				t.Errorf("NormalizeFormat(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetDetectorAliases(t *testing.T) {
+	tests := []struct {
+		format  string
+		pattern string
+	}{
+		{"NGINX", ""},
+		{"nginx_error", ""},
+		{"Dmesg", ""},
+		{"JSONL", "level:error"},
+		{"Custom", "error"},
+		{"HAProxy", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			d, err := GetDetector(tt.format, tt.pattern)
+			if err != nil {
+				t.Fatalf("GetDetector(%q) error: %v", tt.format, err)
+			}
+			if d == nil {
+				t.Fatalf("GetDetector(%q) returned nil detector", tt.format)
+			}
+		})
+	}
+}
+
+func TestGenericDetector_GetContext(t *testing.T) {
+	d, err := NewGenericDetector(`req=(?P<request_id>\w+) (?P<level>error|fatal)`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	ctx := d.GetContext([]byte("[100.0] req=abc123 error: boom"))
+	if ctx == nil {
+		t.Fatal("Expected context, got nil")
+	}
+	if ctx["request_id"] != "abc123" {
+		t.Errorf("request_id = %v, want abc123", ctx["request_id"])
+	}
+	if ctx["level"] != "error" {
+		t.Errorf("level = %v, want error", ctx["level"])
+	}
+
+	if ctx := d.GetContext([]byte("no match here")); ctx != nil {
+		t.Errorf("Expected nil context for non-matching line, got %v", ctx)
+	}
+
+	// Patterns without named groups do not produce context
+	plain, err := NewGenericDetector(`err(or)?`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	if ctx := plain.GetContext([]byte("error")); ctx != nil {
+		t.Errorf("Expected nil context for unnamed groups, got %v", ctx)
+	}
+}
+
+func TestGenericDetector_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		line    string
+		want    string
+	}{
+		{"ERROR", "12:00 ERROR disk full", "ERROR"},
+		{`disk \w+ full`, "12:00 ERROR disk sda1 full, remounting", "disk sda1 full"},
+		{`disk \w+ full`, "12:00 INFO all good", ""},
+	}
+	for _, tt := range tests {
+		d, err := NewGenericDetector(tt.pattern)
+		if err != nil {
+			t.Fatalf("NewGenericDetector(%q) failed: %v", tt.pattern, err)
+		}
+		if got := string(d.Match([]byte(tt.line))); got != tt.want {
+			t.Errorf("Match(%q) with %q = %q, want %q", tt.line, tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -21,6 +21,14 @@ func NewDmesgDetector() *DmesgDetector {
 }
 
 func (d *DmesgDetector) Detect(line []byte) bool {
+	matched, _ := d.DetectReason(line)
+	return matched
+}
+
+// DetectReason reports whether the line matched, and whether it was for the
+// pattern itself, as a related line of the same subsystem (context) or as a
+// line without a timestamp following a match (continuation).
+func (d *DmesgDetector) DetectReason(line []byte) (bool, string) {
 	// 1. Check if it matches the error pattern first
 	isError := d.GenericDetector.Detect(line)
 
@@ -65,7 +73,7 @@ func (d *DmesgDetector) Detect(line []byte) bool {
 			// String conversion here is necessary for storing the header for later comparison.
 			d.lastMatchHeader = string(headerBytes)
 		}
-		return true
+		return true, ReasonPattern
 	}
 
 	// 4. If not an explicit error, check if it's related context
@@ -76,22 +84,28 @@ func (d *DmesgDetector) Detect(line []byte) bool {
 				if (timestamp - d.lastMatchTime) <= 5.0 {
 					// Pass headerBytes directly without string conversion
 					if areHeadersRelated(d.lastMatchHeader, headerBytes) {
-						return true
+						return true, ReasonContext
 					}
 				}
 			}
 			// If it's a dmesg line but not related (or couldn't parse header/timestamp),
 			// we assume it's NOT context.
-			return false
+			return false, ""
 		} else {
 			// It does not look like a dmesg line (no timestamp).
 			// Assume it is a continuation line (stack trace, etc.)
 			// We accept it as part of the context.
-			return true
+			return true, ReasonContinuation
 		}
 	}
 
-	return false
+	return false, ""
+}
+
+// DependsOnLineOrder returns true: context and continuation lines are
+// reported by their place after a match.
+func (d *DmesgDetector) DependsOnLineOrder() bool {
+	return true
 }
 
 // TransformMessage strips the timestamp from the dmesg line.
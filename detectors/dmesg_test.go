@@ -36,3 +36,59 @@ func TestDmesgTransformMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestDmesgDetectReason(t *testing.T) {
+	d := NewDmesgDetector()
+
+	lines := []struct {
+		input   string
+		matched bool
+		reason  string
+	}{
+		{"[100.000000] usb 1-1: new high-speed USB device", false, ""},
+		{"[200.000000] ata1.00: exception Emask 0x0 SAct 0x0", true, ReasonPattern},
+		{"[200.100000] ata1.00: cmd 60/08:00:00:00:00/00:00:00:00:00/40 tag 0 ncq", true, ReasonContext},
+		{"         res 41/40:00:00:00:00/00:00:00:00:00/00 Emask 0x409", true, ReasonContinuation},
+		{"[200.200000] usb 1-1: device descriptor read", false, ""},
+		{"[210.000000] ata1.00: configured for UDMA/133", false, ""}, // related, but too late
+	}
+	for _, l := range lines {
+		matched, reason := d.DetectReason([]byte(l.input))
+		if matched != l.matched || reason != l.reason {
+			t.Errorf("DetectReason(%q) = %v, %q; expected %v, %q", l.input, matched, reason, l.matched, l.reason)
+		}
+	}
+}
+
+func TestDmesgExtraPattern(t *testing.T) {
+	det, err := GetDetectorWithExtra("dmesg", "", "(?i)timeout")
+	if err != nil {
+		t.Fatalf("GetDetector failed: %v", err)
+	}
+	d, ok := det.(*DmesgDetector)
+	if !ok {
+		t.Fatalf("Expected a dmesg detector, got %T", det)
+	}
+
+	lines := []struct {
+		input   string
+		matched bool
+		reason  string
+	}{
+		{"[100.000000] usb 1-1: new high-speed USB device", false, ""},
+		{"[200.000000] nvme nvme0: I/O 12 QID 3 timeout, aborting", true, ReasonPattern},
+		{"[200.100000] nvme nvme0: Abort status: 0x0", true, ReasonContext},
+		{"[300.000000] ata1.00: exception Emask 0x0 SAct 0x0", true, ReasonPattern},
+		{"[400.000000] usb 1-1: device descriptor read", false, ""},
+	}
+	for _, l := range lines {
+		matched, reason := d.DetectReason([]byte(l.input))
+		if matched != l.matched || reason != l.reason {
+			t.Errorf("DetectReason(%q) = %v, %q; expected %v, %q", l.input, matched, reason, l.matched, l.reason)
+		}
+	}
+	// Timestamps are still handled by the dmesg detector
+	if got := string(d.TransformMessage([]byte("[200.000000] nvme nvme0: timeout"))); got != "nvme nvme0: timeout" {
+		t.Errorf("TransformMessage = %q", got)
+	}
+}
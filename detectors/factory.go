@@ -1,38 +1,225 @@
 package detectors
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
 
-// GetDetector returns a detector based on the format name.
-// If format is "custom" or empty, it requires a pattern and returns a GenericDetector.
-func GetDetector(format string, pattern string) (Detector, error) {
-	switch format {
-	case "dmesg":
-		return NewDmesgDetector(), nil
-	case "nginx":
-		return NewNginxDetector(), nil
-	case "nginx-error":
-		return NewNginxErrorDetector(), nil
-	case "json":
+// formatAliases maps alternative spellings to canonical detector format names.
+var formatAliases = map[string]string{
+	"jsonl":       "json",
+	"ndjson":      "json",
+	"nginx_error": "nginx-error",
+	"nginxerror":  "nginx-error",
+	"kernel":      "dmesg",
+	"apache":      "nginx", // same combined access log format
+	"w3c":         "iis",
+}
+
+// NormalizeFormat returns the canonical detector format name for the given input.
+// Matching is case-insensitive and known aliases (e.g. "jsonl", "nginx_error") are
+// mapped to their canonical names. Unknown names are returned lowercased.
+func NormalizeFormat(format string) string {
+	f := strings.ToLower(strings.TrimSpace(format))
+	if canonical, ok := formatAliases[f]; ok {
+		return canonical
+	}
+	return f
+}
+
+// Factory builds a detector from the monitor's pattern (which may be empty).
+type Factory func(pattern string) (Detector, error)
+
+// Info describes a registered detector, as listed by --list-detectors.
+type Info struct {
+	Name        string
+	Aliases     []string // alternative format names, see NormalizeFormat
+	Description string
+	Context     bool // extracts context fields (ContextExtractor)
+	Timestamp   bool // extracts the line's own timestamp (TimestampExtractor)
+}
+
+type registration struct {
+	factory Factory
+	info    Info
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+)
+
+func init() {
+	RegisterWithInfo(Info{
+		Name:        "dmesg",
+		Description: "Kernel log with [seconds] timestamps; reports error, fail, panic, oops and exception",
+		Timestamp:   true,
+	}, func(string) (Detector, error) { return NewDmesgDetector(), nil })
+	RegisterWithInfo(Info{
+		Name:        "nginx",
+		Description: "Combined access log format (nginx, apache); reports 5xx responses",
+		Context:     true,
+		Timestamp:   true,
+	}, func(string) (Detector, error) { return NewNginxDetector(), nil })
+	RegisterWithInfo(Info{
+		Name:        "nginx-error",
+		Description: "nginx error log; reports error, crit, alert and emerg lines",
+	}, func(string) (Detector, error) { return NewNginxErrorDetector(), nil })
+	RegisterWithInfo(Info{
+		Name:        "haproxy",
+		Description: "HAProxy HTTP log; reports 5xx and server-side terminations",
+		Context:     true,
+		Timestamp:   true,
+	}, func(string) (Detector, error) { return NewHAProxyDetector(), nil })
+	RegisterWithInfo(Info{
+		Name:        "iis",
+		Description: "IIS W3C extended log, layout read from #Fields:; reports 5xx responses",
+		Context:     true,
+		Timestamp:   true,
+	}, func(string) (Detector, error) { return NewIISDetector(), nil })
+	RegisterWithInfo(Info{
+		Name:        "json",
+		Description: "One JSON object per line; pattern is key:regex",
+		Context:     true,
+		Timestamp:   true,
+	}, func(pattern string) (Detector, error) {
 		if pattern == "" {
 			return nil, fmt.Errorf("pattern is required for json detector (format: key:regex)")
 		}
 		return NewJsonDetector(pattern)
-	case "custom", "":
+	})
+	RegisterWithInfo(Info{
+		Name:        "custom",
+		Description: "Any line matching pattern (without one: error, fail, panic, fatal, exception); named groups become context (default)",
+		Context:     true,
+	}, func(pattern string) (Detector, error) {
 		if pattern == "" {
-			return nil, fmt.Errorf("pattern is required for custom detector")
+			// An empty pattern would match every line
+			pattern = DefaultPattern
 		}
 		return NewGenericDetector(pattern)
-	default:
+	})
+}
+
+// Register makes a detector available to GetDetector (and format: in the
+// config) under name. Names are matched like NormalizeFormat, so they are
+// case-insensitive. It is meant to be called from an init function of a
+// program embedding sentrylogmon, and panics if name is empty, factory is
+// nil, or name is already registered. Detectors that extract context should
+// use RegisterWithInfo with Context set, or group_by is rejected for them.
+func Register(name string, factory Factory) {
+	RegisterWithInfo(Info{Name: name}, factory)
+}
+
+// RegisterWithInfo is like Register, with a description and capabilities to
+// show in --list-detectors. The name is info.Name.
+func RegisterWithInfo(info Info, factory Factory) {
+	key := NormalizeFormat(info.Name)
+	if key == "" {
+		panic("detectors: Register with empty name")
+	}
+	if factory == nil {
+		panic("detectors: Register factory is nil for " + info.Name)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[key]; dup {
+		panic("detectors: Register called twice for " + info.Name)
+	}
+	info.Name = key
+	registry[key] = registration{factory: factory, info: info}
+}
+
+func lookup(format string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[format]
+	return r.factory, ok
+}
+
+// Describe returns what is known about the detector for format, matched like
+// GetDetector, or false if there is none.
+func Describe(format string) (Info, bool) {
+	key := NormalizeFormat(format)
+	if key == "" {
+		key = "custom"
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[key]
+	return r.info, ok
+}
+
+// List returns the registered detectors sorted by name, with the aliases
+// NormalizeFormat maps to each.
+func List() []Info {
+	registryMu.RLock()
+	infos := make([]Info, 0, len(registry))
+	for _, r := range registry {
+		infos = append(infos, r.info)
+	}
+	registryMu.RUnlock()
+
+	for i := range infos {
+		var aliases []string
+		for alias, canonical := range formatAliases {
+			if canonical == infos[i].Name {
+				aliases = append(aliases, alias)
+			}
+		}
+		sort.Strings(aliases)
+		infos[i].Aliases = aliases
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// DefaultPattern is what the custom detector matches when it is given no
+// pattern, e.g. for a file monitor with neither pattern nor format.
+const DefaultPattern = `(?i)(error|fail|panic|fatal|exception)`
+
+// GetDetector returns a detector based on the format name.
+// If format is "custom" or empty, it returns a GenericDetector for pattern,
+// or for DefaultPattern if pattern is empty.
+func GetDetector(format string, pattern string) (Detector, error) {
+	return GetDetectorWithExtra(format, pattern, "")
+}
+
+// GetDetectorWithExtra is like GetDetector; a non-empty extraPattern is
+// matched in addition to the detector's own pattern (see PatternExtender).
+func GetDetectorWithExtra(format string, pattern string, extraPattern string) (Detector, error) {
+	key := NormalizeFormat(format)
+	if key == "" {
+		key = "custom"
+	}
+	factory, ok := lookup(key)
+	if !ok {
 		return nil, fmt.Errorf("unknown detector format: %s", format)
 	}
+	d, err := factory(pattern)
+	if err != nil || extraPattern == "" {
+		return d, err
+	}
+	pe, ok := d.(PatternExtender)
+	if !ok {
+		return nil, fmt.Errorf("format %s does not support extra_pattern", key)
+	}
+	if err := pe.ExtendPattern(extraPattern); err != nil {
+		return nil, fmt.Errorf("invalid extra_pattern: %w", err)
+	}
+	return d, nil
 }
 
-// IsKnownDetector checks if the given name matches a known detector type.
+// IsKnownDetector checks if the given name matches a known detector type,
+// built-in or registered. "custom" is not considered known.
+// Matching is case-insensitive and accepts aliases (see NormalizeFormat).
 func IsKnownDetector(name string) bool {
-	switch name {
-	case "dmesg", "nginx", "nginx-error", "json":
-		return true
-	default:
+	key := NormalizeFormat(name)
+	if key == "custom" {
 		return false
 	}
+	_, ok := lookup(key)
+	return ok
 }
@@ -0,0 +1,70 @@
+package detectors
+
+import (
+	"bytes"
+	"testing"
+)
+
+// prefixDetector reports lines starting with its pattern.
+type prefixDetector struct {
+	prefix []byte
+}
+
+func (d *prefixDetector) Detect(line []byte) bool {
+	return bytes.HasPrefix(line, d.prefix)
+}
+
+func TestRegister(t *testing.T) {
+	Register("Test-Prefix", func(pattern string) (Detector, error) {
+		return &prefixDetector{prefix: []byte(pattern)}, nil
+	})
+
+	if !IsKnownDetector("test-prefix") {
+		t.Error("Expected registered detector to be known")
+	}
+	d, err := GetDetector("TEST-PREFIX", "ALERT")
+	if err != nil {
+		t.Fatalf("GetDetector failed: %v", err)
+	}
+	if !d.Detect([]byte("ALERT disk full")) || d.Detect([]byte("disk full ALERT")) {
+		t.Error("Expected the registered detector to be used")
+	}
+
+	// Built-ins are unchanged
+	if _, err := GetDetector("json", ""); err == nil {
+		t.Error("Expected json detector without pattern to fail")
+	}
+	if _, err := GetDetector("nope", "x"); err == nil {
+		t.Error("Expected unknown format to fail")
+	}
+	if _, err := GetDetectorWithExtra("json", "level:error", "timeout"); err == nil {
+		t.Error("Expected extra_pattern on json to fail")
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Register to panic for a built-in name")
+		}
+	}()
+	Register("NGINX", func(string) (Detector, error) { return nil, nil })
+}
+
+func TestCustomDetectorDefaultPattern(t *testing.T) {
+	for _, format := range []string{"custom", ""} {
+		d, err := GetDetector(format, "")
+		if err != nil {
+			t.Fatalf("GetDetector(%q, \"\") failed: %v", format, err)
+		}
+		if !d.Detect([]byte("2026-10-16 12:00:00 ERROR connection refused")) {
+			t.Errorf("Expected %q detector without pattern to match an error line", format)
+		}
+		if !d.Detect([]byte("Unhandled Exception in worker")) {
+			t.Errorf("Expected %q detector without pattern to match an exception line", format)
+		}
+		if d.Detect([]byte("2026-10-16 12:00:00 INFO request served")) {
+			t.Errorf("Expected %q detector without pattern not to match every line", format)
+		}
+	}
+}
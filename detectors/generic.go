@@ -7,9 +7,10 @@ import (
 
 // GenericDetector uses a regex pattern to detect issues.
 type GenericDetector struct {
-	pattern   *regexp.Regexp
-	literal   []byte
-	isLiteral bool
+	pattern        *regexp.Regexp
+	literal        []byte
+	isLiteral      bool
+	hasNamedGroups bool
 }
 
 func NewGenericDetector(pattern string) (*GenericDetector, error) {
@@ -20,11 +21,36 @@ func NewGenericDetector(pattern string) (*GenericDetector, error) {
 		}, nil
 	}
 
-	re, err := regexp.Compile(pattern)
+	re, err := compileCached(pattern)
 	if err != nil {
 		return nil, err
 	}
-	return &GenericDetector{pattern: re}, nil
+	d := &GenericDetector{pattern: re}
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			d.hasNamedGroups = true
+			break
+		}
+	}
+	return d, nil
+}
+
+// ExtendPattern makes d match extra as well as its own pattern. Built-in
+// detectors embedding a GenericDetector (dmesg, nginx, nginx-error) get it
+// too, so their pattern can be extended.
+func (d *GenericDetector) ExtendPattern(extra string) error {
+	var own string
+	if d.isLiteral {
+		own = regexp.QuoteMeta(string(d.literal))
+	} else {
+		own = d.pattern.String()
+	}
+	combined, err := NewGenericDetector("(?:" + own + ")|(?:" + extra + ")")
+	if err != nil {
+		return err
+	}
+	*d = *combined
+	return nil
 }
 
 func (d *GenericDetector) Detect(line []byte) bool {
@@ -33,3 +59,37 @@ func (d *GenericDetector) Detect(line []byte) bool {
 	}
 	return d.pattern.Match(line)
 }
+
+// Match returns the part of the line matched by the pattern, or nil.
+func (d *GenericDetector) Match(line []byte) []byte {
+	if d.isLiteral {
+		if bytes.Contains(line, d.literal) {
+			return d.literal
+		}
+		return nil
+	}
+	loc := d.pattern.FindIndex(line)
+	if loc == nil {
+		return nil
+	}
+	return line[loc[0]:loc[1]]
+}
+
+// GetContext returns the named capture groups of the pattern (e.g. (?P<request_id>\w+)).
+// It returns nil if the pattern has no named groups or does not match.
+func (d *GenericDetector) GetContext(line []byte) map[string]interface{} {
+	if !d.hasNamedGroups {
+		return nil
+	}
+	match := d.pattern.FindSubmatch(line)
+	if match == nil {
+		return nil
+	}
+	ctx := make(map[string]interface{})
+	for i, name := range d.pattern.SubexpNames() {
+		if name != "" && match[i] != nil {
+			ctx[name] = string(match[i])
+		}
+	}
+	return ctx
+}
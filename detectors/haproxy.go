@@ -0,0 +1,104 @@
+package detectors
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// haproxyHTTPRegex matches the HTTP-mode log format ("option httplog"):
+// client:port [accept_date] frontend backend/server Tq/Tw/Tc/Tr/Tt status bytes req_cookie res_cookie term_state ...
+// Timers may be -1 (not reached), and Tt/bytes may be prefixed with '+' when logasap is enabled.
+var haproxyHTTPRegex = regexp.MustCompile(
+	`(\S+):(\d+) \[([^\]]+)\] (\S+) ([^/\s]+)/(\S+) (-?\d+)/(-?\d+)/(-?\d+)/(-?\d+)/\+?(-?\d+) (-?\d+) \+?(\d+) \S+ \S+ (\S{4})(?:.*?"([^"]*)")?`,
+)
+
+// haproxyErrorTermStates lists the first two termination flags that indicate a failure,
+// even when the status code is not 5xx (e.g. the server aborted mid-response).
+//   - sH: server timeout while waiting for response headers
+//   - SH: server aborted before sending complete headers
+//   - sQ: timed out in the queue waiting for a server slot
+//   - SC: server refused the connection
+//   - PH: proxy blocked an invalid/malformed server response
+var haproxyErrorTermStates = map[string]bool{
+	"sH": true,
+	"SH": true,
+	"sQ": true,
+	"SC": true,
+	"PH": true,
+}
+
+const haproxyAcceptDateLayout = "02/Jan/2006:15:04:05.000"
+
+// HAProxyDetector detects issues in HAProxy HTTP-mode logs.
+// A line is reported if the status code is 5xx or the termination state
+// indicates a server-side failure (see haproxyErrorTermStates).
+type HAProxyDetector struct{}
+
+func NewHAProxyDetector() *HAProxyDetector {
+	return &HAProxyDetector{}
+}
+
+func (d *HAProxyDetector) Detect(line []byte) bool {
+	m := haproxyHTTPRegex.FindSubmatch(line)
+	if m == nil {
+		return false
+	}
+
+	if status, err := strconv.Atoi(string(m[12])); err == nil && status >= 500 && status <= 599 {
+		return true
+	}
+
+	return haproxyErrorTermStates[string(m[14][:2])]
+}
+
+// GetContext returns the parsed HAProxy fields (backend, status, timers, etc.).
+func (d *HAProxyDetector) GetContext(line []byte) map[string]interface{} {
+	m := haproxyHTTPRegex.FindSubmatch(line)
+	if m == nil {
+		return nil
+	}
+
+	ctx := map[string]interface{}{
+		"client_ip":         string(m[1]),
+		"client_port":       string(m[2]),
+		"accept_date":       string(m[3]),
+		"frontend":          string(m[4]),
+		"backend":           string(m[5]),
+		"server":            string(m[6]),
+		"termination_state": string(m[14]),
+	}
+
+	timers := []string{"Tq", "Tw", "Tc", "Tr", "Tt"}
+	for i, name := range timers {
+		if v, err := strconv.Atoi(string(m[7+i])); err == nil {
+			ctx[name] = v
+		}
+	}
+	if v, err := strconv.Atoi(string(m[12])); err == nil {
+		ctx["status_code"] = v
+	}
+	if v, err := strconv.Atoi(string(m[13])); err == nil {
+		ctx["bytes_read"] = v
+	}
+	if len(m[15]) > 0 {
+		ctx["request"] = string(m[15])
+	}
+
+	return ctx
+}
+
+// ExtractTimestamp parses the HAProxy accept date (e.g. 06/Feb/2009:12:14:14.655).
+// HAProxy logs the accept date in local time without a zone offset.
+func (d *HAProxyDetector) ExtractTimestamp(line []byte) (float64, string, bool) {
+	m := haproxyHTTPRegex.FindSubmatch(line)
+	if m == nil {
+		return 0, "", false
+	}
+	tsStr := string(m[3])
+	t, err := time.ParseInLocation(haproxyAcceptDateLayout, tsStr, time.Local)
+	if err != nil {
+		return 0, "", false
+	}
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9, tsStr, true
+}
@@ -0,0 +1,104 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHAProxyDetector_GetContext(t *testing.T) {
+	d := NewHAProxyDetector()
+	line := []byte(`Feb  6 12:14:16 lb haproxy[14389]: 10.0.1.4:33319 [06/Feb/2009:12:14:16.120] http-in app/srv1 5/0/2/-1/30002 504 194 - - sH-- 10/10/8/4/0 0/0 "GET /api/slow HTTP/1.1"`)
+
+	if !d.Detect(line) {
+		t.Fatal("Expected line to be detected")
+	}
+
+	ctx := d.GetContext(line)
+	if ctx == nil {
+		t.Fatal("Expected context, got nil")
+	}
+
+	expected := map[string]interface{}{
+		"client_ip":         "10.0.1.4",
+		"frontend":          "http-in",
+		"backend":           "app",
+		"server":            "srv1",
+		"Tq":                5,
+		"Tw":                0,
+		"Tc":                2,
+		"Tr":                -1,
+		"Tt":                30002,
+		"status_code":       504,
+		"bytes_read":        194,
+		"termination_state": "sH--",
+		"request":           "GET /api/slow HTTP/1.1",
+	}
+	for k, want := range expected {
+		if got := ctx[k]; got != want {
+			t.Errorf("ctx[%q] = %v (%T), want %v (%T)", k, got, got, want, want)
+		}
+	}
+}
+
+func TestHAProxyDetector_Detect(t *testing.T) {
+	d := NewHAProxyDetector()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "200 OK",
+			input:    `10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in static/srv1 10/0/30/69/109 200 2750 - - ---- 1/1/1/1/0 0/0 "GET / HTTP/1.1"`,
+			expected: false,
+		},
+		{
+			name:     "502 Bad Gateway",
+			input:    `10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in app/srv1 10/0/30/69/109 502 2750 - - ---- 1/1/1/1/0 0/0 "GET / HTTP/1.1"`,
+			expected: true,
+		},
+		{
+			name:     "Server refused connection",
+			input:    `10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in app/srv1 10/0/-1/-1/12 -1 0 - - SC-- 1/1/1/1/3 0/0 "GET / HTTP/1.1"`,
+			expected: true,
+		},
+		{
+			name:     "Client abort is not an error",
+			input:    `10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in app/srv1 10/0/30/69/109 200 2750 - - CD-- 1/1/1/1/0 0/0 "GET / HTTP/1.1"`,
+			expected: false,
+		},
+		{
+			name:     "Non-HTTP line",
+			input:    `Proxy http-in started.`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Detect([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Detect() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHAProxyDetector_ExtractTimestamp(t *testing.T) {
+	d := NewHAProxyDetector()
+	line := []byte(`10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in static/srv1 10/0/30/69/109 503 2750 - - ---- 1/1/1/1/0 0/0 "GET / HTTP/1.1"`)
+
+	ts, tsStr, ok := d.ExtractTimestamp(line)
+	if !ok {
+		t.Fatal("Expected timestamp to be extracted")
+	}
+	if tsStr != "06/Feb/2009:12:14:14.655" {
+		t.Errorf("tsStr = %q, want %q", tsStr, "06/Feb/2009:12:14:14.655")
+	}
+
+	want := time.Date(2009, time.February, 6, 12, 14, 14, 655000000, time.Local)
+	expectedTs := float64(want.Unix()) + float64(want.Nanosecond())/1e9
+	if ts != expectedTs {
+		t.Errorf("ts = %v, want %v", ts, expectedTs)
+	}
+}
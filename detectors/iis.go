@@ -0,0 +1,143 @@
+package detectors
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// iisDefaultFields is the column layout IIS writes by default. It is used
+// until a #Fields: directive is seen, e.g. when tailing a log from its end.
+var iisDefaultFields = []string{
+	"date", "time", "s-ip", "cs-method", "cs-uri-stem", "cs-uri-query", "s-port",
+	"cs-username", "c-ip", "cs(User-Agent)", "cs(Referer)", "sc-status",
+	"sc-substatus", "sc-win32-status", "time-taken",
+}
+
+// iisIntFields are attached to the context as numbers.
+var iisIntFields = map[string]bool{
+	"s-port":          true,
+	"sc-status":       true,
+	"sc-substatus":    true,
+	"sc-win32-status": true,
+	"sc-bytes":        true,
+	"cs-bytes":        true,
+	"time-taken":      true,
+}
+
+// IISDetector detects issues in IIS (W3C extended) logs and reports 5xx
+// responses. The column layout is read from the #Fields: directive that IIS
+// writes at the top of each file and whenever the logged fields change, so a
+// detector must not be shared between log files.
+type IISDetector struct {
+	mu     sync.RWMutex
+	fields []string
+}
+
+func NewIISDetector() *IISDetector {
+	return &IISDetector{fields: iisDefaultFields}
+}
+
+// Detect reports data rows with a 5xx sc-status. Directives (lines starting
+// with '#') are never reported.
+func (d *IISDetector) Detect(line []byte) bool {
+	if d.directive(line) {
+		return false
+	}
+	status, err := strconv.Atoi(d.field(line, "sc-status"))
+	return err == nil && status >= 500 && status <= 599
+}
+
+// DependsOnLineOrder returns true: the layout of a row is the one of the last
+// #Fields: directive before it.
+func (d *IISDetector) DependsOnLineOrder() bool {
+	return true
+}
+
+// directive handles a directive line, learning the layout from #Fields:, and
+// reports whether line was one.
+func (d *IISDetector) directive(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '#' {
+		return false
+	}
+	if rest, ok := bytes.CutPrefix(line, []byte("#Fields:")); ok {
+		fields := strings.Fields(string(rest))
+		if len(fields) > 0 {
+			d.mu.Lock()
+			d.fields = fields
+			d.mu.Unlock()
+		}
+	}
+	return true
+}
+
+// parse maps the columns of a data row to the current field names. "-" marks
+// an empty value in W3C logs and is left out.
+func (d *IISDetector) parse(line []byte) map[string]string {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return nil
+	}
+	d.mu.RLock()
+	names := d.fields
+	d.mu.RUnlock()
+
+	values := strings.Fields(string(line))
+	row := make(map[string]string, len(values))
+	for i, value := range values {
+		if i >= len(names) {
+			break
+		}
+		if value != "-" {
+			row[names[i]] = value
+		}
+	}
+	return row
+}
+
+func (d *IISDetector) field(line []byte, name string) string {
+	return d.parse(line)[name]
+}
+
+// GetContext returns the fields of a data row by their W3C names (sc-status,
+// cs-method, cs-uri-stem, time-taken, ...), plus status_code.
+func (d *IISDetector) GetContext(line []byte) map[string]interface{} {
+	row := d.parse(line)
+	if len(row) == 0 {
+		return nil
+	}
+	ctx := make(map[string]interface{}, len(row)+1)
+	for name, value := range row {
+		if iisIntFields[name] {
+			if v, err := strconv.Atoi(value); err == nil {
+				ctx[name] = v
+				continue
+			}
+		}
+		// "+" stands for a space in W3C logs, e.g. in the user agent
+		ctx[name] = strings.ReplaceAll(value, "+", " ")
+	}
+	if status, ok := ctx["sc-status"]; ok {
+		ctx["status_code"] = status
+	}
+	return ctx
+}
+
+// ExtractTimestamp parses the date and time fields (e.g. 2026-10-11 22:14:15),
+// which IIS logs in UTC.
+func (d *IISDetector) ExtractTimestamp(line []byte) (float64, string, bool) {
+	row := d.parse(line)
+	date, clock := row["date"], row["time"]
+	if date == "" || clock == "" {
+		return 0, "", false
+	}
+	tsStr := date + " " + clock
+	t, err := time.Parse("2006-01-02 15:04:05", tsStr)
+	if err != nil {
+		return 0, "", false
+	}
+	return float64(t.Unix()), tsStr, true
+}
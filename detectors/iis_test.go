@@ -0,0 +1,84 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIISDetector_GetContext(t *testing.T) {
+	d := NewIISDetector()
+	d.Detect([]byte("#Fields: date time cs-method cs-uri-stem cs-uri-query sc-status sc-substatus time-taken cs(User-Agent)"))
+	line := []byte("2026-10-11 22:14:02 POST /api/orders id=7 502 3 1203 Mozilla/5.0+(Windows+NT+10.0)")
+
+	if !d.Detect(line) {
+		t.Fatal("Expected line to be detected")
+	}
+
+	ctx := d.GetContext(line)
+	if ctx == nil {
+		t.Fatal("Expected context, got nil")
+	}
+	expected := map[string]interface{}{
+		"cs-method":      "POST",
+		"cs-uri-stem":    "/api/orders",
+		"cs-uri-query":   "id=7",
+		"sc-status":      502,
+		"sc-substatus":   3,
+		"status_code":    502,
+		"time-taken":     1203,
+		"cs(User-Agent)": "Mozilla/5.0 (Windows NT 10.0)",
+	}
+	for k, want := range expected {
+		if got := ctx[k]; got != want {
+			t.Errorf("ctx[%q] = %v (%T), want %v (%T)", k, got, got, want, want)
+		}
+	}
+
+	ts, tsStr, ok := d.ExtractTimestamp(line)
+	want := time.Date(2026, 10, 11, 22, 14, 2, 0, time.UTC)
+	if !ok || ts != float64(want.Unix()) || tsStr != "2026-10-11 22:14:02" {
+		t.Errorf("ExtractTimestamp = %v, %q, %v; want %v", ts, tsStr, ok, float64(want.Unix()))
+	}
+}
+
+func TestIISDetector_Detect(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "default layout 500",
+			input:    "2026-10-11 22:14:02 10.0.0.5 POST /api/orders - 443 - 203.0.113.8 curl/8.4.0 - 500 0 0 1203",
+			expected: true,
+		},
+		{
+			name:     "default layout 200",
+			input:    "2026-10-11 22:14:01 10.0.0.5 GET /index.html - 443 - 203.0.113.7 curl/8.4.0 - 200 0 0 15",
+			expected: false,
+		},
+		{
+			name:     "directive",
+			input:    "#Software: Microsoft Internet Information Services 10.0",
+			expected: false,
+		},
+		{
+			name:     "truncated row",
+			input:    "2026-10-11 22:14:01 10.0.0.5 GET",
+			expected: false,
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewIISDetector().Detect([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Detect() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
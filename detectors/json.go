@@ -2,6 +2,8 @@ package detectors
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -14,6 +16,10 @@ type JsonDetector struct {
 	Field    string
 	Pattern  *regexp.Regexp
 
+	// Optional field whose value is decoded before matching and context extraction
+	decodeField    string
+	decodeEncoding string
+
 	mu       sync.Mutex
 	lastData map[string]interface{}
 	lastLine []byte
@@ -27,7 +33,7 @@ func NewJsonDetector(pattern string) (*JsonDetector, error) {
 	field := strings.TrimSpace(parts[0])
 	regexStr := strings.TrimSpace(parts[1])
 
-	re, err := regexp.Compile(regexStr)
+	re, err := compileCached(regexStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid regex for json detector: %v", err)
 	}
@@ -38,12 +44,67 @@ func NewJsonDetector(pattern string) (*JsonDetector, error) {
 	}, nil
 }
 
+// SetDecodeField decodes the named field (base64 or hex) in every line, so
+// the decoded text is what the pattern matches and what appears in the context.
+// Values that fail to decode are left as they are.
+func (d *JsonDetector) SetDecodeField(field, encoding string) error {
+	switch encoding {
+	case "base64", "hex":
+	default:
+		return fmt.Errorf("unknown decode encoding '%s' (valid encodings: base64, hex)", encoding)
+	}
+	d.decodeField = field
+	d.decodeEncoding = encoding
+	return nil
+}
+
+// parse unmarshals line and decodes the decode field, if configured.
+func (d *JsonDetector) parse(line []byte) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(line, &data); err != nil {
+		return nil, err
+	}
+	if d.decodeField != "" {
+		if s, ok := data[d.decodeField].(string); ok {
+			if decoded, ok := decodeString(s, d.decodeEncoding); ok {
+				data[d.decodeField] = decoded
+			}
+		}
+	}
+	return data, nil
+}
+
+func decodeString(s, encoding string) (string, bool) {
+	var b []byte
+	var err error
+	switch encoding {
+	case "base64":
+		s = strings.TrimSpace(s)
+		b, err = base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			// Also accept unpadded and URL-safe encodings
+			b, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+			if err != nil {
+				b, err = base64.URLEncoding.DecodeString(s)
+			}
+		}
+	case "hex":
+		b, err = hex.DecodeString(strings.TrimSpace(s))
+	default:
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
 func (d *JsonDetector) Detect(line []byte) bool {
 	// We do not lock initially because Unmarshal is heavy and we don't want to block readers if possible.
 	// However, usually Detect is called before readers.
 
-	var data map[string]interface{}
-	if err := json.Unmarshal(line, &data); err != nil {
+	data, err := d.parse(line)
+	if err != nil {
 		d.mu.Lock()
 		d.lastData = nil
 		d.lastLine = nil
@@ -79,6 +140,16 @@ func (d *JsonDetector) Detect(line []byte) bool {
 	return false
 }
 
+// ParsesFormat returns true if line is a JSON object.
+func (d *JsonDetector) ParsesFormat(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	return len(line) > 0 && line[0] == '{' && json.Valid(line)
+}
+
+func (d *JsonDetector) FormatName() string {
+	return "JSON"
+}
+
 func (d *JsonDetector) GetContext(line []byte) map[string]interface{} {
 	d.mu.Lock()
 	// Verify cache validity by checking content equality
@@ -89,8 +160,8 @@ func (d *JsonDetector) GetContext(line []byte) map[string]interface{} {
 	}
 	d.mu.Unlock()
 
-	var data map[string]interface{}
-	if err := json.Unmarshal(line, &data); err != nil {
+	data, err := d.parse(line)
+	if err != nil {
 		return nil
 	}
 	return data
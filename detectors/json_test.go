@@ -199,3 +199,79 @@ func TestJsonDetector_Concurrency(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestJsonDetector_DecodeField(t *testing.T) {
+	d, err := NewJsonDetector("stack:NullPointerException")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	if err := d.SetDecodeField("stack", "base64"); err != nil {
+		t.Fatalf("Failed to set decode field: %v", err)
+	}
+
+	// base64("java.lang.NullPointerException at Foo.bar(Foo.java:42)")
+	input := []byte(`{"level":"error","stack":"amF2YS5sYW5nLk51bGxQb2ludGVyRXhjZXB0aW9uIGF0IEZvby5iYXIoRm9vLmphdmE6NDIp"}`)
+	if !d.Detect(input) {
+		t.Fatal("Expected pattern to match the decoded field")
+	}
+	ctx := d.GetContext(input)
+	if ctx["stack"] != "java.lang.NullPointerException at Foo.bar(Foo.java:42)" {
+		t.Errorf("Expected decoded stack in context, got %v", ctx["stack"])
+	}
+
+	// Without a cached Detect, GetContext decodes too
+	other := []byte(`{"level":"info","stack":"aGVsbG8="}`)
+	if d.Detect(other) {
+		t.Error("Expected no match for decoded text without the pattern")
+	}
+	if ctx := d.GetContext(other); ctx["stack"] != "hello" {
+		t.Errorf("Expected decoded stack 'hello', got %v", ctx["stack"])
+	}
+
+	// Values that are not valid base64 are matched as-is
+	plain := []byte(`{"stack":"NullPointerException!"}`)
+	if !d.Detect(plain) {
+		t.Error("Expected undecodable value to be matched as-is")
+	}
+}
+
+func TestJsonDetector_DecodeFieldHex(t *testing.T) {
+	d, err := NewJsonDetector("payload:timeout")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	if err := d.SetDecodeField("payload", "hex"); err != nil {
+		t.Fatalf("Failed to set decode field: %v", err)
+	}
+	// hex("read timeout")
+	if !d.Detect([]byte(`{"payload":"726561642074696d656f7574"}`)) {
+		t.Error("Expected pattern to match the hex-decoded field")
+	}
+
+	if err := d.SetDecodeField("payload", "rot13"); err == nil {
+		t.Error("Expected error for unknown encoding")
+	}
+}
+
+func TestJsonDetector_ParsesFormat(t *testing.T) {
+	d, err := NewJsonDetector("level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`{"level":"info","msg":"ok"}`, true},
+		{`  {"level":"info"}  `, true},
+		{`INFO request served`, false},
+		{`{"level":"info"`, false},
+		{`["not","an","object"]`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := d.ParsesFormat([]byte(tt.input)); got != tt.expected {
+			t.Errorf("ParsesFormat(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package detectors
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MultiDetector reports a line if any of its detectors does.
+type MultiDetector struct {
+	detectors []Detector
+}
+
+// NewMultiDetector returns a detector matching any of the regex patterns.
+func NewMultiDetector(patterns []string) (*MultiDetector, error) {
+	d := &MultiDetector{}
+	for i, p := range patterns {
+		gd, err := NewGenericDetector(p)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %d: %w", i+1, err)
+		}
+		d.detectors = append(d.detectors, gd)
+	}
+	return d, nil
+}
+
+func (d *MultiDetector) Detect(line []byte) bool {
+	for _, det := range d.detectors {
+		if det.Detect(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetContext returns the context of the first matching detector that provides one.
+func (d *MultiDetector) GetContext(line []byte) map[string]interface{} {
+	for _, det := range d.detectors {
+		extractor, ok := det.(ContextExtractor)
+		if !ok || !det.Detect(line) {
+			continue
+		}
+		if ctx := extractor.GetContext(line); ctx != nil {
+			return ctx
+		}
+	}
+	return nil
+}
+
+// Match returns the match of the first detector that matches the line.
+func (d *MultiDetector) Match(line []byte) []byte {
+	for _, det := range d.detectors {
+		if extractor, ok := det.(MatchExtractor); ok {
+			if match := extractor.Match(line); match != nil {
+				return match
+			}
+		}
+	}
+	return nil
+}
+
+// ReadPatternFile reads one regex pattern per line from path. Blank lines and
+// lines starting with '#' are ignored. Invalid regexes are reported with their
+// line number.
+func ReadPatternFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := NewGenericDetector(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid regex: %w", path, lineNo, err)
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("%s: no patterns found", path)
+	}
+	return patterns, nil
+}
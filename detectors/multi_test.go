@@ -0,0 +1,69 @@
+package detectors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadPatternFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	content := `# Known error signatures
+OutOfMemoryError
+
+(?i)connection (refused|reset) to (?P<host>\S+)
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns, err := ReadPatternFile(path)
+	if err != nil {
+		t.Fatalf("ReadPatternFile failed: %v", err)
+	}
+	if len(patterns) != 2 {
+		t.Fatalf("Expected 2 patterns, got %d: %v", len(patterns), patterns)
+	}
+
+	d, err := NewMultiDetector(patterns)
+	if err != nil {
+		t.Fatalf("NewMultiDetector failed: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"java.lang.OutOfMemoryError: Java heap space", true},
+		{"Connection Refused to db1:5432", true},
+		{"# Known error signatures", false},
+		{"all good", false},
+	}
+	for _, tt := range tests {
+		if got := d.Detect([]byte(tt.input)); got != tt.expected {
+			t.Errorf("Detect(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+
+	ctx := d.GetContext([]byte("connection reset to db2:5432"))
+	if ctx["host"] != "db2:5432" {
+		t.Errorf("Expected host from the matching pattern, got %v", ctx["host"])
+	}
+}
+
+func TestReadPatternFileInvalidRegex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	content := "# comment\nvalid\n(unclosed\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ReadPatternFile(path)
+	if err == nil {
+		t.Fatal("Expected error for invalid regex")
+	}
+	if !strings.Contains(err.Error(), "patterns.txt:3:") {
+		t.Errorf("Expected error to name line 3, got %v", err)
+	}
+}
@@ -1,10 +1,22 @@
 package detectors
 
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // NginxDetector detects issues in Nginx error logs.
 // Default pattern: (?i)(error|critical|alert|emerg)
 // Note: "warn" is often just noise, but can be added if needed.
+//
+// With a StatusFilter, access log lines (combined/common format, which Apache
+// uses too) are instead reported by their status code; error log lines still
+// use the pattern.
 type NginxDetector struct {
 	*GenericDetector
+	status *StatusFilter
 }
 
 func NewNginxDetector() *NginxDetector {
@@ -12,6 +24,33 @@ func NewNginxDetector() *NginxDetector {
 	return &NginxDetector{GenericDetector: d}
 }
 
+// SetStatusFilter makes access log lines match by status code instead of the pattern.
+func (d *NginxDetector) SetStatusFilter(f *StatusFilter) {
+	d.status = f
+}
+
+func (d *NginxDetector) Detect(line []byte) bool {
+	if d.status != nil {
+		if status, _, ok := parseAccessStatus(line); ok {
+			return d.status.Match(status)
+		}
+	}
+	return d.GenericDetector.Detect(line)
+}
+
+// GetContext returns the status code and request line of access log lines.
+func (d *NginxDetector) GetContext(line []byte) map[string]interface{} {
+	status, request, ok := parseAccessStatus(line)
+	if !ok {
+		return nil
+	}
+	ctx := map[string]interface{}{"status_code": status}
+	if request != "" {
+		ctx["request"] = request
+	}
+	return ctx
+}
+
 func (d *NginxDetector) ExtractTimestamp(line []byte) (float64, string, bool) {
 	if ts, tsStr, ok := ParseNginxError(line); ok {
 		return ts, tsStr, true
@@ -23,3 +62,75 @@ func (d *NginxDetector) ExtractTimestamp(line []byte) (float64, string, bool) {
 
 	return 0, "", false
 }
+
+// StatusFilter selects HTTP status codes by class (e.g. "5xx"), minus
+// individually ignored codes (e.g. 404).
+type StatusFilter struct {
+	classes [6]bool // indexed by the first digit
+	ignore  map[int]bool
+}
+
+// NewStatusFilter builds a StatusFilter from classes such as "4xx" and "5xx".
+// No classes means 5xx only.
+func NewStatusFilter(classes []string, ignore []int) (*StatusFilter, error) {
+	f := &StatusFilter{ignore: make(map[int]bool)}
+	if len(classes) == 0 {
+		classes = []string{"5xx"}
+	}
+	for _, c := range classes {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if len(c) != 3 || c[0] < '1' || c[0] > '5' || c[1:] != "xx" {
+			return nil, fmt.Errorf("invalid status class '%s' (valid classes: 1xx, 2xx, 3xx, 4xx, 5xx)", c)
+		}
+		f.classes[c[0]-'0'] = true
+	}
+	for _, code := range ignore {
+		if code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status code %d to ignore", code)
+		}
+		f.ignore[code] = true
+	}
+	return f, nil
+}
+
+// Match reports whether status is in one of the classes and not ignored.
+func (f *StatusFilter) Match(status int) bool {
+	if status < 100 || status > 599 || f.ignore[status] {
+		return false
+	}
+	return f.classes[status/100]
+}
+
+// parseAccessStatus finds the status code following the quoted request of an
+// access log line: ... [27/Oct/2023:10:00:00 +0000] "GET / HTTP/1.1" 502 157 ...
+func parseAccessStatus(line []byte) (int, string, bool) {
+	start := bytes.Index(line, []byte(`] "`))
+	if start < 0 {
+		return 0, "", false
+	}
+	start += 3
+	// The request ends at the first unescaped quote (Apache escapes quotes as \")
+	end := -1
+	for i := start; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '"' {
+			end = i
+			break
+		}
+	}
+	if end < 0 || end+5 > len(line) || line[end+1] != ' ' {
+		return 0, "", false
+	}
+	code := line[end+2 : end+5]
+	if end+5 < len(line) && line[end+5] != ' ' {
+		return 0, "", false
+	}
+	status, err := strconv.Atoi(string(code))
+	if err != nil || status < 100 || status > 599 {
+		return 0, "", false
+	}
+	return status, string(line[start:end]), true
+}
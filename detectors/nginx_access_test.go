@@ -81,3 +81,68 @@ func TestParseNginxAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestNginxStatusFilter(t *testing.T) {
+	const (
+		serverError = `10.0.0.1 - - [27/Oct/2023:10:00:00 +0000] "GET /api HTTP/1.1" 500 12 "-" "curl/8.0"`
+		notFound    = `10.0.0.1 - - [27/Oct/2023:10:00:01 +0000] "GET /error.png HTTP/1.1" 404 0 "-" "curl/8.0"`
+		forbidden   = `10.0.0.1 - - [27/Oct/2023:10:00:02 +0000] "POST /admin HTTP/1.1" 403 9`
+		ok          = `10.0.0.1 - - [27/Oct/2023:10:00:03 +0000] "GET /error HTTP/1.1" 200 512`
+		quoted      = `10.0.0.1 - - [27/Oct/2023:10:00:04 +0000] "GET /\"x\" HTTP/1.1" 502 0`
+		errorLog    = `2023/10/27 10:00:05 [error] 12#12: *1 connect() failed (111: Connection refused)`
+	)
+
+	tests := []struct {
+		name    string
+		classes []string
+		ignore  []int
+		want    map[string]bool
+	}{
+		{
+			name: "Default 5xx",
+			want: map[string]bool{serverError: true, notFound: false, forbidden: false, ok: false, quoted: true, errorLog: true},
+		},
+		{
+			name:    "4xx And 5xx Ignoring 404",
+			classes: []string{"4xx", "5XX"},
+			ignore:  []int{404},
+			want:    map[string]bool{serverError: true, notFound: false, forbidden: true, ok: false, errorLog: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewStatusFilter(tt.classes, tt.ignore)
+			if err != nil {
+				t.Fatalf("NewStatusFilter failed: %v", err)
+			}
+			d := NewNginxDetector()
+			d.SetStatusFilter(filter)
+			for line, want := range tt.want {
+				if got := d.Detect([]byte(line)); got != want {
+					t.Errorf("Detect(%q) = %v, want %v", line, got, want)
+				}
+			}
+		})
+	}
+
+	// Without a filter the pattern applies, so a 200 for /error matches
+	if !NewNginxDetector().Detect([]byte(ok)) {
+		t.Errorf("Expected the pattern to apply without a status filter")
+	}
+
+	ctx := NewNginxDetector().GetContext([]byte(serverError))
+	if ctx["status_code"] != 500 || ctx["request"] != "GET /api HTTP/1.1" {
+		t.Errorf("Unexpected context %v", ctx)
+	}
+	if ctx := NewNginxDetector().GetContext([]byte(errorLog)); ctx != nil {
+		t.Errorf("Expected no context for an error log line, got %v", ctx)
+	}
+
+	if _, err := NewStatusFilter([]string{"6xx"}, nil); err == nil {
+		t.Errorf("Expected an error for an invalid status class")
+	}
+	if _, err := NewStatusFilter(nil, []int{42}); err == nil {
+		t.Errorf("Expected an error for an invalid status code")
+	}
+}
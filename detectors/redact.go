@@ -0,0 +1,58 @@
+package detectors
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RedactedPlaceholder replaces redacted text.
+const RedactedPlaceholder = "[REDACTED]"
+
+// piiPatterns are scrubbed by a Redactor with PII scrubbing enabled.
+// Key/value secrets keep their key so the line stays readable.
+var piiPatterns = []struct {
+	re   *regexp.Regexp
+	repl []byte
+}{
+	{regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|auth)(["']?\s*[=:]\s*["']?)[^\s"',;&]+`), []byte("${1}${2}" + RedactedPlaceholder)},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]{8,}`), []byte("${1} " + RedactedPlaceholder)},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), []byte(RedactedPlaceholder)},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), []byte(RedactedPlaceholder)},
+	{regexp.MustCompile(`(?i)\b(?:[0-9a-f]{1,4}:){3,7}[0-9a-f]{1,4}\b`), []byte(RedactedPlaceholder)},
+	{regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), []byte(RedactedPlaceholder)},
+}
+
+// Redactor replaces sensitive text in log lines with RedactedPlaceholder.
+// It implements MessageTransformer.
+type Redactor struct {
+	patterns []*regexp.Regexp
+	scrubPII bool
+}
+
+// NewRedactor returns a Redactor for the given regex patterns. With scrubPII set
+// it also scrubs common personal data and secrets: email and IP addresses,
+// card-like numbers, bearer tokens and password/token/key values.
+func NewRedactor(patterns []string, scrubPII bool) (*Redactor, error) {
+	r := &Redactor{scrubPII: scrubPII}
+	for i, p := range patterns {
+		re, err := compileCached(p)
+		if err != nil {
+			return nil, fmt.Errorf("redact pattern %d: %w", i+1, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// TransformMessage returns line with all redactions applied.
+func (r *Redactor) TransformMessage(line []byte) []byte {
+	for _, re := range r.patterns {
+		line = re.ReplaceAllLiteral(line, []byte(RedactedPlaceholder))
+	}
+	if r.scrubPII {
+		for _, p := range piiPatterns {
+			line = p.re.ReplaceAll(line, p.repl)
+		}
+	}
+	return line
+}
@@ -0,0 +1,175 @@
+package detectors
+
+import (
+	"bytes"
+	"time"
+)
+
+// SyslogHeader is the header of a syslog line, either RFC 3164 as written by
+// rsyslog, e.g. "Oct 11 22:14:15 host app[pid]: message" (rsyslog's RFC 3339
+// timestamps are accepted too), or RFC 5424, e.g.
+// "<34>1 2026-10-11T22:14:15Z host app 1234 ID47 - message".
+type SyslogHeader struct {
+	Version   int // 1 for RFC 5424, 0 for RFC 3164
+	Timestamp string
+	Host      string
+	App       string
+	PID       string // empty if not given
+	MsgID     string // RFC 5424 only; empty if not given
+}
+
+var syslogMonths = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// ParseSyslogHeader returns the header of a syslog line, or nil if it has none.
+// Lines with an RFC 3339 timestamp but no <PRI> are only parsed by
+// StripSyslogHeader, since "<time> word word: message" is common outside syslog.
+func ParseSyslogHeader(line []byte) *SyslogHeader {
+	h, _, _ := parseSyslogHeader(line, false)
+	return h
+}
+
+// StripSyslogHeader returns the line without its syslog header and the parsed
+// header, or the line unchanged and nil if it has none. A leading <PRI> is kept
+// for syslog priority parsing.
+func StripSyslogHeader(line []byte) ([]byte, *SyslogHeader) {
+	h, priEnd, msgStart := parseSyslogHeader(line, true)
+	if h == nil {
+		return line, nil
+	}
+	out := make([]byte, 0, priEnd+len(line)-msgStart)
+	out = append(out, line[:priEnd]...)
+	out = append(out, line[msgStart:]...)
+	return out, h
+}
+
+// parseSyslogHeader parses the header of line and returns it with the end of
+// the <PRI> (0 if there is none) and the start of the message.
+func parseSyslogHeader(line []byte, rfc3339WithoutPri bool) (*SyslogHeader, int, int) {
+	rest := line
+	priEnd := 0
+	if len(rest) > 2 && rest[0] == '<' {
+		limit := min(len(rest), 5)
+		if end := bytes.IndexByte(rest[:limit], '>'); end > 1 {
+			priEnd = end + 1
+			rest = rest[priEnd:]
+		}
+	}
+
+	if priEnd > 0 && len(rest) > 2 && rest[0] == '1' && rest[1] == ' ' {
+		h, n := parseRFC5424Header(rest[2:])
+		if h == nil {
+			return nil, 0, 0
+		}
+		return h, priEnd, priEnd + 2 + n
+	}
+
+	var h SyslogHeader
+	if len(rest) > 16 && isSyslogMonth(rest[:3]) && rest[3] == ' ' && rest[9] == ':' && rest[12] == ':' && rest[15] == ' ' {
+		// "Oct 11 22:14:15" or "Oct  1 22:14:15"
+		h.Timestamp = string(rest[:15])
+		rest = rest[16:]
+	} else {
+		if priEnd == 0 && !rfc3339WithoutPri {
+			return nil, 0, 0
+		}
+		sp := bytes.IndexByte(rest, ' ')
+		if sp <= 0 {
+			return nil, 0, 0
+		}
+		if _, err := time.Parse(time.RFC3339Nano, string(rest[:sp])); err != nil {
+			return nil, 0, 0
+		}
+		h.Timestamp = string(rest[:sp])
+		rest = rest[sp+1:]
+	}
+
+	sp := bytes.IndexByte(rest, ' ')
+	if sp <= 0 {
+		return nil, 0, 0
+	}
+	h.Host = string(rest[:sp])
+	rest = rest[sp+1:]
+
+	// The tag is the next word and ends with a colon, e.g. "sshd[123]:"
+	sp = bytes.IndexByte(rest, ' ')
+	if sp <= 1 || rest[sp-1] != ':' {
+		return nil, 0, 0
+	}
+	tag := rest[:sp-1]
+	if open := bytes.IndexByte(tag, '['); open > 0 && tag[len(tag)-1] == ']' {
+		h.PID = string(tag[open+1 : len(tag)-1])
+		tag = tag[:open]
+	}
+	h.App = string(tag)
+	return &h, priEnd, len(line) - len(rest) + sp + 1
+}
+
+// parseRFC5424Header parses the fields after "<PRI>1 " and returns the header
+// and the length of what it consumed, up to the message.
+func parseRFC5424Header(rest []byte) (*SyslogHeader, int) {
+	h := &SyslogHeader{Version: 1}
+	n := 0
+	fields := []*string{&h.Timestamp, &h.Host, &h.App, &h.PID, &h.MsgID}
+	for _, field := range fields {
+		sp := bytes.IndexByte(rest[n:], ' ')
+		if sp <= 0 {
+			return nil, 0
+		}
+		if value := string(rest[n : n+sp]); value != "-" {
+			*field = value
+		}
+		n += sp + 1
+	}
+
+	sd := structuredDataLen(rest[n:])
+	if sd == 0 {
+		return nil, 0
+	}
+	n += sd
+	if n < len(rest) && rest[n] == ' ' {
+		n++
+	}
+	// The message may start with a UTF-8 BOM
+	if bytes.HasPrefix(rest[n:], []byte("\xef\xbb\xbf")) {
+		n += 3
+	}
+	return h, n
+}
+
+// structuredDataLen returns the length of the RFC 5424 STRUCTURED-DATA at the
+// start of b, either "-" or one or more "[id param=\"value\"]" elements, or 0
+// if it is malformed.
+func structuredDataLen(b []byte) int {
+	if len(b) > 0 && b[0] == '-' {
+		return 1
+	}
+	n := 0
+	for n < len(b) && b[n] == '[' {
+		inQuote := false
+		i := n + 1
+		for ; i < len(b); i++ {
+			c := b[i]
+			if inQuote && c == '\\' {
+				i++
+			} else if c == '"' {
+				inQuote = !inQuote
+			} else if c == ']' && !inQuote {
+				break
+			}
+		}
+		if i >= len(b) {
+			return 0
+		}
+		n = i + 1
+	}
+	return n
+}
+
+func isSyslogMonth(b []byte) bool {
+	for _, m := range syslogMonths {
+		if string(b) == m {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package detectors
+
+import "testing"
+
+func TestStripSyslogHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   string
+		header *SyslogHeader
+	}{
+		{
+			name:   "RFC 3164 with pid",
+			line:   "Oct 11 22:14:15 web1 sshd[1234]: Failed password for root",
+			want:   "Failed password for root",
+			header: &SyslogHeader{Timestamp: "Oct 11 22:14:15", Host: "web1", App: "sshd", PID: "1234"},
+		},
+		{
+			name:   "Space padded day without pid",
+			line:   "Oct  1 02:04:05 db1 kernel: [123.456] Out of memory",
+			want:   "[123.456] Out of memory",
+			header: &SyslogHeader{Timestamp: "Oct  1 02:04:05", Host: "db1", App: "kernel"},
+		},
+		{
+			name:   "Priority is kept",
+			line:   "<34>Oct 11 22:14:15 web1 su: 'su root' failed",
+			want:   "<34>'su root' failed",
+			header: &SyslogHeader{Timestamp: "Oct 11 22:14:15", Host: "web1", App: "su"},
+		},
+		{
+			name:   "RFC 3339 timestamp",
+			line:   "2026-10-11T22:14:15.123456+00:00 web1 app[7]: error: disk full",
+			want:   "error: disk full",
+			header: &SyslogHeader{Timestamp: "2026-10-11T22:14:15.123456+00:00", Host: "web1", App: "app", PID: "7"},
+		},
+		{
+			name:   "RFC 5424",
+			line:   "<165>1 2026-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"App]lication\"] An application event",
+			want:   "<165>An application event",
+			header: &SyslogHeader{Version: 1, Timestamp: "2026-10-11T22:14:15.003Z", Host: "mymachine.example.com", App: "evntslog", PID: "1234", MsgID: "ID47"},
+		},
+		{
+			name:   "RFC 5424 nil values and BOM",
+			line:   "<34>1 2026-10-11T22:14:15Z web1 su - - - \xef\xbb\xbf'su root' failed",
+			want:   "<34>'su root' failed",
+			header: &SyslogHeader{Version: 1, Timestamp: "2026-10-11T22:14:15Z", Host: "web1", App: "su"},
+		},
+		{
+			name: "RFC 5424 unterminated structured data",
+			line: "<34>1 2026-10-11T22:14:15Z web1 su - - [origin ip=\"1.2.3.4\" failed",
+			want: "<34>1 2026-10-11T22:14:15Z web1 su - - [origin ip=\"1.2.3.4\" failed",
+		},
+		{
+			name: "No header",
+			line: "error: disk full",
+			want: "error: disk full",
+		},
+		{
+			name: "No tag",
+			line: "Oct 11 22:14:15 web1 something failed",
+			want: "Oct 11 22:14:15 web1 something failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, header := StripSyslogHeader([]byte(tt.line))
+			if string(got) != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+			if (header == nil) != (tt.header == nil) {
+				t.Fatalf("header = %+v, want %+v", header, tt.header)
+			}
+			if header != nil && *header != *tt.header {
+				t.Errorf("header = %+v, want %+v", *header, *tt.header)
+			}
+		})
+	}
+}
+
+func TestParseSyslogHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		header *SyslogHeader
+	}{
+		{
+			name:   "RFC 3164",
+			line:   "Oct 11 22:14:15 web1 sshd[1234]: Failed password for root",
+			header: &SyslogHeader{Timestamp: "Oct 11 22:14:15", Host: "web1", App: "sshd", PID: "1234"},
+		},
+		{
+			name:   "RFC 3339 with priority",
+			line:   "<34>2026-10-11T22:14:15Z web1 app: error",
+			header: &SyslogHeader{Timestamp: "2026-10-11T22:14:15Z", Host: "web1", App: "app"},
+		},
+		{
+			name:   "RFC 5424",
+			line:   "<34>1 2026-10-11T22:14:15Z web1 app 42 - - error",
+			header: &SyslogHeader{Version: 1, Timestamp: "2026-10-11T22:14:15Z", Host: "web1", App: "app", PID: "42"},
+		},
+		{
+			// Common in application logs, so only StripSyslogHeader takes it
+			name: "RFC 3339 without priority",
+			line: "2026-10-11T22:14:15Z worker job: failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := ParseSyslogHeader([]byte(tt.line))
+			if (header == nil) != (tt.header == nil) {
+				t.Fatalf("header = %+v, want %+v", header, tt.header)
+			}
+			if header != nil && *header != *tt.header {
+				t.Errorf("header = %+v, want %+v", *header, *tt.header)
+			}
+		})
+	}
+}
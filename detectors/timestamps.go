@@ -506,3 +506,49 @@ func ParseNginxAccess(line []byte) (float64, string, bool) {
 
 	return 0, "", false
 }
+
+// ExtractTimestamp finds a timestamp in the line in any of the supported
+// formats (dmesg, ISO8601/RFC3339, Nginx error, syslog, Nginx access).
+// It returns the unix time (seconds since boot for dmesg) and the matched text,
+// or 0 and "" if none is found.
+func ExtractTimestamp(line []byte) (float64, string) {
+	if len(line) == 0 {
+		return 0, ""
+	}
+
+	// 1. Try dmesg format first (fastest/most common for this tool initially)
+	// Check if it starts with '['
+	if line[0] == '[' {
+		if ts, tsStr, ok := ParseDmesgTimestamp(line); ok {
+			return ts, tsStr
+		}
+	}
+
+	// 2. Try ISO8601/RFC3339 or Nginx
+	// Starts with digit
+	if line[0] >= '0' && line[0] <= '9' {
+		if ts, tsStr, ok := ParseISO8601(line); ok {
+			return ts, tsStr
+		}
+
+		if ts, tsStr, ok := ParseNginxError(line); ok {
+			return ts, tsStr
+		}
+	}
+
+	// 3. Try Syslog (Oct 27 10:00:00)
+	// Starts with '<' or uppercase letter
+	if line[0] == '<' || (line[0] >= 'A' && line[0] <= 'Z') {
+		if ts, tsStr, ok := ParseSyslogTimestamp(line); ok {
+			return ts, tsStr
+		}
+	}
+
+	// 4. Try Nginx Access ([27/Oct/2023:10:00:00 +0000])
+	// This handles IPv6 access logs starting with '[' or other custom formats.
+	if ts, tsStr, ok := ParseNginxAccess(line); ok {
+		return ts, tsStr
+	}
+
+	return 0, ""
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/ipc"
+	"gopkg.in/yaml.v3"
+)
+
+// diffInstances prints how the configuration in path differs from the one
+// each running instance in socketDir uses, i.e. what reloading it would change.
+// Both sides are redacted, so DSNs are only compared as set or unset.
+func diffInstances(socketDir, path string, w io.Writer) error {
+	proposed, err := config.LoadFiles(path)
+	if err != nil {
+		return err
+	}
+	instances, err := ipc.ListInstances(socketDir)
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		fmt.Fprintln(w, "No running instances found.")
+		return nil
+	}
+	for _, inst := range instances {
+		changes, err := diffConfigs(inst.Config, proposed.Redacted())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "PID %d:\n", inst.PID)
+		if len(changes) == 0 {
+			fmt.Fprintln(w, "  no changes")
+		}
+		for _, c := range changes {
+			fmt.Fprintf(w, "  %s\n", c)
+		}
+	}
+	return nil
+}
+
+// diffConfigs lists the differences from running to proposed, by their YAML
+// keys: changed settings first ("~ sentry.environment: ..."), then removed
+// ("- monitor name"), added ("+ monitor name") and changed monitors, which
+// are matched by name.
+func diffConfigs(running, proposed *config.Config) ([]string, error) {
+	if running == nil {
+		running = &config.Config{}
+	}
+	oldSettings, err := flattenYAML(settingsOnly(running))
+	if err != nil {
+		return nil, err
+	}
+	newSettings, err := flattenYAML(settingsOnly(proposed))
+	if err != nil {
+		return nil, err
+	}
+	changes := diffFlat("", oldSettings, newSettings)
+
+	oldMonitors := monitorsByName(running.Monitors)
+	newMonitors := monitorsByName(proposed.Monitors)
+	for _, name := range sortedKeys(oldMonitors) {
+		if _, ok := newMonitors[name]; !ok {
+			changes = append(changes, "- monitor "+name)
+		}
+	}
+	for _, name := range sortedKeys(newMonitors) {
+		old, ok := oldMonitors[name]
+		if !ok {
+			changes = append(changes, "+ monitor "+name)
+			continue
+		}
+		if old.Equal(newMonitors[name]) {
+			continue
+		}
+		oldFlat, err := flattenYAML(old)
+		if err != nil {
+			return nil, err
+		}
+		newFlat, err := flattenYAML(newMonitors[name])
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, diffFlat("monitor "+name+": ", oldFlat, newFlat)...)
+	}
+	return changes, nil
+}
+
+// settingsOnly returns cfg without its monitors, which are compared by name.
+func settingsOnly(cfg *config.Config) config.Config {
+	c := *cfg
+	c.Monitors = nil
+	return c
+}
+
+func monitorsByName(monitors []config.MonitorConfig) map[string]config.MonitorConfig {
+	byName := make(map[string]config.MonitorConfig, len(monitors))
+	for i, m := range monitors {
+		name := m.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		byName[name] = m
+	}
+	return byName
+}
+
+// diffFlat lists the keys whose values differ between two flattened configs.
+func diffFlat(prefix string, old, new map[string]string) []string {
+	keys := make(map[string]bool)
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range new {
+		keys[k] = true
+	}
+	var changes []string
+	for _, k := range sortedKeys(keys) {
+		o, n := old[k], new[k]
+		if o == n {
+			continue
+		}
+		if o == "" {
+			o = "(unset)"
+		}
+		if n == "" {
+			n = "(unset)"
+		}
+		changes = append(changes, fmt.Sprintf("~ %s%s: %s -> %s", prefix, k, o, n))
+	}
+	return changes
+}
+
+// flattenYAML maps the dotted YAML keys of v's settings (e.g.
+// "sentry.environment") to their JSON-encoded values. Lists are kept whole,
+// and unset (zero) values are left out.
+func flattenYAML(v interface{}) (map[string]string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	flat := make(map[string]string)
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		switch v := v.(type) {
+		case nil:
+			return
+		case map[string]interface{}:
+			for k, child := range v {
+				if prefix != "" {
+					k = prefix + "." + k
+				}
+				walk(k, child)
+			}
+			return
+		case []interface{}:
+			if len(v) == 0 {
+				return
+			}
+		case string:
+			if v == "" {
+				return
+			}
+		case int:
+			if v == 0 {
+				return
+			}
+		case float64:
+			if v == 0 {
+				return
+			}
+		case bool:
+			if !v {
+				return
+			}
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			b = []byte(fmt.Sprint(v))
+		}
+		flat[prefix] = string(b)
+	}
+	walk("", tree)
+	return flat, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/ipc"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	running := &config.Config{
+		Sentry:      config.SentryConfig{DSN: "***", Environment: "staging"},
+		MetricsPort: 9100,
+		Monitors: []config.MonitorConfig{
+			{Name: "syslog", Type: "file", Path: "/var/log/syslog"},
+			{Name: "api", Type: "file", Path: "/var/log/api.log", Pattern: "ERROR"},
+			{Name: "old", Type: "dmesg"},
+		},
+	}
+	proposed := &config.Config{
+		Sentry:      config.SentryConfig{DSN: "***", Environment: "production"},
+		MetricsPort: 9100,
+		Monitors: []config.MonitorConfig{
+			{Name: "syslog", Type: "file", Path: "/var/log/syslog"},
+			{Name: "api", Type: "file", Path: "/var/log/api.log", Pattern: "ERROR|FATAL", RateLimitBurst: 20},
+			{Name: "new", Type: "journalctl", Args: "-f"},
+		},
+	}
+
+	changes, err := diffConfigs(running, proposed)
+	if err != nil {
+		t.Fatalf("diffConfigs failed: %v", err)
+	}
+	want := []string{
+		`~ sentry.environment: "staging" -> "production"`,
+		`- monitor old`,
+		`~ monitor api: pattern: "ERROR" -> "ERROR|FATAL"`,
+		`~ monitor api: rate_limit_burst: (unset) -> 20`,
+		`+ monitor new`,
+	}
+	if strings.Join(changes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected changes:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(changes, "\n"))
+	}
+
+	if changes, _ := diffConfigs(running, running); len(changes) != 0 {
+		t.Errorf("Expected no changes for the same config, got %v", changes)
+	}
+}
+
+func TestDiffInstancesOverIPC(t *testing.T) {
+	running := &config.Config{
+		Sentry:   config.SentryConfig{DSN: "https://key@sentry.io/1"},
+		Monitors: []config.MonitorConfig{{Name: "app", Type: "file", Path: "/var/log/app.log"}},
+	}
+	socketDir := t.TempDir()
+	socketPath := ipc.SocketPath(socketDir, os.Getpid())
+	go func() {
+		_ = ipc.StartServer(socketPath, func() *config.Config { return running }, nil, nil, nil)
+	}()
+	if !waitFor(2*time.Second, func() bool {
+		_, err := os.Stat(socketPath)
+		return err == nil
+	}) {
+		t.Fatal("IPC socket was not created")
+	}
+
+	// The DSN differs, but both sides are redacted
+	path := filepath.Join(t.TempDir(), "sentrylogmon.yaml")
+	proposed := "sentry:\n  dsn: https://other@sentry.io/2\nmonitors:\n  - name: app\n    type: file\n    path: /var/log/app.log\n  - name: web\n    type: file\n    path: /var/log/web.log\n"
+	if err := os.WriteFile(path, []byte(proposed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := diffInstances(socketDir, path, &out); err != nil {
+		t.Fatalf("diffInstances failed: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "+ monitor web") || strings.Contains(got, "dsn") {
+		t.Errorf("Expected only the added monitor, got:\n%s", got)
+	}
+}
@@ -0,0 +1,47 @@
+package exporters
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is an event a monitor reported, as handed to exporters.
+type Event struct {
+	Time    time.Time
+	Source  string // the monitor's source name
+	Level   string // Sentry level: debug, info, warning, error or fatal
+	Message string // may span several lines for batches
+}
+
+// Exporter sends events somewhere besides Sentry. Export is called from the
+// monitor's flush path, so it must not block for long, and may be called
+// concurrently.
+type Exporter interface {
+	Export(ev Event) error
+	Close() error
+}
+
+var (
+	// Delay before a network exporter tries its destination again after a
+	// failure, doubled on each further failure up to retryMax. Events exported
+	// meanwhile are dropped at once, so a dead destination costs at most one
+	// timeout per delay instead of one per event.
+	retryMin = 1 * time.Second
+	retryMax = 1 * time.Minute
+)
+
+// jsonEvent is the JSON form of an event, as written by FileExporter and
+// posted by WebhookExporter.
+type jsonEvent struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+func marshalEvent(ev Event) ([]byte, error) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	return json.Marshal(jsonEvent{Time: ev.Time, Source: ev.Source, Level: ev.Level, Message: ev.Message})
+}
@@ -0,0 +1,30 @@
+package exporters
+
+// FileExporter appends events to a local file as JSON lines, e.g. as an
+// audit trail of what was reported, rotating it as configured.
+type FileExporter struct {
+	w *RotatingWriter
+}
+
+// NewFileExporter opens path for appending, creating it if needed.
+func NewFileExporter(path string, opts RotateOptions) (*FileExporter, error) {
+	w, err := NewRotatingWriter(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{w: w}, nil
+}
+
+// Export appends ev as one line.
+func (e *FileExporter) Export(ev Event) error {
+	b, err := marshalEvent(ev)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(b, '\n'))
+	return err
+}
+
+func (e *FileExporter) Close() error {
+	return e.w.Close()
+}
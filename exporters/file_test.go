@@ -0,0 +1,83 @@
+package exporters
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readRecords(t *testing.T, path string) []jsonEvent {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	var recs []jsonEvent
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var r jsonEvent
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", sc.Text(), err)
+		}
+		recs = append(recs, r)
+	}
+	return recs
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	exp, err := NewFileExporter(path, RotateOptions{})
+	if err != nil {
+		t.Fatalf("NewFileExporter failed: %v", err)
+	}
+	ts := time.Date(2026, 10, 11, 22, 14, 15, 0, time.UTC)
+	if err := exp.Export(Event{Time: ts, Source: "kernel", Level: "error", Message: "EXT4-fs error\nremounting read-only"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	exp.Close()
+
+	recs := readRecords(t, path)
+	if len(recs) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(recs))
+	}
+	want := jsonEvent{Time: ts, Source: "kernel", Level: "error", Message: "EXT4-fs error\nremounting read-only"}
+	if !recs[0].Time.Equal(want.Time) || recs[0].Source != want.Source || recs[0].Level != want.Level || recs[0].Message != want.Message {
+		t.Errorf("Expected %+v, got %+v", want, recs[0])
+	}
+}
+
+func TestFileExporterRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	exp, err := NewFileExporter(path, RotateOptions{MaxBytes: 1024, Backups: 2})
+	if err != nil {
+		t.Fatalf("NewFileExporter failed: %v", err)
+	}
+	defer exp.Close()
+
+	msg := strings.Repeat("x", 100)
+	for i := 0; i < 20; i++ {
+		if err := exp.Export(Event{Source: "app", Level: "error", Message: msg}); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	}
+
+	old := readRecords(t, path+".1")
+	if len(old) == 0 {
+		t.Fatal("Expected the rotated file to keep earlier events")
+	}
+	current := readRecords(t, path)
+	if len(current) == 0 {
+		t.Fatal("Expected a new file to be started")
+	}
+	if info, _ := os.Stat(path); info.Size() > 1024 {
+		t.Errorf("Expected current file within output_max_size, got %d bytes", info.Size())
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected at most 2 rotated files, got err=%v", err)
+	}
+}
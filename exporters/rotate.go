@@ -0,0 +1,110 @@
+package exporters
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// RotateOptions configures a RotatingWriter.
+type RotateOptions struct {
+	MaxBytes int64 // rotate before the file would grow past this; 0: no size limit
+	Backups  int   // rotated files to keep; 0: truncate the file instead
+	Daily    bool  // also rotate on the first write of each (local) day
+}
+
+// now is replaced in tests to cross a day boundary.
+var now = time.Now
+
+// RotatingWriter appends to a file and rotates it once it would grow past
+// MaxBytes, or with Daily, once a write falls on a later day than the file's
+// last. Rotated files are kept as path.1 (newest) to path.<Backups> (oldest).
+type RotatingWriter struct {
+	mu   sync.Mutex
+	path string
+	opts RotateOptions
+	f    *os.File
+	size int64
+	day  time.Time // midnight of the day the file was last written
+}
+
+// NewRotatingWriter opens path for appending, creating it if needed.
+func NewRotatingWriter(path string, opts RotateOptions) (*RotatingWriter, error) {
+	w := &RotatingWriter{
+		path: path,
+		opts: opts,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f = f
+	w.size = info.Size()
+	// An existing file left from an earlier day is rotated on the first write
+	w.day = midnight(info.ModTime())
+	return nil
+}
+
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	today := midnight(now())
+	full := w.opts.MaxBytes > 0 && w.size+int64(len(p)) > w.opts.MaxBytes
+	stale := w.opts.Daily && today.After(w.day)
+	if w.size > 0 && (full || stale) {
+		if err := w.rotate(); err != nil {
+			// Keep writing to the current file rather than losing the line
+			fmt.Fprintf(os.Stderr, "Failed to rotate %s: %v\n", w.path, err)
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	w.day = today
+	return n, err
+}
+
+func (w *RotatingWriter) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	if w.opts.Backups > 0 {
+		for i := w.opts.Backups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+		}
+		if err := os.Rename(w.path, w.path+".1"); err != nil {
+			w.open()
+			return err
+		}
+	} else if err := os.Truncate(w.path, 0); err != nil {
+		w.open()
+		return err
+	}
+	return w.open()
+}
+
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Close()
+}
+
+// midnight returns the start of t's day in local time.
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
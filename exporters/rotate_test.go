@@ -0,0 +1,112 @@
+package exporters
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentrylogmon.log")
+
+	w, err := NewRotatingWriter(path, RotateOptions{MaxBytes: 100, Backups: 2})
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	defer w.Close()
+
+	logger := log.New(w, "", 0)
+	logger.Println("first line")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if string(data) != "first line\n" {
+		t.Errorf("Expected log line in file, got %q", data)
+	}
+
+	// Each line is 40 bytes, so the third write exceeds the 100 byte cap
+	line := strings.Repeat("x", 39)
+	for i := 0; i < 3; i++ {
+		logger.Println(line)
+	}
+
+	rotated, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatalf("Expected rotated file: %v", err)
+	}
+	if !strings.HasPrefix(string(rotated), "first line\n") {
+		t.Errorf("Expected rotated file to start with the first line, got %q", rotated)
+	}
+	current, _ := os.ReadFile(path)
+	if string(current) != line+"\n" {
+		t.Errorf("Expected current file to hold only the newest line, got %q", current)
+	}
+
+	// Keeps at most 2 backups
+	for i := 0; i < 10; i++ {
+		logger.Println(line)
+	}
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Errorf("Expected second backup: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected no third backup, got err=%v", err)
+	}
+	if info, _ := os.Stat(path); info.Size() > 100 {
+		t.Errorf("Expected current file within cap, got %d bytes", info.Size())
+	}
+}
+
+func TestRotatingWriterAppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentrylogmon.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewRotatingWriter(path, RotateOptions{MaxBytes: 1024, Backups: 1})
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	w.Write([]byte("new\n"))
+	w.Close()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "old\nnew\n" {
+		t.Errorf("Expected append to existing file, got %q", data)
+	}
+}
+
+func TestRotatingWriterDaily(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	day := time.Date(2026, 10, 11, 23, 59, 0, 0, time.Local)
+	now = func() time.Time { return day }
+	defer func() { now = time.Now }()
+
+	w, err := NewRotatingWriter(path, RotateOptions{Backups: 1, Daily: true})
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer w.Close()
+	w.Write([]byte("monday\n"))
+	w.Write([]byte("still monday\n"))
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Fatalf("Expected no rotation within a day, got err=%v", err)
+	}
+
+	day = day.Add(2 * time.Minute)
+	w.Write([]byte("tuesday\n"))
+
+	rotated, _ := os.ReadFile(path + ".1")
+	if string(rotated) != "monday\nstill monday\n" {
+		t.Errorf("Expected the previous day in the rotated file, got %q", rotated)
+	}
+	current, _ := os.ReadFile(path)
+	if string(current) != "tuesday\n" {
+		t.Errorf("Expected a new file for the new day, got %q", current)
+	}
+}
@@ -0,0 +1,197 @@
+package exporters
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/angch/sentrylogmon/sources"
+)
+
+// SyslogFacilities maps facility names to their codes.
+var SyslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities maps Sentry levels to syslog severities.
+var syslogSeverities = map[string]int{
+	"fatal":   2, // crit
+	"error":   3, // err
+	"warning": 4, // warning
+	"info":    6, // info
+	"debug":   7, // debug
+}
+
+// syslogTimeout bounds dialing and each write, so a dead collector doesn't
+// hold up the monitor.
+const syslogTimeout = 5 * time.Second
+
+// SyslogFormats are the message framings a SyslogExporter can use.
+var SyslogFormats = []string{"rfc3164", "rfc5424"}
+
+// SyslogOptions configures a SyslogExporter.
+type SyslogOptions struct {
+	Facility string // default: user
+	Format   string // rfc3164 (default) or rfc5424
+	Tag      string // APP-NAME; default: sentrylogmon
+}
+
+// SyslogExporter forwards events to a syslog collector over UDP or TCP, with
+// the severity taken from the event level. Messages are framed as RFC 3164
+// ("<PRI>Oct 11 22:14:15 host tag[pid]: [source] message") or RFC 5424, with
+// the source as MSGID. Newlines in batches are escaped as #012, the way
+// rsyslog does, so each event stays one syslog message. Over TCP, messages
+// end with a newline. After a failed connect or write, events are dropped
+// until the next reconnect attempt, with increasing delays between attempts.
+type SyslogExporter struct {
+	network  string
+	address  string
+	facility int
+	rfc5424  bool
+	tag      string
+	hostname string
+	pid      int
+
+	mu      sync.Mutex
+	conn    net.Conn      // dialed on the first Export and after a failed write
+	backoff time.Duration // after failures, how long to wait before dialing again
+	retryAt time.Time     // when to dial again after a failure
+}
+
+// NewSyslogExporter returns an exporter to address, given as for a syslog
+// source, e.g. "udp:127.0.0.1:514" or "tcp:[::1]:601". It connects on the
+// first Export.
+func NewSyslogExporter(address string, opts SyslogOptions) (*SyslogExporter, error) {
+	network, addr, err := sources.ParseSyslogAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	e := &SyslogExporter{
+		network:  network,
+		address:  addr,
+		facility: SyslogFacilities["user"],
+		tag:      opts.Tag,
+		pid:      os.Getpid(),
+	}
+	if opts.Facility != "" {
+		f, ok := SyslogFacilities[opts.Facility]
+		if !ok {
+			return nil, fmt.Errorf("unknown syslog facility '%s' (valid values: kern, user, mail, daemon, auth, syslog, lpr, news, uucp, cron, authpriv, ftp, local0 to local7)", opts.Facility)
+		}
+		e.facility = f
+	}
+	if opts.Format != "" && !slices.Contains(SyslogFormats, opts.Format) {
+		return nil, fmt.Errorf("unknown syslog format '%s' (valid values: %s)", opts.Format, strings.Join(SyslogFormats, ", "))
+	}
+	e.rfc5424 = opts.Format == "rfc5424"
+	if e.tag == "" {
+		e.tag = "sentrylogmon"
+	}
+	e.hostname, _ = os.Hostname()
+	return e, nil
+}
+
+// Export sends ev as one syslog message.
+func (e *SyslogExporter) Export(ev Event) error {
+	msg := e.format(ev)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		if wait := time.Until(e.retryAt); wait > 0 {
+			return fmt.Errorf("syslog %s:%s unavailable, dropped event; retrying in %v", e.network, e.address, wait.Round(time.Second))
+		}
+		conn, err := net.DialTimeout(e.network, e.address, syslogTimeout)
+		if err != nil {
+			e.failedLocked()
+			return fmt.Errorf("failed to connect to syslog %s:%s: %v", e.network, e.address, err)
+		}
+		e.conn = conn
+	}
+	_ = e.conn.SetWriteDeadline(time.Now().Add(syslogTimeout))
+	if _, err := e.conn.Write(msg); err != nil {
+		// Reconnect on a later event, e.g. after the collector restarted
+		e.conn.Close()
+		e.conn = nil
+		e.failedLocked()
+		return fmt.Errorf("failed to write to syslog %s:%s: %v", e.network, e.address, err)
+	}
+	e.backoff = 0
+	return nil
+}
+
+// failedLocked delays the next dial after a failure. e.mu must be held.
+func (e *SyslogExporter) failedLocked() {
+	e.backoff = min(max(e.backoff*2, retryMin), retryMax)
+	e.retryAt = time.Now().Add(e.backoff)
+}
+
+// format frames ev as a syslog message.
+func (e *SyslogExporter) format(ev Event) []byte {
+	severity, ok := syslogSeverities[ev.Level]
+	if !ok {
+		severity = syslogSeverities["info"]
+	}
+	pri := e.facility*8 + severity
+	t := ev.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	message := strings.ReplaceAll(strings.TrimRight(ev.Message, "\n"), "\n", "#012")
+
+	var b strings.Builder
+	if e.rfc5424 {
+		fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s - %s", pri, t.Format("2006-01-02T15:04:05.000000Z07:00"),
+			syslogField(e.hostname, 255), syslogField(e.tag, 48), e.pid, syslogField(ev.Source, 32), message)
+	} else {
+		hostname := e.hostname
+		if hostname == "" {
+			hostname = "localhost"
+		}
+		fmt.Fprintf(&b, "<%d>%s %s %s[%d]: ", pri, t.Format(time.Stamp), hostname, e.tag, e.pid)
+		if ev.Source != "" {
+			fmt.Fprintf(&b, "[%s] ", ev.Source)
+		}
+		b.WriteString(message)
+	}
+	if e.network == "tcp" {
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// syslogField returns s as an RFC 5424 header field: printable ASCII without
+// spaces, at most maxLen bytes, or "-" if empty.
+func syslogField(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (e *SyslogExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
@@ -0,0 +1,180 @@
+package exporters
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogExporterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	exp, err := NewSyslogExporter("udp:"+conn.LocalAddr().String(), SyslogOptions{Facility: "local3"})
+	if err != nil {
+		t.Fatalf("NewSyslogExporter failed: %v", err)
+	}
+	defer exp.Close()
+	if err := exp.Export(Event{
+		Time:    time.Date(2026, 10, 11, 22, 14, 15, 0, time.Local),
+		Source:  "kernel",
+		Level:   "error",
+		Message: "EXT4-fs error\nremounting read-only",
+	}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read datagram: %v", err)
+	}
+	got := string(buf[:n])
+	// local3 (19) * 8 + err (3)
+	want := "<155>Oct 11 22:14:15 " + hostname(t) + " sentrylogmon[" + strconv.Itoa(os.Getpid()) + "]: [kernel] EXT4-fs error#012remounting read-only"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestSyslogExporterTCP5424(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	exp, err := NewSyslogExporter("tcp://"+ln.Addr().String(), SyslogOptions{Format: "rfc5424", Tag: "logmon"})
+	if err != nil {
+		t.Fatalf("NewSyslogExporter failed: %v", err)
+	}
+	defer exp.Close()
+	for _, ev := range []Event{
+		{Source: "app log", Level: "warning", Message: "disk 91% full"},
+		{Source: "app log", Level: "fatal", Message: "out of disk"},
+	} {
+		if err := exp.Export(ev); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	}
+
+	// user (1) * 8 + severity; spaces in the source are not allowed in MSGID
+	for _, want := range []string{
+		`<12>1 \S+ \S+ logmon \d+ app_log - disk 91% full`,
+		`<10>1 \S+ \S+ logmon \d+ app_log - out of disk`,
+	} {
+		select {
+		case got := <-lines:
+			if !regexp.MustCompile("^" + want + "$").MatchString(got) {
+				t.Errorf("Expected a message matching %q, got %q", want, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for a message")
+		}
+	}
+}
+
+func TestSyslogExporterBackoff(t *testing.T) {
+	oldMin := retryMin
+	retryMin = 200 * time.Millisecond
+	t.Cleanup(func() { retryMin = oldMin })
+
+	// A port nothing listens on
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	exp, err := NewSyslogExporter("tcp:"+addr, SyslogOptions{})
+	if err != nil {
+		t.Fatalf("NewSyslogExporter failed: %v", err)
+	}
+	defer exp.Close()
+	ev := Event{Source: "app", Level: "error", Message: "disk full"}
+	if err := exp.Export(ev); err == nil || !strings.Contains(err.Error(), "failed to connect") {
+		t.Fatalf("Expected a connect error, got %v", err)
+	}
+
+	// Until the retry delay is over, events are dropped without dialing
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("Failed to listen on %s again: %v", addr, err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	if err := exp.Export(ev); err == nil || !strings.Contains(err.Error(), "dropped event") {
+		t.Errorf("Expected the event to be dropped during the retry delay, got %v", err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if err := exp.Export(ev); err != nil {
+		t.Fatalf("Expected the exporter to reconnect after the delay, got %v", err)
+	}
+	select {
+	case line := <-lines:
+		if !strings.HasSuffix(line, "[app] disk full") {
+			t.Errorf("Expected the event after reconnecting, got %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a message")
+	}
+}
+
+func TestNewSyslogExporterInvalid(t *testing.T) {
+	tests := []struct {
+		address string
+		opts    SyslogOptions
+		errText string
+	}{
+		{"udp:127.0.0.1", SyslogOptions{}, "invalid syslog address"},
+		{"udp:127.0.0.1:514", SyslogOptions{Facility: "local9"}, "unknown syslog facility 'local9'"},
+		{"udp:127.0.0.1:514", SyslogOptions{Format: "json"}, "unknown syslog format 'json'"},
+	}
+	for _, tt := range tests {
+		if _, err := NewSyslogExporter(tt.address, tt.opts); err == nil || !strings.Contains(err.Error(), tt.errText) {
+			t.Errorf("NewSyslogExporter(%q, %+v): expected an error containing %q, got %v", tt.address, tt.opts, tt.errText, err)
+		}
+	}
+}
+
+func hostname(t *testing.T) string {
+	h, err := os.Hostname()
+	if err != nil || h == "" {
+		return "localhost"
+	}
+	return h
+}
@@ -0,0 +1,131 @@
+package exporters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebhookCompressions are the body encodings a WebhookExporter can use,
+// besides none.
+var WebhookCompressions = []string{"gzip"}
+
+// webhookTimeout bounds each POST, so a slow endpoint doesn't hold up the
+// monitor.
+const webhookTimeout = 5 * time.Second
+
+// WebhookOptions configures a WebhookExporter.
+type WebhookOptions struct {
+	Headers  map[string]string // e.g. Authorization for a Splunk HEC token
+	Compress string            // gzip, or empty to send the body as is
+}
+
+// WebhookExporter posts each event as a JSON object to an HTTP endpoint. With
+// gzip compression the body is sent with Content-Encoding: gzip. A request
+// that fails or gets a response other than 2xx is an error, after which
+// events are dropped until the next attempt, with increasing delays between
+// attempts.
+type WebhookExporter struct {
+	url     string
+	headers map[string]string
+	gzip    bool
+	client  *http.Client
+
+	mu      sync.Mutex
+	backoff time.Duration // after failures, how long to wait before posting again
+	retryAt time.Time     // when to post again after a failure
+}
+
+// NewWebhookExporter returns an exporter posting to rawURL, an http or https
+// URL.
+func NewWebhookExporter(rawURL string, opts WebhookOptions) (*WebhookExporter, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("url must be an http or https URL, got '%s'", rawURL)
+	}
+	if opts.Compress != "" && !slices.Contains(WebhookCompressions, opts.Compress) {
+		return nil, fmt.Errorf("unknown compression '%s' (valid values: %s)", opts.Compress, strings.Join(WebhookCompressions, ", "))
+	}
+	return &WebhookExporter{
+		url:     rawURL,
+		headers: opts.Headers,
+		gzip:    opts.Compress == "gzip",
+		client:  &http.Client{Timeout: webhookTimeout},
+	}, nil
+}
+
+// Export posts ev.
+func (e *WebhookExporter) Export(ev Event) error {
+	e.mu.Lock()
+	wait := time.Until(e.retryAt)
+	e.mu.Unlock()
+	if wait > 0 {
+		return fmt.Errorf("webhook %s unavailable, dropped event; retrying in %v", e.url, wait.Round(time.Second))
+	}
+
+	body, err := marshalEvent(ev)
+	if err != nil {
+		return err
+	}
+	if e.gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		e.failed()
+		return fmt.Errorf("failed to post to webhook %s: %v", e.url, err)
+	}
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e.failed()
+		return fmt.Errorf("webhook %s returned %s", e.url, resp.Status)
+	}
+
+	e.mu.Lock()
+	e.backoff = 0
+	e.mu.Unlock()
+	return nil
+}
+
+// failed delays the next post after a failure.
+func (e *WebhookExporter) failed() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.backoff = min(max(e.backoff*2, retryMin), retryMax)
+	e.retryAt = time.Now().Add(e.backoff)
+}
+
+func (e *WebhookExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
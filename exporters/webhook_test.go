@@ -0,0 +1,106 @@
+package exporters
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// webhookServer records the Content-Encoding and decoded event of each post.
+func webhookServer(t *testing.T) (*httptest.Server, chan string, chan jsonEvent) {
+	t.Helper()
+	encodings := make(chan string, 1)
+	events := make(chan jsonEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "bad gzip", http.StatusBadRequest)
+				return
+			}
+			body = gz
+		}
+		var ev jsonEvent
+		if err := json.NewDecoder(body).Decode(&ev); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		encodings <- r.Header.Get("Content-Encoding")
+		events <- ev
+	}))
+	t.Cleanup(srv.Close)
+	return srv, encodings, events
+}
+
+func TestWebhookExporterGzip(t *testing.T) {
+	srv, encodings, events := webhookServer(t)
+
+	exp, err := NewWebhookExporter(srv.URL, WebhookOptions{Compress: "gzip"})
+	if err != nil {
+		t.Fatalf("NewWebhookExporter failed: %v", err)
+	}
+	defer exp.Close()
+	ts := time.Date(2026, 10, 11, 22, 14, 15, 0, time.UTC)
+	if err := exp.Export(Event{Time: ts, Source: "app", Level: "error", Message: "disk full"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if enc := <-encodings; enc != "gzip" {
+		t.Errorf("Expected Content-Encoding: gzip, got %q", enc)
+	}
+	ev := <-events
+	if !ev.Time.Equal(ts) || ev.Source != "app" || ev.Level != "error" || ev.Message != "disk full" {
+		t.Errorf("Unexpected event %+v", ev)
+	}
+}
+
+func TestWebhookExporterUncompressedByDefault(t *testing.T) {
+	srv, encodings, events := webhookServer(t)
+
+	exp, err := NewWebhookExporter(srv.URL, WebhookOptions{Headers: map[string]string{"Authorization": "Splunk token"}})
+	if err != nil {
+		t.Fatalf("NewWebhookExporter failed: %v", err)
+	}
+	defer exp.Close()
+	if err := exp.Export(Event{Source: "app", Level: "warning", Message: "slow request"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if enc := <-encodings; enc != "" {
+		t.Errorf("Expected no Content-Encoding, got %q", enc)
+	}
+	if ev := <-events; ev.Message != "slow request" {
+		t.Errorf("Expected the message to be posted as is, got %+v", ev)
+	}
+}
+
+func TestWebhookExporterErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	exp, err := NewWebhookExporter(srv.URL, WebhookOptions{})
+	if err != nil {
+		t.Fatalf("NewWebhookExporter failed: %v", err)
+	}
+	if err := exp.Export(Event{Message: "x"}); err == nil {
+		t.Error("Expected an error for a 503 response")
+	}
+	// Dropped without posting until the retry delay has passed
+	if err := exp.Export(Event{Message: "x"}); err == nil {
+		t.Error("Expected the event to be dropped while waiting to retry")
+	}
+
+	if _, err := NewWebhookExporter("udp:127.0.0.1:514", WebhookOptions{}); err == nil {
+		t.Error("Expected an error for a non-http URL")
+	}
+	if _, err := NewWebhookExporter(srv.URL, WebhookOptions{Compress: "zstd"}); err == nil {
+		t.Error("Expected an error for an unknown compression")
+	}
+}
@@ -0,0 +1,82 @@
+package fingerprint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Names lists the normalizers in the order they are applied, whatever order
+// they are configured in: UUIDs and hex IDs are masked before numbers, which
+// would otherwise break them up.
+var Names = []string{"uuids", "hex", "numbers", "whitespace"}
+
+var (
+	uuidPattern = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	// 0x-prefixed values, or words of hex digits with a digit in them
+	hexPattern = regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b|\b[a-f]*[0-9][0-9a-f]*\b`)
+	numberRun  = regexp.MustCompile(`[0-9]+`)
+	spaceRun   = regexp.MustCompile(`\s+`)
+)
+
+// Unprefixed hex IDs need this many digits and a letter among them, so short
+// names such as "sda1" or "ext4" and plain numbers are not taken for IDs.
+const hexMinDigits = 8
+
+var normalizers = map[string]func(string) string{
+	"uuids": func(s string) string { return uuidPattern.ReplaceAllString(s, "<uuid>") },
+	"hex": func(s string) string {
+		return hexPattern.ReplaceAllStringFunc(s, func(m string) string {
+			if strings.HasPrefix(strings.ToLower(m), "0x") {
+				return "<hex>"
+			}
+			if len(m) < hexMinDigits || !strings.ContainsAny(strings.ToLower(m), "abcdef") {
+				return m
+			}
+			return "<hex>"
+		})
+	},
+	"numbers":    func(s string) string { return numberRun.ReplaceAllString(s, "#") },
+	"whitespace": func(s string) string { return strings.TrimSpace(spaceRun.ReplaceAllString(s, " ")) },
+}
+
+// Normalizer rewrites messages so that ones differing only in IDs, numbers
+// or spacing fingerprint the same, e.g. with numbers, "worker 12 exited
+// after 300ms" and "worker 7 exited after 45ms" both become "worker # exited
+// after #ms".
+type Normalizer struct {
+	steps []func(string) string
+}
+
+// New returns a Normalizer applying the named normalizers (see Names). An
+// empty list returns nil, which leaves messages unchanged.
+func New(names []string) (*Normalizer, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	for _, name := range names {
+		if _, ok := normalizers[name]; !ok {
+			return nil, fmt.Errorf("unknown fingerprint normalizer '%s' (valid values: %s)", name, strings.Join(Names, ", "))
+		}
+	}
+	n := &Normalizer{}
+	for _, name := range Names {
+		if slices.Contains(names, name) {
+			n.steps = append(n.steps, normalizers[name])
+		}
+	}
+	return n, nil
+}
+
+// Normalize returns s with the normalizers applied. A nil Normalizer returns
+// s unchanged.
+func (n *Normalizer) Normalize(s string) string {
+	if n == nil {
+		return s
+	}
+	for _, step := range n.steps {
+		s = step(s)
+	}
+	return s
+}
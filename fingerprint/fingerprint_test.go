@@ -0,0 +1,49 @@
+package fingerprint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name        string
+		normalizers []string
+		in          string
+		want        string
+	}{
+		{"Numbers", []string{"numbers"}, "worker 12 exited after 300ms", "worker # exited after #ms"},
+		{"Whitespace", []string{"whitespace"}, "  disk\tfull   on  /var ", "disk full on /var"},
+		{"UUIDs", []string{"uuids"}, "request 3f2b8c1e-9d4a-4e6b-8f0c-1a2b3c4d5e6f failed", "request <uuid> failed"},
+		{"Hex", []string{"hex"}, "commit 9fceb02d0ae5 at 0x7ffd5e8 on sda1 in 2026", "commit <hex> at <hex> on sda1 in 2026"},
+		{"Hex Keeps Words", []string{"hex"}, "accepted deadbeef", "accepted deadbeef"},
+		// UUIDs are masked before numbers whatever the configured order
+		{"Order", []string{"numbers", "uuids"}, "job 42 id 3f2b8c1e-9d4a-4e6b-8f0c-1a2b3c4d5e6f", "job # id <uuid>"},
+		{"All", []string{"whitespace", "numbers", "uuids", "hex"}, "conn  0xdeadbeef   closed after 17 retries", "conn <hex> closed after # retries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New(tt.normalizers)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			if got := n.Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, expected %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	n, err := New(nil)
+	if err != nil || n != nil {
+		t.Fatalf("Expected no normalizer for an empty list, got %v, %v", n, err)
+	}
+	if got := n.Normalize("port 80"); got != "port 80" {
+		t.Errorf("Expected a nil normalizer to leave messages unchanged, got %q", got)
+	}
+
+	if _, err := New([]string{"numbers", "digits"}); err == nil || !strings.Contains(err.Error(), "unknown fingerprint normalizer 'digits'") {
+		t.Errorf("Expected an unknown normalizer error, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/angch/sentrylogmon/monitor"
+	"github.com/getsentry/sentry-go"
+)
+
+// deliveryCounter wraps an http.RoundTripper and counts the requests Sentry
+// accepted, so a oneshot run can report how many events were delivered.
+type deliveryCounter struct {
+	base http.RoundTripper
+
+	confirmed atomic.Int64
+}
+
+func (d *deliveryCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := d.base.RoundTrip(req)
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		d.confirmed.Add(1)
+	}
+	return resp, err
+}
+
+// exitFlushTimeout returns how long to wait on exit for queued events. A
+// oneshot run waits longer by default since delivering its events is the point.
+func exitFlushTimeout(oneShot bool) time.Duration {
+	if *flushTimeout > 0 {
+		return *flushTimeout
+	}
+	if oneShot {
+		return 30 * time.Second
+	}
+	return 2 * time.Second
+}
+
+// flushAll waits up to timeout, shared across all hubs, for events queued on
+// the monitors' own hubs and the global hub to be sent. It reports whether
+// every queue drained.
+func flushAll(monitors []*monitor.Monitor, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	flushed := true
+	for _, m := range monitors {
+		if !m.Flush(time.Until(deadline)) {
+			flushed = false
+		}
+	}
+	if !sentry.Flush(time.Until(deadline)) {
+		flushed = false
+	}
+	return flushed
+}
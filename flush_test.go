@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/monitor"
+	"github.com/getsentry/sentry-go"
+)
+
+// slowTransport delays every request, like a distant or overloaded Sentry.
+type slowTransport struct {
+	base  http.RoundTripper
+	delay time.Duration
+}
+
+func (s slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	time.Sleep(s.delay)
+	return s.base.RoundTrip(req)
+}
+
+func TestFlushAllWaitsForSlowSentry(t *testing.T) {
+	srv, received := newMockSentry(t, http.StatusOK)
+	delivered := &deliveryCounter{base: slowTransport{base: http.DefaultTransport, delay: 400 * time.Millisecond}}
+
+	prev := sentry.CurrentHub().Client()
+	t.Cleanup(func() { sentry.CurrentHub().BindClient(prev) })
+	if err := sentry.Init(sentry.ClientOptions{Dsn: mockDSN(srv), HTTPTransport: delivered}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Lines 10s apart are reported as separate events.
+	input := `[100.0] error one
+[110.0] error two
+[120.0] error three
+[130.0] error four
+[140.0] error five
+[150.0] error six
+`
+	det, err := detectors.NewGenericDetector("error")
+	if err != nil {
+		t.Fatalf("NewGenericDetector failed: %v", err)
+	}
+	mon, err := monitor.New(context.Background(), &stringSource{content: input}, det, nil, monitor.Options{
+		SentryDSN:     mockDSN(srv),
+		HTTPTransport: delivered,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.CaptureMessage("global event")
+
+	// 7 events at 400ms each take longer than the old fixed 2s flush.
+	if !flushAll([]*monitor.Monitor{mon}, 10*time.Second) {
+		t.Fatal("Expected flushAll to drain all queues")
+	}
+	if got := delivered.confirmed.Load(); got != 7 {
+		t.Errorf("Expected 7 confirmed deliveries, got %d", got)
+	}
+	if got := len(received()); got != 7 {
+		t.Errorf("Expected mock Sentry to receive 7 events, got %d", got)
+	}
+}
+
+func TestFlushAllReportsTimeout(t *testing.T) {
+	srv, _ := newMockSentry(t, http.StatusOK)
+	delivered := &deliveryCounter{base: slowTransport{base: http.DefaultTransport, delay: time.Second}}
+
+	prev := sentry.CurrentHub().Client()
+	t.Cleanup(func() { sentry.CurrentHub().BindClient(prev) })
+	if err := sentry.Init(sentry.ClientOptions{Dsn: mockDSN(srv), HTTPTransport: delivered}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+	sentry.CaptureMessage("slow event")
+
+	if flushAll(nil, 100*time.Millisecond) {
+		t.Error("Expected flushAll to report a timeout")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/angch/sentrylogmon/monitor"
+)
+
+type monitorHealth struct {
+	Source               string    `json:"source"`
+	LastActivity         time.Time `json:"last_activity"`
+	SecondsSinceActivity float64   `json:"seconds_since_activity"`
+	Healthy              bool      `json:"healthy"`
+	Error                string    `json:"error,omitempty"`
+}
+
+type healthResponse struct {
+	Status   string          `json:"status"`
+	Monitors []monitorHealth `json:"monitors"`
+}
+
+// healthHandler serves /healthz. It responds 503 when every source is failing
+// to start, or when staleness is set and no monitor has read a line within it.
+func healthHandler(healthFunc func() []monitor.Health, staleness time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		resp := healthResponse{Monitors: []monitorHealth{}}
+
+		anyFresh := staleness <= 0
+		allFailing := true
+		for _, h := range healthFunc() {
+			since := now.Sub(h.LastActivity)
+			fresh := staleness <= 0 || since <= staleness
+			mh := monitorHealth{
+				Source:               h.Source,
+				LastActivity:         h.LastActivity,
+				SecondsSinceActivity: since.Seconds(),
+				Healthy:              fresh && h.Err == nil,
+			}
+			if h.Err != nil {
+				mh.Error = h.Err.Error()
+			} else {
+				allFailing = false
+			}
+			if fresh {
+				anyFresh = true
+			}
+			resp.Monitors = append(resp.Monitors, mh)
+		}
+
+		status := http.StatusOK
+		resp.Status = "ok"
+		if len(resp.Monitors) > 0 && (allFailing || !anyFresh) {
+			status = http.StatusServiceUnavailable
+			resp.Status = "unhealthy"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
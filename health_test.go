@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/monitor"
+)
+
+func TestHealthHandler(t *testing.T) {
+	now := time.Now()
+	failing := errors.New("exec: \"journalctl\": executable file not found in $PATH")
+
+	tests := []struct {
+		name           string
+		health         []monitor.Health
+		staleness      time.Duration
+		expectedStatus int
+	}{
+		{
+			name: "Healthy",
+			health: []monitor.Health{
+				{Source: "app", LastActivity: now.Add(-time.Second)},
+			},
+			staleness:      time.Minute,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "All Sources Failing",
+			health: []monitor.Health{
+				{Source: "journal", LastActivity: now, Err: failing},
+				{Source: "dmesg", LastActivity: now, Err: failing},
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "One Source Failing",
+			health: []monitor.Health{
+				{Source: "journal", LastActivity: now, Err: failing},
+				{Source: "app", LastActivity: now},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "All Stale",
+			health: []monitor.Health{
+				{Source: "app", LastActivity: now.Add(-time.Hour)},
+			},
+			staleness:      10 * time.Minute,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "Stale Without Staleness Configured",
+			health: []monitor.Health{
+				{Source: "app", LastActivity: now.Add(-time.Hour)},
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := healthHandler(func() []monitor.Health { return tt.health }, tt.staleness)
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			var resp healthResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Monitors) != len(tt.health) {
+				t.Fatalf("Expected %d monitors in response, got %d", len(tt.health), len(resp.Monitors))
+			}
+			for i, h := range tt.health {
+				if h.Err != nil && resp.Monitors[i].Error != h.Err.Error() {
+					t.Errorf("Expected error %q for %s, got %q", h.Err, h.Source, resp.Monitors[i].Error)
+				}
+				if h.Err != nil && resp.Monitors[i].Healthy {
+					t.Errorf("Expected %s to be reported unhealthy", h.Source)
+				}
+			}
+		})
+	}
+}
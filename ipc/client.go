@@ -53,6 +53,26 @@ func ListInstances(socketDir string) ([]StatusResponse, error) {
 	return instances, nil
 }
 
+// RecentEvents returns the events the instance at socketPath sent to Sentry
+// after the one numbered after (0 for all it keeps), oldest first.
+func RecentEvents(socketPath string, after uint64) ([]RecentEvent, error) {
+	client := newUnixClient(socketPath)
+	resp, err := client.Get(fmt.Sprintf("http://unix/events/recent?after=%d", after))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status: %s", resp.Status)
+	}
+	var recent RecentEventsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&recent); err != nil {
+		return nil, err
+	}
+	return recent.Events, nil
+}
+
 func RequestUpdate(socketPath string) error {
 	client := newUnixClient(socketPath)
 	resp, err := client.Post("http://unix/update", "application/json", nil)
@@ -1,11 +1,15 @@
 package ipc
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
 )
 
 func TestGetSocketDir(t *testing.T) {
@@ -102,3 +106,79 @@ func TestEnsureSecureDirectory(t *testing.T) {
 		}
 	}
 }
+
+func TestStatsEndpoint(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "sentrylogmon.sock")
+
+	statsFunc := func() []MonitorStats {
+		return []MonitorStats{{Source: "app", LinesPerSec: 120.5, MatchesPerSec: 2}}
+	}
+	go func() {
+		_ = StartServer(socketPath, func() *config.Config { return &config.Config{} }, nil, statsFunc, nil)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(socketPath); err == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	resp, err := newUnixClient(socketPath).Get("http://unix/stats")
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var stats StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if stats.PID != os.Getpid() {
+		t.Errorf("Expected PID %d, got %d", os.Getpid(), stats.PID)
+	}
+	if len(stats.Monitors) != 1 || stats.Monitors[0].Source != "app" || stats.Monitors[0].LinesPerSec != 120.5 {
+		t.Errorf("Unexpected monitor stats: %+v", stats.Monitors)
+	}
+}
+
+func TestSocketDirOverride(t *testing.T) {
+	envDir := filepath.Join(t.TempDir(), "env")
+	t.Setenv(SocketDirEnv, envDir)
+	if got := GetSocketDir(); got != envDir {
+		t.Errorf("GetSocketDir() = %s, expected %s from %s", got, envDir, SocketDirEnv)
+	}
+
+	flagDir := filepath.Join(t.TempDir(), "flag")
+	SetSocketDir(flagDir)
+	defer SetSocketDir("")
+	if got := GetSocketDir(); got != flagDir {
+		t.Errorf("GetSocketDir() = %s, expected %s from SetSocketDir", got, flagDir)
+	}
+
+	// Round-trip status through the overridden directory
+	if err := EnsureSecureDirectory(GetSocketDir()); err != nil {
+		t.Fatalf("EnsureSecureDirectory failed: %v", err)
+	}
+	socketPath := SocketPath(GetSocketDir(), os.Getpid())
+	go func() {
+		_ = StartServer(socketPath, func() *config.Config { return &config.Config{} }, nil, nil, nil)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(socketPath); err == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	instances, err := ListInstances(GetSocketDir())
+	if err != nil {
+		t.Fatalf("ListInstances failed: %v", err)
+	}
+	if len(instances) != 1 || instances[0].PID != os.Getpid() {
+		t.Errorf("Expected this process in %s, got %+v", flagDir, instances)
+	}
+}
@@ -38,7 +38,7 @@ func TestStatusRedaction(t *testing.T) {
 	// We need to run this in a goroutine as it blocks
 	go func() {
 		// StartServer blocks until error or close
-		_ = StartServer(socketPath, cfg, nil)
+		_ = StartServer(socketPath, func() *config.Config { return cfg }, nil, nil, nil)
 	}()
 
 	// Wait for socket to appear
@@ -2,17 +2,30 @@ package ipc
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/angch/sentrylogmon/config"
 )
 
-func StartServer(socketPath string, cfg *config.Config, restartFunc func()) error {
+// ErrSocketRemoved is returned by StartServer when its socket file is deleted
+// or replaced (e.g. by a temp directory cleaner), which leaves it unreachable.
+var ErrSocketRemoved = errors.New("IPC socket file was removed")
+
+// SocketCheckInterval is how often StartServer checks that its socket file is still there.
+var SocketCheckInterval = 10 * time.Second
+
+// StartServer serves the IPC API on socketPath until it fails.
+// configFunc returns the running configuration, which changes when a reload
+// is applied in place. statsFunc, if set, reports per-monitor throughput for /stats,
+// and recentFunc the last events sent, oldest first, for /events/recent.
+func StartServer(socketPath string, configFunc func() *config.Config, restartFunc func(), statsFunc func() []MonitorStats, recentFunc func() []RecentEvent) error {
 	// Ensure socket file is removed before listening, in case of crash/restart
 	os.Remove(socketPath)
 
@@ -26,6 +39,11 @@ func StartServer(socketPath string, cfg *config.Config, restartFunc func()) erro
 		listener.Close()
 		return err
 	}
+	socketInfo, err := os.Stat(socketPath)
+	if err != nil {
+		listener.Close()
+		return err
+	}
 
 	mux := http.NewServeMux()
 
@@ -40,6 +58,7 @@ func StartServer(socketPath string, cfg *config.Config, restartFunc func()) erro
 		var m runtime.MemStats
 		runtime.ReadMemStats(&m)
 
+		cfg := configFunc()
 		status := StatusResponse{
 			PID:         os.Getpid(),
 			StartTime:   startTime,
@@ -52,6 +71,55 @@ func StartServer(socketPath string, cfg *config.Config, restartFunc func()) erro
 		json.NewEncoder(w).Encode(status)
 	})
 
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		stats := StatsResponse{
+			PID:      os.Getpid(),
+			Monitors: []MonitorStats{},
+		}
+		if statsFunc != nil {
+			stats.Monitors = statsFunc()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+	})
+
+	// ?after=<seq> returns only the events after one the client has already seen
+	mux.HandleFunc("/events/recent", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var after uint64
+		if s := r.URL.Query().Get("after"); s != "" {
+			var err error
+			if after, err = strconv.ParseUint(s, 10, 64); err != nil {
+				http.Error(w, "Invalid after", http.StatusBadRequest)
+				return
+			}
+		}
+
+		recent := RecentEventsResponse{
+			PID:    os.Getpid(),
+			Events: []RecentEvent{},
+		}
+		if recentFunc != nil {
+			for _, ev := range recentFunc() {
+				if ev.Seq > after {
+					recent.Events = append(recent.Events, ev)
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(recent)
+	})
+
 	mux.HandleFunc("/update", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -75,9 +143,36 @@ func StartServer(socketPath string, cfg *config.Config, restartFunc func()) erro
 		Handler: mux,
 	}
 
-	if cfg.Verbose {
+	if configFunc().Verbose {
 		log.Printf("IPC Server listening on %s", socketPath)
 	}
 
-	return server.Serve(listener)
+	// Without its socket file nobody can connect, so stop serving and let the caller start again
+	done := make(chan struct{})
+	defer close(done)
+	removed := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(SocketCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if info, err := os.Stat(socketPath); err != nil || !os.SameFile(info, socketInfo) {
+					close(removed)
+					server.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	err = server.Serve(listener)
+	select {
+	case <-removed:
+		return ErrSocketRemoved
+	default:
+		return err
+	}
 }
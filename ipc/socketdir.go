@@ -0,0 +1,35 @@
+package ipc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SocketDirEnv overrides the socket directory when --socket-dir is not given.
+const SocketDirEnv = "SENTRYLOGMON_SOCKET_DIR"
+
+var socketDirOverride string
+
+// SetSocketDir makes GetSocketDir return dir. An empty dir restores the default.
+func SetSocketDir(dir string) {
+	socketDirOverride = dir
+}
+
+// GetSocketDir returns the directory holding the IPC sockets: the directory
+// set with SetSocketDir, else $SENTRYLOGMON_SOCKET_DIR, else a per-user
+// directory under the temp directory.
+func GetSocketDir() string {
+	if socketDirOverride != "" {
+		return socketDirOverride
+	}
+	if dir := os.Getenv(SocketDirEnv); dir != "" {
+		return dir
+	}
+	return defaultSocketDir()
+}
+
+// SocketPath returns the socket path of the instance with the given PID in socketDir.
+func SocketPath(socketDir string, pid int) string {
+	return filepath.Join(socketDir, fmt.Sprintf("sentrylogmon.%d.sock", pid))
+}
@@ -14,6 +14,33 @@ type StatusResponse struct {
 	Config      *config.Config `json:"config"`
 }
 
+// MonitorStats is the moving average throughput of one monitor.
+type MonitorStats struct {
+	Source        string  `json:"source"`
+	LinesPerSec   float64 `json:"lines_per_sec"`
+	MatchesPerSec float64 `json:"matches_per_sec"`
+}
+
+type StatsResponse struct {
+	PID      int            `json:"pid"`
+	Monitors []MonitorStats `json:"monitors"`
+}
+
+// RecentEvent summarizes an event an instance sent to Sentry.
+type RecentEvent struct {
+	Seq     uint64    `json:"seq"` // increases with each event of the instance
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Lines   int       `json:"lines"`
+}
+
+type RecentEventsResponse struct {
+	PID    int           `json:"pid"`
+	Events []RecentEvent `json:"events"`
+}
+
 type UpdateRequest struct {
 	Action string `json:"action"` // "restart"
 }
@@ -59,7 +59,7 @@ func EnsureSecureDirectory(path string) error {
 	return nil
 }
 
-// GetSocketDir returns the secure socket directory for the current user.
-func GetSocketDir() string {
+// defaultSocketDir returns the per-user socket directory under the temp directory.
+func defaultSocketDir() string {
 	return filepath.Join(os.TempDir(), fmt.Sprintf("sentrylogmon-%d", os.Getuid()))
 }
@@ -13,7 +13,7 @@ func EnsureSecureDirectory(path string) error {
 	return os.MkdirAll(path, 0700)
 }
 
-// GetSocketDir returns the secure socket directory.
-func GetSocketDir() string {
+// defaultSocketDir returns the socket directory under the temp directory.
+func defaultSocketDir() string {
 	return filepath.Join(os.TempDir(), "sentrylogmon")
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/getsentry/sentry-go"
+)
+
+// sendLifecycleEvent sends an info event recording that sentrylogmon started
+// or stopped (action), so deployments can be audited from Sentry. sources are
+// the names of the running monitors.
+func sendLifecycleEvent(hub *sentry.Hub, action string, cfg *config.Config, sources []string) {
+	host, _ := os.Hostname()
+	version := cfg.Sentry.Release
+	if version == "" {
+		version = "unknown"
+	}
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("alert_type", "lifecycle")
+		scope.SetTag("lifecycle", action)
+		scope.SetLevel(sentry.LevelInfo)
+		scope.SetContext("Sentrylogmon", map[string]interface{}{
+			"host":     host,
+			"pid":      os.Getpid(),
+			"version":  version,
+			"monitors": sources,
+		})
+		hub.CaptureMessage(fmt.Sprintf("sentrylogmon %s, %d monitors, version %s", action, len(sources), version))
+	})
+}
+
+// monitorSources returns the source names of the running monitors.
+func monitorSources(monitors *monitorSet) []string {
+	running := monitors.all()
+	sources := make([]string, 0, len(running))
+	for _, m := range running {
+		sources = append(sources, m.Source.Name())
+	}
+	return sources
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/getsentry/sentry-go"
+)
+
+// recordingTransport captures Sentry events instead of sending them.
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Configure(options sentry.ClientOptions) {}
+func (t *recordingTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+func (t *recordingTransport) Flush(timeout time.Duration) bool          { return true }
+func (t *recordingTransport) FlushWithContext(ctx context.Context) bool { return true }
+func (t *recordingTransport) Close()                                    {}
+
+func TestSendLifecycleEvent(t *testing.T) {
+	transport := &recordingTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	cfg := &config.Config{Sentry: config.SentryConfig{Release: "v1.2.3"}}
+	sendLifecycleEvent(hub, "started", cfg, []string{"/var/log/syslog", "dmesg"})
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	event := transport.events[0]
+	if !strings.Contains(event.Message, "started, 2 monitors, version v1.2.3") {
+		t.Errorf("Unexpected message: %q", event.Message)
+	}
+	if event.Level != sentry.LevelInfo {
+		t.Errorf("Expected info level, got %q", event.Level)
+	}
+	if event.Tags["alert_type"] != "lifecycle" || event.Tags["lifecycle"] != "started" {
+		t.Errorf("Unexpected tags: %v", event.Tags)
+	}
+	ctx := event.Contexts["Sentrylogmon"]
+	if monitors, ok := ctx["monitors"].([]string); !ok || len(monitors) != 2 {
+		t.Errorf("Expected the monitor list in context, got %v", ctx["monitors"])
+	}
+	if _, ok := ctx["host"]; !ok {
+		t.Error("Expected host in context")
+	}
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	_ "net/http/pprof" // Register pprof handlers
@@ -12,6 +14,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -20,6 +23,7 @@ import (
 
 	"github.com/angch/sentrylogmon/config"
 	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/exporters"
 	"github.com/angch/sentrylogmon/ipc"
 	"github.com/angch/sentrylogmon/monitor"
 	"github.com/angch/sentrylogmon/sources"
@@ -29,14 +33,29 @@ import (
 )
 
 var (
-	statusFlag = flag.Bool("status", false, "List running instances")
-	updateFlag = flag.Bool("update", false, "Update/Restart all running instances")
-	initFlag   = flag.Bool("init", false, "Generate a starter configuration file")
+	statusFlag    = flag.Bool("status", false, "List running instances")
+	updateFlag    = flag.Bool("update", false, "Update/Restart all running instances")
+	tailFlag      = flag.Bool("tail", false, "Print the events running instances recently sent to Sentry, then follow new ones")
+	initFlag      = flag.Bool("init", false, "Generate a starter configuration file")
+	checkDSNFlag  = flag.Bool("check-dsn", false, "Send a test event to the configured Sentry DSN and report the result")
+	validateFlag  = flag.Bool("validate", false, "Validate the configuration, report all problems, and exit")
+	listDetectors = flag.Bool("list-detectors", false, "List the available detector formats and their aliases, and exit")
+	configSchema  = flag.Bool("config-schema", false, "Print a JSON Schema of the configuration file, for editor completion and validation, and exit")
+	logFileFlag   = flag.String("log-file", "", "Write sentrylogmon's own logs to this file instead of stderr")
+	logMaxSizeMB  = flag.Int("log-max-size", 10, "Rotate --log-file after it reaches this many megabytes (keeps 3 old files)")
+	socketDirFlag = flag.String("socket-dir", "", "Directory for IPC sockets, used by --status and --update too (default: $SENTRYLOGMON_SOCKET_DIR or a per-user temp directory)")
+	maxLifetime   = flag.Duration("max-lifetime", 0, "Restart (re-exec) the process after running this long, e.g. 24h (0 to disable)")
+	flushTimeout  = flag.Duration("flush-timeout", 0, "How long to wait on exit for queued events to reach Sentry (default: 2s, or 30s with --oneshot)")
+	cpuProfile    = flag.String("benchmark-cpuprofile", "", "With --benchmark, write a CPU profile to this file for go tool pprof")
+	diffConfig    = flag.String("diff-config", "", "Show what reloading this config file would change in each running instance, and exit")
 )
 
 func main() {
 	// Ensure flags are parsed first to handle --status/--update without requiring full config
 	config.ParseFlags()
+	if *socketDirFlag != "" {
+		ipc.SetSocketDir(*socketDirFlag)
+	}
 
 	if *statusFlag {
 		instances, err := ipc.ListInstances(ipc.GetSocketDir())
@@ -65,7 +84,7 @@ func main() {
 			log.Fatalf("Error listing instances: %v", err)
 		}
 		for _, inst := range instances {
-			socketPath := filepath.Join(ipc.GetSocketDir(), fmt.Sprintf("sentrylogmon.%d.sock", inst.PID))
+			socketPath := ipc.SocketPath(ipc.GetSocketDir(), inst.PID)
 			fmt.Printf("Requesting update for PID %d...\n", inst.PID)
 			if err := ipc.RequestUpdate(socketPath); err != nil {
 				fmt.Printf("Failed to update PID %d: %v\n", inst.PID, err)
@@ -76,6 +95,36 @@ func main() {
 		return
 	}
 
+	if *diffConfig != "" {
+		if err := diffInstances(ipc.GetSocketDir(), *diffConfig, os.Stdout); err != nil {
+			log.Fatalf("Error comparing configurations: %v", err)
+		}
+		return
+	}
+
+	if *tailFlag {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := tailInstances(ctx, ipc.GetSocketDir(), os.Stdout, tailInterval); err != nil {
+			log.Fatalf("Error listing instances: %v", err)
+		}
+		return
+	}
+
+	if *listDetectors {
+		printDetectors(os.Stdout)
+		return
+	}
+
+	if *configSchema {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(config.Schema()); err != nil {
+			log.Fatalf("Error writing schema: %v", err)
+		}
+		return
+	}
+
 	if *initFlag {
 		if err := generateConfig("sentrylogmon.yaml"); err != nil {
 			log.Fatalf("Error generating config: %v", err)
@@ -84,26 +133,90 @@ func main() {
 		return
 	}
 
+	if *checkDSNFlag {
+		sentryCfg, err := config.LoadSentry()
+		if err != nil {
+			log.Fatalf("Failed to load configuration: %v", err)
+		}
+		latency, err := checkDSN(sentryCfg, 10*time.Second)
+		if err != nil {
+			fmt.Printf("DSN check failed after %v: %v\n", latency.Round(time.Millisecond), err)
+			os.Exit(1)
+		}
+		fmt.Printf("DSN check succeeded: test event accepted in %v\n", latency.Round(time.Millisecond))
+		return
+	}
+
+	if *validateFlag {
+		// Load validates the configuration
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Println("Configuration is invalid:")
+			for _, line := range strings.Split(err.Error(), "\n") {
+				fmt.Printf("  - %s\n", line)
+			}
+			os.Exit(1)
+		}
+		for _, w := range cfg.Warnings() {
+			fmt.Printf("Warning: %s\n", w)
+		}
+		fmt.Println("Configuration is valid")
+		return
+	}
+
+	if *logFileFlag != "" {
+		w, err := exporters.NewRotatingWriter(*logFileFlag, exporters.RotateOptions{MaxBytes: int64(*logMaxSizeMB) * 1024 * 1024, Backups: 3})
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		defer w.Close()
+		log.SetOutput(w)
+	}
+
 	// Load configuration after checking for IPC flags
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	for _, w := range cfg.Warnings() {
+		log.Printf("Warning: %s", w)
+	}
+
+	if cfg.Benchmark != "" {
+		if err := runBenchmarks(os.Stdout, cfg, cfg.Benchmark, *cpuProfile); err != nil {
+			log.Fatalf("Benchmark failed: %v", err)
+		}
+		return
+	}
 
-	if cfg.Sentry.DSN == "" {
+	var sample *sampleCollector
+	var sampleFull <-chan struct{} // nil unless sampling, so it never fires
+	if cfg.SampleMatches > 0 {
+		prepareSampleConfig(cfg)
+		sample, err = newSampleCollector(cfg.SampleMatches)
+		if err != nil {
+			log.Fatalf("Failed to set up sampling: %v", err)
+		}
+		sampleFull = sample.full
+	}
+
+	if cfg.Sentry.DSN == "" && sample == nil {
 		log.Fatal("Sentry DSN is required. Set via --dsn flag, SENTRY_DSN environment variable, or config file")
 	}
 
 	// Initialize Sentry
+	delivered := &deliveryCounter{base: http.DefaultTransport}
 	err = sentry.Init(sentry.ClientOptions{
-		Dsn:         cfg.Sentry.DSN,
-		Environment: cfg.Sentry.Environment,
-		Release:     cfg.Sentry.Release,
+		Dsn:           cfg.Sentry.DSN,
+		Environment:   cfg.Sentry.Environment,
+		Release:       cfg.Sentry.Release,
+		Dist:          cfg.Sentry.Dist,
+		SampleRate:    cfg.Sentry.SampleRate,
+		HTTPTransport: delivered,
 	})
 	if err != nil {
 		log.Fatalf("Failed to initialize Sentry: %v", err)
 	}
-	defer sentry.Flush(2 * time.Second)
 
 	if cfg.Verbose {
 		log.Printf("Initialized Sentry (env=%s, release=%s)", cfg.Sentry.Environment, cfg.Sentry.Release)
@@ -126,159 +239,100 @@ func main() {
 	}
 
 	if len(cfg.Monitors) == 0 {
-		log.Fatal("No monitors configured. Use --file, --dmesg, --journalctl, --command, or config file.")
-	}
-
-	if cfg.MetricsPort > 0 {
-		go func() {
-			addr := fmt.Sprintf(":%d", cfg.MetricsPort)
-			if cfg.Verbose {
-				log.Printf("Starting Prometheus metrics server on %s/metrics", addr)
-			}
-			http.Handle("/metrics", promhttp.Handler())
-			http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusOK)
-				w.Write([]byte("OK"))
-			})
-			if err := http.ListenAndServe(addr, nil); err != nil {
-				log.Printf("Failed to start metrics server: %v", err)
-			}
-		}()
+		log.Fatal("No monitors configured. Use --file, --dmesg, --journalctl, --command, --syslog, --ssh, or config file.")
 	}
 
 	// Start System Stats Collector
 	sysstatCollector := sysstat.New()
 	go sysstatCollector.Run()
 
-	// Start monitors
-	var monitors []*monitor.Monitor
+	// Shared by all monitors to cap the combined event volume
+	var globalRateLimiter *monitor.RateLimiter
+	if cfg.GlobalRateLimitBurst > 0 {
+		window := time.Second
+		if cfg.GlobalRateLimitWindow != "" {
+			if d, err := time.ParseDuration(cfg.GlobalRateLimitWindow); err == nil {
+				window = d
+			} else {
+				log.Printf("Invalid global rate limit window '%s', defaulting to 1s: %v", cfg.GlobalRateLimitWindow, err)
+			}
+		}
+		globalRateLimiter = monitor.NewRateLimiter(cfg.GlobalRateLimitBurst, window)
+	}
 
-	addMonitor := func(src sources.LogSource, monCfg config.MonitorConfig) {
-		detectorFormat := determineDetectorFormat(monCfg)
+	builder := &monitorBuilder{
+		cfg:               cfg,
+		collector:         sysstatCollector,
+		globalRateLimiter: globalRateLimiter,
+		httpTransport:     delivered,
+	}
+	if sample != nil {
+		builder.onMatch = sample.add
+	}
 
-		det, err := detectors.GetDetector(detectorFormat, monCfg.Pattern)
-		if err != nil {
-			log.Printf("Failed to create detector for monitor '%s': %v", monCfg.Name, err)
-			return
+	// Start monitors
+	monitors := &monitorSet{}
+	for _, monCfg := range enabledMonitors(cfg) {
+		if g := newGroup(ctx, builder, monCfg); g != nil {
+			monitors.groups = append(monitors.groups, g)
 		}
+	}
 
-		// Prepare Sentry Options
-		sentryDSN := monCfg.Sentry.DSN
-		sentryEnv := monCfg.Sentry.Environment
-		sentryRelease := monCfg.Sentry.Release
+	if len(monitors.groups) == 0 {
+		log.Fatal("No valid monitors to start.")
+	}
 
-		// Inherit global config if DSN is overridden but other fields are missing
-		if sentryDSN != "" {
-			if sentryEnv == "" {
-				sentryEnv = cfg.Sentry.Environment
+	if cfg.MetricsPort > 0 {
+		var staleness time.Duration
+		if cfg.HealthStaleness != "" {
+			d, err := time.ParseDuration(cfg.HealthStaleness)
+			if err != nil {
+				log.Printf("Invalid health staleness '%s', ignoring: %v", cfg.HealthStaleness, err)
 			}
-			if sentryRelease == "" {
-				sentryRelease = cfg.Sentry.Release
+			staleness = d
+		}
+		healthFunc := func() []monitor.Health {
+			running := monitors.all()
+			health := make([]monitor.Health, 0, len(running))
+			for _, m := range running {
+				health = append(health, m.Health())
 			}
+			return health
 		}
 
-		m, err := monitor.New(ctx, src, det, sysstatCollector, monitor.Options{
-			Verbose:           cfg.Verbose,
-			ExcludePattern:    monCfg.ExcludePattern,
-			MaxInactivity:     monCfg.MaxInactivity,
-			RateLimitBurst:    monCfg.RateLimitBurst,
-			RateLimitWindow:   monCfg.RateLimitWindow,
-			SentryDSN:         sentryDSN,
-			SentryEnvironment: sentryEnv,
-			SentryRelease:     sentryRelease,
-		})
-		if err != nil {
-			log.Printf("Failed to create monitor '%s': %v", monCfg.Name, err)
-			return
+		addr := fmt.Sprintf(":%d", cfg.MetricsPort)
+		if cfg.Verbose {
+			log.Printf("Starting Prometheus metrics server on %s/metrics", addr)
 		}
-		m.StopOnEOF = cfg.OneShot
-		monitors = append(monitors, m)
-	}
-
-	for _, monCfg := range cfg.Monitors {
-		switch monCfg.Type {
-		case "file":
-			if monCfg.Path == "" {
-				log.Printf("Skipping file monitor '%s': path is empty", monCfg.Name)
-				continue
-			}
-
-			if strings.ContainsAny(monCfg.Path, "*?[]") {
-				matches, err := filepath.Glob(monCfg.Path)
-				if err != nil {
-					log.Printf("Error matching glob pattern %s: %v", monCfg.Path, err)
-					continue
-				}
-				if len(matches) == 0 {
-					log.Printf("No files matched glob pattern %s", monCfg.Path)
-					continue
-				}
-				for _, match := range matches {
-					// Use a unique name for each file source
-					name := monCfg.Name + ":" + match
-					src := sources.NewFileSource(name, match)
-					addMonitor(src, monCfg)
-				}
-			} else {
-				src := sources.NewFileSource(monCfg.Name, monCfg.Path)
-				addMonitor(src, monCfg)
-			}
-		case "journalctl":
-			src := sources.NewJournalctlSource(monCfg.Name, monCfg.Args)
-			addMonitor(src, monCfg)
-		case "dmesg":
-			src := sources.NewDmesgSource(monCfg.Name)
-			addMonitor(src, monCfg)
-		case "command":
-			parts := strings.Fields(monCfg.Args)
-			if len(parts) > 0 {
-				src := sources.NewCommandSource(monCfg.Name, parts[0], parts[1:]...)
-				addMonitor(src, monCfg)
-			} else {
-				log.Printf("Skipping command monitor '%s': command is empty", monCfg.Name)
-				continue
-			}
-		case "syslog":
-			src := sources.NewSyslogSource(monCfg.Name, monCfg.Path)
-			addMonitor(src, monCfg)
-		default:
-			log.Printf("Unknown monitor type: %s", monCfg.Type)
-			continue
+		http.Handle("/metrics", promhttp.Handler())
+		http.HandleFunc("/healthz", healthHandler(healthFunc, staleness))
+		go superviseServer(ctx, "metrics", func() error {
+			return http.ListenAndServe(addr, nil)
+		}, sentry.CurrentHub())
+	}
+
+	// Per-monitor and mirror hubs have their own transports to flush, as does the global hub
+	exitTimeout := exitFlushTimeout(cfg.OneShot)
+	defer func() {
+		if !flushAll(monitors.all(), exitTimeout) {
+			log.Printf("Timed out after %v waiting for events to reach Sentry; some may be lost (see --flush-timeout)", exitTimeout)
 		}
-	}
+		if cfg.OneShot && sample == nil {
+			log.Printf("%d events confirmed by Sentry", delivered.confirmed.Load())
+		}
+	}()
 
-	if len(monitors) == 0 {
-		log.Fatal("No valid monitors to start.")
+	for _, g := range monitors.groups {
+		monitors.start(g)
 	}
-
-	var wg sync.WaitGroup
-	for _, m := range monitors {
-		wg.Add(1)
-		go func(mon *monitor.Monitor) {
-			defer wg.Done()
-			mon.Start()
-		}(m)
+	lifecycleEvents := cfg.LifecycleEvents && sample == nil
+	if lifecycleEvents {
+		sendLifecycleEvent(sentry.CurrentHub(), "started", cfg, monitorSources(monitors))
 	}
 
 	shutdown := func() {
 		cancel()
-		for _, m := range monitors {
-			if err := m.Source.Close(); err != nil {
-				log.Printf("Error closing source %s: %v", m.Source.Name(), err)
-			}
-		}
-
-		done := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(done)
-		}()
-
-		select {
-		case <-done:
-		case <-time.After(5 * time.Second):
-			log.Println("Timeout waiting for monitors to stop")
-		}
+		monitors.stopAll(5 * time.Second)
 	}
 
 	// Start IPC Server
@@ -289,44 +343,82 @@ func main() {
 	if err := ipc.EnsureSecureDirectory(socketDir); err != nil {
 		log.Printf("Failed to ensure secure IPC directory: %v", err)
 	} else {
-		socketPath = filepath.Join(socketDir, fmt.Sprintf("sentrylogmon.%d.sock", os.Getpid()))
+		socketPath = ipc.SocketPath(socketDir, os.Getpid())
 		defer os.Remove(socketPath)
 	}
 
+	// IPC updates, config reloads and --max-lifetime can all ask for a restart; only the first one runs
+	var restartOnce sync.Once
 	restartFunc = func() {
-		log.Println("Restart requested. Shutting down...")
-		shutdown()
+		restartOnce.Do(func() {
+			log.Println("Restart requested. Shutting down...")
+			shutdown()
+			// Deferred flushes do not run across exec
+			flushAll(monitors.all(), exitTimeout)
 
-		if socketPath != "" {
-			os.Remove(socketPath)
+			if err := reexec(socketPath); err != nil {
+				log.Fatalf("Failed to re-exec: %v", err)
+			}
+		})
+	}
+
+	statsFunc := func() []ipc.MonitorStats {
+		running := monitors.all()
+		stats := make([]ipc.MonitorStats, 0, len(running))
+		for _, m := range running {
+			s := m.Stats()
+			stats = append(stats, ipc.MonitorStats{
+				Source:        s.Source,
+				LinesPerSec:   s.LinesPerSec,
+				MatchesPerSec: s.MatchesPerSec,
+			})
 		}
+		return stats
+	}
 
-		executable, err := os.Executable()
+	// The running configuration changes when a reload is applied in place
+	var runningMu sync.Mutex
+	running := cfg
+	configFunc := func() *config.Config {
+		runningMu.Lock()
+		defer runningMu.Unlock()
+		return running
+	}
+
+	// A config change that only touches monitors is applied in place, keeping
+	// unchanged monitors (and their file positions); anything else restarts
+	reloadFunc := func() {
+		newCfg, err := config.Load()
 		if err != nil {
-			log.Printf("Failed to get executable path: %v", err)
+			log.Printf("Failed to load changed configuration, ignoring: %v", err)
 			return
 		}
-
-		log.Printf("Re-executing %s %v", executable, os.Args[1:])
-		if err := syscall.Exec(executable, os.Args, os.Environ()); err != nil {
-			log.Fatalf("Failed to re-exec: %v", err)
+		if sample != nil || !configFunc().SameGlobals(newCfg) {
+			restartFunc()
+			return
 		}
+		kept, stopped, started := monitors.reload(ctx, builder, enabledMonitors(newCfg))
+		log.Printf("Configuration reloaded in place: %d monitors kept, %d stopped, %d started", kept, stopped, started)
+		runningMu.Lock()
+		running = newCfg
+		runningMu.Unlock()
 	}
 
 	if socketPath != "" {
-		go func() {
-			if err := ipc.StartServer(socketPath, cfg, restartFunc); err != nil {
-				log.Printf("IPC Server error: %v", err)
-			}
-		}()
+		go superviseServer(ctx, "IPC", func() error {
+			return ipc.StartServer(socketPath, configFunc, restartFunc, statsFunc, func() []ipc.RecentEvent {
+				return recentEvents(monitors.all())
+			})
+		}, sentry.CurrentHub())
+	}
+
+	if *maxLifetime > 0 {
+		go scheduleMaxLifetime(ctx, *maxLifetime, restartFunc)
 	}
 
 	// Start config watcher
-	if f := flag.Lookup("config"); f != nil {
-		configPath := f.Value.String()
-		if configPath != "" {
-			go watchConfig(ctx, configPath, restartFunc)
-		}
+	if configPaths := config.Files(); len(configPaths) > 0 {
+		go watchConfig(ctx, configPaths, reloadFunc)
 	}
 
 	// Wait for signals
@@ -336,7 +428,7 @@ func main() {
 	if cfg.OneShot {
 		done := make(chan struct{})
 		go func() {
-			wg.Wait()
+			monitors.wg.Wait()
 			close(done)
 		}()
 
@@ -345,6 +437,8 @@ func main() {
 			if cfg.Verbose {
 				log.Println("All monitors finished.")
 			}
+		case <-sampleFull:
+			shutdown()
 		case sig := <-c:
 			if cfg.Verbose {
 				log.Printf("Received signal %v, shutting down...", sig)
@@ -358,29 +452,207 @@ func main() {
 		}
 		shutdown()
 	}
+
+	if lifecycleEvents {
+		sendLifecycleEvent(sentry.CurrentHub(), "stopped", configFunc(), monitorSources(monitors))
+	}
+
+	if sample != nil {
+		n, err := sample.writeFile(cfg.SampleOut)
+		if err != nil {
+			log.Fatalf("Failed to write sample: %v", err)
+		}
+		fmt.Printf("Wrote %d redacted matched lines to %s\n", n, cfg.SampleOut)
+	}
+}
+
+// monitorEnabled reports whether a monitor should run in the resolved Sentry environment.
+// The environment is the monitor's own Sentry override (when it sets a DSN), otherwise the global one.
+// An empty environments list enables the monitor everywhere.
+func monitorEnabled(monCfg config.MonitorConfig, globalEnv string) bool {
+	if len(monCfg.Environments) == 0 {
+		return true
+	}
+	env := globalEnv
+	if monCfg.Sentry.DSN != "" && monCfg.Sentry.Environment != "" {
+		env = monCfg.Sentry.Environment
+	}
+	for _, e := range monCfg.Environments {
+		if strings.EqualFold(e, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupByKey returns the monitor's group_by key. journalctl monitors with JSON
+// output are grouped by systemd unit unless group_by is set.
+func groupByKey(monCfg config.MonitorConfig) string {
+	if monCfg.GroupBy != "" {
+		return monCfg.GroupBy
+	}
+	if monCfg.Type == "journalctl" && journalctlJSONOutput(monCfg.Args) {
+		return monitor.SystemdUnitKey
+	}
+	return ""
+}
+
+// journalctlJSONOutput reports whether journalctl args select JSON output (-o json, --output=json).
+func journalctlJSONOutput(args string) bool {
+	fields := strings.Fields(args)
+	for i, f := range fields {
+		switch {
+		case f == "-ojson" || f == "--output=json":
+			return true
+		case (f == "-o" || f == "--output") && i+1 < len(fields) && fields[i+1] == "json":
+			return true
+		}
+	}
+	return false
+}
+
+type globFile struct {
+	name string
+	path string
+}
+
+// newFileSource tails path, or replays it once when the monitor has replay set.
+func newFileSource(name, path string, monCfg config.MonitorConfig, opts sources.FileOptions) sources.LogSource {
+	if monCfg.Replay {
+		return sources.NewReplaySource(name, path, sources.ReplayOptions{Speed: monCfg.ReplaySpeed})
+	}
+	return sources.NewFileSource(name, path, opts)
+}
+
+// globFileSources expands a file monitor's glob pattern. Each file gets the
+// source name "<monitor name>:<path>", which is unique per file and stays the
+// same across restarts. Files are returned in sorted order. Matches of an
+// ignore pattern, directories and files that look binary are left out and logged.
+func globFileSources(name, pattern string, ignore []string) ([]globFile, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	files := make([]globFile, 0, len(matches))
+	for _, match := range matches {
+		if ig := matchIgnoreGlob(match, ignore); ig != "" {
+			log.Printf("Monitor '%s': ignoring %s (matches ignore_globs pattern %q)", name, match, ig)
+			continue
+		}
+		if fi, err := os.Stat(match); err == nil && fi.IsDir() {
+			log.Printf("Monitor '%s': ignoring %s (directory)", name, match)
+			continue
+		}
+		if isBinaryFile(match) {
+			log.Printf("Monitor '%s': ignoring %s (binary content)", name, match)
+			continue
+		}
+		files = append(files, globFile{name: name + ":" + match, path: match})
+	}
+	return files, nil
+}
+
+// matchIgnoreGlob returns the first ignore pattern matching path, or "".
+// Patterns without a path separator match the file name, others the full path.
+func matchIgnoreGlob(path string, ignore []string) string {
+	for _, p := range ignore {
+		target := path
+		if !strings.ContainsRune(p, filepath.Separator) {
+			target = filepath.Base(path)
+		}
+		if ok, _ := filepath.Match(p, target); ok {
+			return p
+		}
+	}
+	return ""
 }
 
-func determineDetectorFormat(monCfg config.MonitorConfig) string {
-	if monCfg.Format != "" {
-		return monCfg.Format
+// binarySniffLen is how much of a file isBinaryFile inspects, as git does.
+const binarySniffLen = 8000
+
+// isBinaryFile reports whether the start of the file contains a NUL byte,
+// which text logs never do (e.g. lastlog, wtmp, compressed archives).
+// Unreadable files are reported as binary.
+func isBinaryFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return true
 	}
-	// If pattern is present, assume custom (GenericDetector).
-	// This allows overriding the default dmesg detector for dmesg source if a custom pattern is provided.
-	if monCfg.Pattern != "" {
-		return "custom"
+	defer f.Close()
+	buf := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return true
 	}
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
 
-	// Infer detector format from monitor type.
-	// Specifically, 'dmesg' source type defaults to 'dmesg' detector format.
-	if monCfg.Type == "dmesg" {
-		return "dmesg"
+// newDetector builds a monitor's line detector. A monitor with only a
+// sequence rule has no line detector, so it may return nil.
+func newDetector(monCfg config.MonitorConfig) (detectors.Detector, error) {
+	var det detectors.Detector
+	if monCfg.PatternFile != "" {
+		patterns, err := detectors.ReadPatternFile(monCfg.PatternFile)
+		if err != nil {
+			return nil, err
+		}
+		if monCfg.Pattern != "" {
+			patterns = append([]string{monCfg.Pattern}, patterns...)
+		}
+		det, err = detectors.NewMultiDetector(patterns)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(monCfg.Sequence) == 0 || monCfg.Pattern != "" || monCfg.Format != "" {
+		var err error
+		det, err = detectors.GetDetectorWithExtra(monCfg.DetectorFormat(), monCfg.Pattern, monCfg.ExtraPattern)
+		if err != nil {
+			return nil, err
+		}
 	}
+	if len(monCfg.StatusClasses) > 0 || len(monCfg.IgnoreStatus) > 0 {
+		nd, ok := det.(*detectors.NginxDetector)
+		if !ok {
+			return nil, fmt.Errorf("status_classes requires format: nginx")
+		}
+		filter, err := detectors.NewStatusFilter(monCfg.StatusClasses, monCfg.IgnoreStatus)
+		if err != nil {
+			return nil, err
+		}
+		nd.SetStatusFilter(filter)
+	}
+	if monCfg.DecodeField != nil {
+		jd, ok := det.(*detectors.JsonDetector)
+		if !ok {
+			return nil, fmt.Errorf("decode_field requires format: json")
+		}
+		if err := jd.SetDecodeField(monCfg.DecodeField.Name, monCfg.DecodeField.Encoding); err != nil {
+			return nil, err
+		}
+	}
+	return det, nil
+}
 
-	// Infer detector format from monitor name if it matches a known detector (e.g. "nginx").
-	if detectors.IsKnownDetector(monCfg.Name) {
-		return monCfg.Name
+// printDetectors writes a table of the registered detectors to w.
+func printDetectors(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "FORMAT\tALIASES\tCONTEXT\tTIMESTAMP\tDESCRIPTION")
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	for _, d := range detectors.List() {
+		aliases := strings.Join(d.Aliases, ", ")
+		if aliases == "" {
+			aliases = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Name, aliases, yesNo(d.Context), yesNo(d.Timestamp), d.Description)
 	}
-	return "custom"
+	tw.Flush()
 }
 
 func printInstanceTable(instances []ipc.StatusResponse) {
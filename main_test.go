@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -148,94 +150,6 @@ func TestMessageFormatting(t *testing.T) {
 	}
 }
 
-func TestDetermineDetectorFormat(t *testing.T) {
-	tests := []struct {
-		name     string
-		monCfg   config.MonitorConfig
-		expected string
-	}{
-		{
-			name: "Explicit format",
-			monCfg: config.MonitorConfig{
-				Format: "nginx",
-			},
-			expected: "nginx",
-		},
-		{
-			name: "Dmesg type without pattern",
-			monCfg: config.MonitorConfig{
-				Type: "dmesg",
-			},
-			expected: "dmesg",
-		},
-		{
-			name: "Dmesg type with custom pattern",
-			monCfg: config.MonitorConfig{
-				Type:    "dmesg",
-				Pattern: "some-error",
-			},
-			expected: "custom",
-		},
-		{
-			name: "File type without pattern",
-			monCfg: config.MonitorConfig{
-				Type: "file",
-			},
-			expected: "custom",
-		},
-		{
-			name: "File type with pattern",
-			monCfg: config.MonitorConfig{
-				Type:    "file",
-				Pattern: "some-error",
-			},
-			expected: "custom",
-		},
-		{
-			name: "Explicit format overrides pattern and type",
-			monCfg: config.MonitorConfig{
-				Format:  "nginx",
-				Type:    "dmesg",
-				Pattern: "some-error",
-			},
-			expected: "nginx",
-		},
-		{
-			name: "Name matching known detector (nginx)",
-			monCfg: config.MonitorConfig{
-				Name: "nginx",
-				Type: "file",
-			},
-			expected: "nginx",
-		},
-		{
-			name: "Name matching known detector (nginx-error)",
-			monCfg: config.MonitorConfig{
-				Name: "nginx-error",
-				Type: "file",
-			},
-			expected: "nginx-error",
-		},
-		{
-			name: "Unknown Name defaults to custom",
-			monCfg: config.MonitorConfig{
-				Name: "foobar",
-				Type: "file",
-			},
-			expected: "custom",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := determineDetectorFormat(tt.monCfg)
-			if got != tt.expected {
-				t.Errorf("determineDetectorFormat() = %v, want %v", got, tt.expected)
-			}
-		})
-	}
-}
-
 func TestFormatDuration(t *testing.T) {
 	tests := []struct {
 		d        time.Duration
@@ -296,3 +210,180 @@ func TestGenerateConfig(t *testing.T) {
 		t.Errorf("Unexpected error message: %v", err)
 	}
 }
+
+func TestPrintDetectors(t *testing.T) {
+	var buf bytes.Buffer
+	printDetectors(&buf)
+	out := buf.String()
+
+	for _, name := range []string{"dmesg", "nginx", "nginx-error", "haproxy", "json", "custom"} {
+		if !regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(name) + ` `).MatchString(out) {
+			t.Errorf("Expected detector %q to be listed, got:\n%s", name, out)
+		}
+	}
+	for _, alias := range []string{"jsonl, ndjson", "nginx_error", "kernel"} {
+		if !strings.Contains(out, alias) {
+			t.Errorf("Expected alias %q to be listed, got:\n%s", alias, out)
+		}
+	}
+}
+
+func TestMonitorEnabled(t *testing.T) {
+	tests := []struct {
+		name      string
+		monCfg    config.MonitorConfig
+		globalEnv string
+		expected  bool
+	}{
+		{
+			name:      "No environments runs everywhere",
+			monCfg:    config.MonitorConfig{Name: "all"},
+			globalEnv: "staging",
+			expected:  true,
+		},
+		{
+			name:      "Restricted to prod, running in staging",
+			monCfg:    config.MonitorConfig{Name: "prod-only", Environments: []string{"prod"}},
+			globalEnv: "staging",
+			expected:  false,
+		},
+		{
+			name:      "Restricted to prod, running in prod",
+			monCfg:    config.MonitorConfig{Name: "prod-only", Environments: []string{"staging", "Prod"}},
+			globalEnv: "prod",
+			expected:  true,
+		},
+		{
+			name: "Monitor Sentry override decides the environment",
+			monCfg: config.MonitorConfig{
+				Name:         "override",
+				Environments: []string{"prod"},
+				Sentry:       config.SentryConfig{DSN: "https://key@sentry.io/2", Environment: "prod"},
+			},
+			globalEnv: "staging",
+			expected:  true,
+		},
+		{
+			name: "Environment override without DSN is ignored",
+			monCfg: config.MonitorConfig{
+				Name:         "no-dsn",
+				Environments: []string{"prod"},
+				Sentry:       config.SentryConfig{Environment: "prod"},
+			},
+			globalEnv: "staging",
+			expected:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monitorEnabled(tt.monCfg, tt.globalEnv); got != tt.expected {
+				t.Errorf("monitorEnabled() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGlobFileSources(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.log", "a.log", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := globFileSources("app", filepath.Join(dir, "*.log"), nil)
+	if err != nil {
+		t.Fatalf("globFileSources failed: %v", err)
+	}
+
+	expected := []string{
+		"app:" + filepath.Join(dir, "a.log"),
+		"app:" + filepath.Join(dir, "b.log"),
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %d files, got %d", len(expected), len(files))
+	}
+	seen := make(map[string]bool)
+	for i, f := range files {
+		if f.name != expected[i] {
+			t.Errorf("Expected name %q, got %q", expected[i], f.name)
+		}
+		if seen[f.name] {
+			t.Errorf("Duplicate source name %q", f.name)
+		}
+		seen[f.name] = true
+	}
+
+	// Names are stable across calls
+	again, _ := globFileSources("app", filepath.Join(dir, "*.log"), nil)
+	for i := range files {
+		if again[i] != files[i] {
+			t.Errorf("Expected stable names, got %v then %v", files, again)
+		}
+	}
+}
+
+func TestGlobFileSourcesIgnore(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"app.log":     []byte("2024-01-01 ok\n"),
+		"debug.log":   []byte("noise\n"),
+		"lastlog.log": {0x00, 0x00, 0x01, 0x7f},
+		"empty.log":   nil,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "archive.log"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := globFileSources("app", filepath.Join(dir, "*.log"), []string{"debug.*"})
+	if err != nil {
+		t.Fatalf("globFileSources failed: %v", err)
+	}
+
+	var paths []string
+	for _, f := range got {
+		paths = append(paths, filepath.Base(f.path))
+	}
+	expected := []string{"app.log", "empty.log"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	// Patterns with a separator match the full path
+	got, err = globFileSources("app", filepath.Join(dir, "*.log"), []string{filepath.Join(dir, "app.*")})
+	if err != nil {
+		t.Fatalf("globFileSources failed: %v", err)
+	}
+	for _, f := range got {
+		if filepath.Base(f.path) == "app.log" {
+			t.Errorf("Expected app.log to be ignored by full-path pattern, got %v", got)
+		}
+	}
+}
+
+func TestGroupByKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		monCfg   config.MonitorConfig
+		expected string
+	}{
+		{"Journalctl JSON", config.MonitorConfig{Type: "journalctl", Args: "-f -o json"}, "_SYSTEMD_UNIT"},
+		{"Journalctl JSON Long Flag", config.MonitorConfig{Type: "journalctl", Args: "--output=json -f"}, "_SYSTEMD_UNIT"},
+		{"Journalctl Short Output", config.MonitorConfig{Type: "journalctl", Args: "-f -o short"}, ""},
+		{"Explicit Group By Wins", config.MonitorConfig{Type: "journalctl", Args: "-o json", GroupBy: "PRIORITY"}, "PRIORITY"},
+		{"File Monitor", config.MonitorConfig{Type: "file", Args: "-o json"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupByKey(tt.monCfg); got != tt.expected {
+				t.Errorf("groupByKey() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -29,6 +29,71 @@ var (
 		[]string{"source", "status"},
 	)
 
+	OversizeTrimmedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentrylogmon_oversize_trimmed_total",
+			Help: "Total number of events trimmed to fit the maximum Sentry event size, or dropped when trimming was not enough.",
+		},
+		[]string{"source"},
+	)
+
+	GroupEvictionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentrylogmon_group_evictions_total",
+			Help: "Total number of group_by batches flushed early because the monitor reached max_fingerprints groups.",
+		},
+		[]string{"source"},
+	)
+
+	BatchFlushesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentrylogmon_batch_flushes_total",
+			Help: "Total number of batches sent, by reason: timer, size, age, time_window, evicted or force.",
+		},
+		[]string{"source", "reason"},
+	)
+
+	BatchLines = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sentrylogmon_batch_lines",
+			Help:    "Number of lines in each batch sent as one event.",
+			Buckets: []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+		},
+		[]string{"source"},
+	)
+
+	IgnoredLinesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentrylogmon_ignored_lines_total",
+			Help: "Total number of log lines skipped because they contained an ignore_substrings entry.",
+		},
+		[]string{"source"},
+	)
+
+	TruncatedLinesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentrylogmon_truncated_lines_total",
+			Help: "Total number of log lines longer than the 1MB read buffer, of which only the start was checked.",
+		},
+		[]string{"source"},
+	)
+
+	ThroughputLinesPerSec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sentrylogmon_throughput_lines_per_sec",
+			Help: "Moving average (1m) of lines read per second.",
+		},
+		[]string{"source"},
+	)
+
+	MatchRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sentrylogmon_match_rate",
+			Help: "Moving average (1m) of lines matched by the detector per second.",
+		},
+		[]string{"source"},
+	)
+
 	LastActivityTimestamp = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "sentrylogmon_last_activity_timestamp_seconds",
@@ -42,5 +107,13 @@ func init() {
 	prometheus.MustRegister(ProcessedLinesTotal)
 	prometheus.MustRegister(IssuesDetectedTotal)
 	prometheus.MustRegister(SentryEventsTotal)
+	prometheus.MustRegister(OversizeTrimmedTotal)
+	prometheus.MustRegister(GroupEvictionsTotal)
+	prometheus.MustRegister(BatchFlushesTotal)
+	prometheus.MustRegister(BatchLines)
+	prometheus.MustRegister(IgnoredLinesTotal)
+	prometheus.MustRegister(TruncatedLinesTotal)
 	prometheus.MustRegister(LastActivityTimestamp)
+	prometheus.MustRegister(ThroughputLinesPerSec)
+	prometheus.MustRegister(MatchRate)
 }
@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"log"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// contextAttachment returns the lines around the first line of a batch,
+// reread from the source and redacted like reported lines, as a "context.log"
+// attachment. It returns nil if the source no longer has the line where it
+// was read, e.g. after a rotation.
+func (m *Monitor) contextAttachment(meta BatchMetadata) *sentry.Attachment {
+	if m.contextSource == nil || meta.RawLine == nil {
+		return nil
+	}
+	lines, err := m.contextSource.LinesAround(meta.RawOffset, meta.RawLine, m.contextAttachLines)
+	if err != nil {
+		log.Printf("[%s] Failed to read context lines: %v", m.Source.Name(), err)
+		return nil
+	}
+	if lines == nil {
+		if m.Verbose {
+			log.Printf("[%s] Matched line no longer found in the source, sending no context lines", m.Source.Name())
+		}
+		return nil
+	}
+	if m.redactor != nil {
+		lines = m.redactor.TransformMessage(lines)
+	}
+	return &sentry.Attachment{
+		Filename:    "context.log",
+		ContentType: "text/plain",
+		Payload:     lines,
+	}
+}
@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/sources"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestContextAttachLines(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	content := "request 1 ok\nrequest 2 ok\nopening db password=hunter2\nFATAL out of memory\n  at alloc()\n  at main()\nrequest 3 ok\n"
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source := sources.NewFileSource("app", logPath, sources.FileOptions{FromStart: true})
+	det, err := detectors.NewGenericDetector("FATAL")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	mon, err := New(context.Background(), source, det, nil, Options{
+		ContextAttachLines: 2,
+		Redact:             []string{`password=\w+`},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	done := make(chan struct{})
+	go func() {
+		mon.Start()
+		close(done)
+	}()
+
+	// Closing the source ends the scan, which flushes the batch
+	time.Sleep(300 * time.Millisecond)
+	source.Close()
+	<-done
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if len(events[0].Attachments) != 1 {
+		t.Fatalf("Expected 1 attachment, got %d", len(events[0].Attachments))
+	}
+	a := events[0].Attachments[0]
+	if a.Filename != "context.log" {
+		t.Errorf("Expected attachment context.log, got %q", a.Filename)
+	}
+	want := "request 2 ok\nopening db [REDACTED]\nFATAL out of memory\n  at alloc()\n  at main()\n"
+	if string(a.Payload) != want {
+		t.Errorf("Expected the surrounding lines, redacted:\n%q\ngot:\n%q", want, a.Payload)
+	}
+}
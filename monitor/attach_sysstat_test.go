@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/sysstat"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorOmitSysstat(t *testing.T) {
+	collector := sysstat.New()
+
+	for _, omit := range []bool{false, true} {
+		transport := &MockTransport{}
+		if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+			t.Fatalf("Failed to init sentry: %v", err)
+		}
+
+		mon, err := New(context.Background(), &MockSource{content: "GET /api 500\n"}, &MockDetector{}, collector, Options{
+			OmitSysstat: omit,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create monitor: %v", err)
+		}
+		mon.StopOnEOF = true
+		mon.Start()
+		sentry.Flush(time.Second)
+
+		events := snapshotEvents(transport)
+		if len(events) != 1 {
+			t.Fatalf("OmitSysstat=%v: expected 1 event, got %d", omit, len(events))
+		}
+		if _, got := events[0].Contexts["Server State"]; got == omit {
+			t.Errorf("OmitSysstat=%v: Server State attached = %v", omit, got)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorBatchSeparatorAndOrder(t *testing.T) {
+	input := "[100.0] error one\n[100.5] error two\n[101.0] error three\n"
+
+	testCases := []struct {
+		name      string
+		separator string
+		order     string
+		expected  string
+	}{
+		{
+			name:     "Default",
+			expected: "[100.0] error one\n[100.5] error two\n[101.0] error three",
+		},
+		{
+			name:      "Separator",
+			separator: "\n---\n",
+			expected:  "[100.0] error one\n---\n[100.5] error two\n---\n[101.0] error three",
+		},
+		{
+			name:     "Newest First",
+			order:    "desc",
+			expected: "[101.0] error three\n[100.5] error two\n[100.0] error one",
+		},
+		{
+			name:      "Separator Newest First",
+			separator: " | ",
+			order:     "desc",
+			expected:  "[101.0] error three | [100.5] error two | [100.0] error one",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+			mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{
+				BatchSeparator: tc.separator,
+				BatchOrder:     tc.order,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+			sentry.Flush(time.Second)
+
+			events := snapshotEvents(transport)
+			if len(events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(events))
+			}
+			if events[0].Message != tc.expected {
+				t.Errorf("Expected message %q, got %q", tc.expected, events[0].Message)
+			}
+		})
+	}
+}
+
+func TestMonitorInvalidBatchOrder(t *testing.T) {
+	if _, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{BatchOrder: "newest"}); err == nil {
+		t.Error("Expected an error for an unknown batch_order")
+	}
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/metrics"
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type batchMetricsSource struct{ MockSource }
+
+func (s *batchMetricsSource) Name() string { return "batch_metrics" }
+
+func TestBatchMetrics(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Groups of 1, 3 and 12 lines, 100s apart; the last is split at
+	// max_batch_lines into batches of 10 and 2
+	var input strings.Builder
+	for g, n := range []int{1, 3, 12} {
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&input, "[%d.0] error %d\n", 100*(g+1), i)
+		}
+	}
+	source := &batchMetricsSource{MockSource{content: input.String()}}
+	mon, err := New(context.Background(), source, &MockDetector{}, nil, Options{
+		MaxBatchLines: 10,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	if got := len(snapshotEvents(transport)); got != 4 {
+		t.Fatalf("Expected 4 events, got %d", got)
+	}
+
+	var m dto.Metric
+	if err := metrics.BatchLines.With(prometheus.Labels{"source": "batch_metrics"}).(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+	h := m.GetHistogram()
+	if h.GetSampleCount() != 4 || h.GetSampleSum() != 16 {
+		t.Errorf("Expected 4 batches of 16 lines in all, got %d of %v", h.GetSampleCount(), h.GetSampleSum())
+	}
+	want := map[float64]uint64{1: 1, 2: 2, 5: 3, 10: 4, 25: 4}
+	for _, b := range h.GetBucket() {
+		if n, ok := want[b.GetUpperBound()]; ok && b.GetCumulativeCount() != n {
+			t.Errorf("Bucket le=%v: expected %d batches, got %d", b.GetUpperBound(), n, b.GetCumulativeCount())
+		}
+	}
+
+	reasons := map[string]float64{flushTimeWindow: 2, flushSize: 1, flushForce: 1, flushTimer: 0}
+	for reason, want := range reasons {
+		var c dto.Metric
+		if err := metrics.BatchFlushesTotal.WithLabelValues("batch_metrics", reason).Write(&c); err != nil {
+			t.Fatalf("Failed to read metric: %v", err)
+		}
+		if got := c.GetCounter().GetValue(); got != want {
+			t.Errorf("Expected %v flushes for %s, got %v", want, reason, got)
+		}
+	}
+}
@@ -126,3 +126,42 @@ func BenchmarkProcessMatchNginx(b *testing.B) {
 		mon.processMatch(lineBytes)
 	}
 }
+
+// The ignore_substrings pre-filter against the equivalent exclude regex, on
+// lines that mostly match neither.
+func BenchmarkIgnoreSubstrings(b *testing.B) {
+	lines := [][]byte{
+		[]byte("2023-01-01 12:00:00 INFO GET /api/users 200 12ms"),
+		[]byte("2023-01-01 12:00:00 ERROR GET /healthz 503 1ms"),
+		[]byte("2023-01-01 12:00:00 WARN slow query on orders table took 1200ms"),
+		[]byte("2023-01-01 12:00:00 INFO kube-probe/1.27 GET /ready 200 0ms"),
+	}
+
+	b.Run("Substrings", func(b *testing.B) {
+		mon, err := New(context.Background(), &MockSource{}, nil, nil, Options{
+			IgnoreSubstrings: []string{"/healthz", "kube-probe", "/metrics"},
+		})
+		if err != nil {
+			b.Fatalf("Failed to create monitor: %v", err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, line := range lines {
+				_ = mon.ignored(line)
+			}
+		}
+	})
+
+	b.Run("ExcludeRegex", func(b *testing.B) {
+		det, err := detectors.NewGenericDetector(`/healthz|kube-probe|/metrics`)
+		if err != nil {
+			b.Fatalf("Failed to create detector: %v", err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, line := range lines {
+				_ = det.Detect(line)
+			}
+		}
+	})
+}
@@ -95,3 +95,97 @@ func TestBufferByteLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestMaxBatchLines(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// 5 lines in one time window are split into batches of at most 2
+	var input strings.Builder
+	for i := 0; i < 5; i++ {
+		input.WriteString("[100.0] error\n")
+	}
+	mon, err := New(context.Background(), &MockSource{content: input.String()}, &MockDetector{}, nil, Options{MaxBatchLines: 2})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+	for i, want := range []int{2, 2, 1} {
+		if got := strings.Count(events[i].Message, "\n") + 1; got != want {
+			t.Errorf("Event %d: expected %d lines, got %d", i, want, got)
+		}
+	}
+}
+
+func TestMaxBatchBytes(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Each line is 20 bytes, so a 50 byte cap fits two
+	input := "[100.0] error line 1\n[100.0] error line 2\n[100.0] error line 3\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{MaxBatchBytes: 50})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].Message != "[100.0] error line 1\n[100.0] error line 2" {
+		t.Errorf("Unexpected first event %q", events[0].Message)
+	}
+}
+
+func TestMaxBatchAge(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// A steady stream of untimestamped lines never pauses for FlushInterval
+	source := NewMockPipeSource()
+	mon, err := New(context.Background(), source, &MockDetector{}, nil, Options{MaxBatchAge: "300ms"})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	done := make(chan struct{})
+	go func() {
+		mon.Start()
+		close(done)
+	}()
+
+	start := time.Now()
+	for time.Since(start) < time.Second {
+		source.Write([]byte("error: still failing\n"))
+		time.Sleep(20 * time.Millisecond)
+	}
+	sentry.Flush(time.Second)
+	// Batches flushed at their max age while lines kept coming
+	events := snapshotEvents(transport)
+	if len(events) < 2 {
+		t.Fatalf("Expected batches to be sent every 300ms during the stream, got %d events", len(events))
+	}
+	// About 15 lines arrive in 300ms
+	if lines := strings.Count(events[0].Message, "\n") + 1; lines > 25 {
+		t.Errorf("Expected the first batch to be cut at its max age, got %d lines", lines)
+	}
+
+	source.Close()
+	<-done
+}
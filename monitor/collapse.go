@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// CollapseRepeatsDelay bounds how long Options.CollapseRepeats holds a line
+// back to count its repeats.
+var CollapseRepeatsDelay = time.Second
+
+// lineScanner is what the scan loop reads lines with: an offsetScanner from
+// newLineScanner, or a collapseScanner.
+type lineScanner interface {
+	Scan() bool
+	Bytes() []byte
+	Offset() int64
+	Err() error
+}
+
+// collapsedLine is a line, where its first copy starts, and how many times
+// in a row it was read.
+type collapsedLine struct {
+	line   []byte
+	offset int64
+	count  int
+}
+
+// collapseScanner scans the lines of a reader with each run of byte-identical
+// consecutive lines collapsed into one, like syslog's "last message repeated
+// N times" but without a second line. The line is passed on unchanged, so
+// formats such as JSON still parse, and Count gives the length of its run.
+// A line is passed on once a different line arrives, the reader ends, or
+// CollapseRepeatsDelay has passed since the run started; a run still going
+// on then starts a new count, so a line repeated forever is still reported.
+type collapseScanner struct {
+	lines chan collapsedLine
+	cur   collapsedLine
+	err   error // set before lines is closed
+}
+
+// newCollapseScanner starts scanning r, truncating lines too long for the
+// scan loop as newLineScanner does. Errors of r, such as errSourceStalled,
+// are returned by Err after the held line. r is read until it ends.
+func newCollapseScanner(r io.Reader, truncated func()) *collapseScanner {
+	s := &collapseScanner{lines: make(chan collapsedLine)}
+	read := make(chan collapsedLine)
+	go func() {
+		defer close(read)
+		scanner := newLineScanner(r, truncated)
+		for scanner.Scan() {
+			read <- collapsedLine{line: bytes.Clone(scanner.Bytes()), offset: scanner.Offset(), count: 1}
+		}
+		s.err = scanner.Err()
+	}()
+
+	go func() {
+		defer close(s.lines)
+		var (
+			run     collapsedLine
+			timer   *time.Timer
+			expired <-chan time.Time
+		)
+		flush := func() {
+			if run.count == 0 {
+				return
+			}
+			s.lines <- run
+			run = collapsedLine{}
+			timer.Stop()
+			expired = nil
+		}
+		for {
+			select {
+			case line, ok := <-read:
+				if !ok {
+					flush()
+					return
+				}
+				if run.count > 0 && bytes.Equal(line.line, run.line) {
+					run.count++
+					continue
+				}
+				flush()
+				run = line
+				timer = time.NewTimer(CollapseRepeatsDelay)
+				expired = timer.C
+			case <-expired:
+				flush()
+			}
+		}
+	}()
+	return s
+}
+
+func (s *collapseScanner) Scan() bool {
+	var ok bool
+	s.cur, ok = <-s.lines
+	return ok
+}
+
+func (s *collapseScanner) Bytes() []byte { return s.cur.line }
+
+// Offset returns where the first copy of the current line starts.
+func (s *collapseScanner) Offset() int64 { return s.cur.offset }
+
+// Count returns how many times in a row the current line was read.
+func (s *collapseScanner) Count() int { return s.cur.count }
+
+func (s *collapseScanner) Err() error { return s.err }
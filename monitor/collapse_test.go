@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorCollapseRepeats(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// JSON lines must still parse when collapsed
+	det, err := detectors.GetDetector("json", "level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	diskFull := `{"level":"error","msg":"disk full"}` + "\n"
+	input := strings.Repeat(diskFull, 5) + `{"level":"error","msg":"other"}` + "\n" + strings.Repeat(diskFull, 2)
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		CollapseRepeats: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	var matched []string
+	mon.OnMatch = func(line []byte) { matched = append(matched, string(line)) }
+	mon.StopOnEOF = true
+	mon.Start()
+
+	if len(matched) != 3 {
+		t.Fatalf("Expected 3 collapsed lines to match, got %q", matched)
+	}
+	for _, line := range matched {
+		if strings.Contains(line, "repeated") {
+			t.Errorf("Expected the line to be passed on unchanged, got %q", line)
+		}
+	}
+
+	// Without OnMatch, the repeats are counted on the event
+	mon, err = New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		CollapseRepeats: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	event := transport.events[0]
+	if got := event.Extra["repeated_lines"]; got != 5 {
+		t.Errorf("Expected repeated_lines 5 (4 + 1), got %v", got)
+	}
+	if event.Contexts["Log Data"]["msg"] != "disk full" {
+		t.Errorf("Expected the collapsed JSON line's fields to be extracted, got %v", event.Contexts["Log Data"])
+	}
+}
+
+func TestCollapseScannerDelay(t *testing.T) {
+	defer func(d time.Duration) { CollapseRepeatsDelay = d }(CollapseRepeatsDelay)
+	CollapseRepeatsDelay = 20 * time.Millisecond
+
+	src, w := io.Pipe()
+	defer w.Close()
+	s := newCollapseScanner(src, nil)
+
+	type result struct {
+		line  string
+		count int
+	}
+	lines := make(chan result)
+	go func() {
+		for s.Scan() {
+			lines <- result{string(s.Bytes()), s.Count()}
+		}
+	}()
+
+	// The held line is passed on without waiting for the next line
+	for range 3 {
+		if _, err := io.WriteString(w, "error: disk full\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case got := <-lines:
+		if got.line != "error: disk full" || got.count != 3 {
+			t.Errorf("Expected the line with a count of 3, got %q with %d", got.line, got.count)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the held line")
+	}
+}
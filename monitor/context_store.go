@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+)
+
+// DefaultContextWithin is how long fields captured by context_pattern are kept
+// when context_within is not set.
+const DefaultContextWithin = time.Minute
+
+// contextStore remembers the named groups captured from lines matching the
+// context pattern, so later events can carry them (e.g. the request_id of a
+// preceding "request started" line). Fields expire once no context line has
+// been seen for the window. It is only used from the scan loop, so it needs no
+// locking.
+type contextStore struct {
+	detector *detectors.GenericDetector
+	within   time.Duration
+
+	fields  map[string]interface{}
+	updated time.Time // time of the last context line
+}
+
+func newContextStore(pattern string, within time.Duration) (*contextStore, error) {
+	d, err := detectors.NewGenericDetector(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if within <= 0 {
+		within = DefaultContextWithin
+	}
+	return &contextStore{detector: d, within: within}, nil
+}
+
+// feed expires stale fields as of t and records the captures of a context line.
+// Newer captures overwrite older values of the same name.
+func (s *contextStore) feed(line []byte, t time.Time) {
+	if s.fields != nil && t.Sub(s.updated) > s.within {
+		s.fields = nil
+	}
+
+	captures := s.detector.GetContext(line)
+	if len(captures) == 0 {
+		return
+	}
+	if s.fields == nil {
+		s.fields = make(map[string]interface{}, len(captures))
+	}
+	for k, v := range captures {
+		s.fields[k] = v
+	}
+	s.updated = t
+}
+
+// merge returns ctx with the remembered fields added. Fields extracted from the
+// event's own line take precedence.
+func (s *contextStore) merge(ctx map[string]interface{}) map[string]interface{} {
+	if len(s.fields) == 0 {
+		return ctx
+	}
+	merged := make(map[string]interface{}, len(s.fields)+len(ctx))
+	for k, v := range s.fields {
+		merged[k] = v
+	}
+	for k, v := range ctx {
+		merged[k] = v
+	}
+	return merged
+}
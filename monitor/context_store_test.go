@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestContextPattern(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		within        string
+		expectRequest string
+	}{
+		{
+			name: "Preceding Context Line",
+			input: "2024-01-01T00:00:00Z request started request_id=abc123\n" +
+				"2024-01-01T00:00:01Z Error: db timeout\n",
+			expectRequest: "abc123",
+		},
+		{
+			name: "Latest Context Line Wins",
+			input: "2024-01-01T00:00:00Z request started request_id=abc123\n" +
+				"2024-01-01T00:00:01Z request started request_id=def456\n" +
+				"2024-01-01T00:00:02Z Error: db timeout\n",
+			expectRequest: "def456",
+		},
+		{
+			name: "Expired",
+			input: "2024-01-01T00:00:00Z request started request_id=abc123\n" +
+				"2024-01-01T00:05:00Z Error: db timeout\n",
+			within: "1m",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+
+			detector, err := detectors.NewGenericDetector("Error")
+			if err != nil {
+				t.Fatalf("Failed to create detector: %v", err)
+			}
+			mon, err := New(context.Background(), &MockSource{content: tc.input}, detector, nil, Options{
+				ContextPattern: `request_id=(?P<request_id>\w+)`,
+				ContextWithin:  tc.within,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+
+			sentry.Flush(time.Second)
+
+			transport.mu.Lock()
+			defer transport.mu.Unlock()
+
+			if len(transport.events) != 1 {
+				t.Fatalf("Expected 1 event (context lines must not trigger), got %d", len(transport.events))
+			}
+			event := transport.events[0]
+			if !strings.HasSuffix(event.Message, "Error: db timeout") {
+				t.Errorf("Unexpected message %q", event.Message)
+			}
+			got, _ := event.Contexts["Log Data"]["request_id"].(string)
+			if got != tc.expectRequest {
+				t.Errorf("Expected request_id %q, got %q (contexts: %v)", tc.expectRequest, got, event.Contexts)
+			}
+		})
+	}
+}
+
+func TestContextPatternRedacted(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+	detector, err := detectors.NewGenericDetector("Error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	// The capture alone ("hunter2") doesn't match the redact pattern, only the line does
+	input := "2024-01-01T00:00:00Z login user=bob password=hunter2\n" +
+		"2024-01-01T00:00:01Z Error: session expired\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, detector, nil, Options{
+		ContextPattern: `user=(?P<user>\w+) (?:password=(?P<password>\S+))?`,
+		Redact:         []string{`password=\S+`},
+		MessageFrom:    "password",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	logData := events[0].Contexts["Log Data"]
+	if logData["user"] != "bob" {
+		t.Errorf("Expected user bob in Log Data, got %v", logData)
+	}
+	if pw, ok := logData["password"]; ok && pw != "" {
+		t.Errorf("Expected no password captured from the redacted line, got %v", pw)
+	}
+	if strings.Contains(events[0].Message, "hunter2") {
+		t.Errorf("Expected the secret to stay out of the message, got %q", events[0].Message)
+	}
+}
+
+func TestContextStoreMergePrecedence(t *testing.T) {
+	store, err := newContextStore(`request_id=(?P<request_id>\w+) user=(?P<user>\w+)`, 0)
+	if err != nil {
+		t.Fatalf("newContextStore failed: %v", err)
+	}
+	store.feed([]byte("request_id=abc user=alice"), time.Now())
+
+	merged := store.merge(map[string]interface{}{"user": "bob"})
+	if merged["request_id"] != "abc" {
+		t.Errorf("Expected remembered request_id, got %v", merged)
+	}
+	if merged["user"] != "bob" {
+		t.Errorf("Expected the event's own user to win, got %v", merged["user"])
+	}
+}
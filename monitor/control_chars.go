@@ -0,0 +1,34 @@
+package monitor
+
+// escapeControlChars returns line with ASCII control characters and DEL
+// replaced by their \xNN form, e.g. a tab by \x09 and a bell by \x07, since
+// they render badly in Sentry and can be used to forge log output. Newlines
+// are kept, since lines read never contain one and they join the lines of a
+// batch. It is applied to what is sent, after context extraction, so formats
+// such as JSON still parse tabs as whitespace.
+func escapeControlChars(line []byte) []byte {
+	n := 0
+	for _, c := range line {
+		if isControlChar(c) {
+			n++
+		}
+	}
+	if n == 0 {
+		return line
+	}
+
+	const hex = "0123456789abcdef"
+	out := make([]byte, 0, len(line)+3*n)
+	for _, c := range line {
+		if isControlChar(c) {
+			out = append(out, '\\', 'x', hex[c>>4], hex[c&0xf])
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func isControlChar(c byte) bool {
+	return (c < 0x20 && c != '\n') || c == 0x7f
+}
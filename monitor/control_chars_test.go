@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestNormalizeControlChars(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Both lines are batched into one event.
+	input := "[100.0] error:\tdisk full\x07\n[101.0] error: retry failed\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{
+		NormalizeControlChars: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	want := `[100.0] error:\x09disk full\x07` + "\n" + `[101.0] error: retry failed`
+	if events[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, events[0].Message)
+	}
+}
+
+func TestEscapeControlCharsUnchanged(t *testing.T) {
+	line := []byte("plain line, no control characters: ünïcödé")
+	if got := escapeControlChars(line); &got[0] != &line[0] {
+		t.Error("Expected a line without control characters to be returned as is")
+	}
+}
+
+func TestNormalizeControlCharsAfterExtraction(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, err := detectors.GetDetector("json", "level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	// Tabs as JSON whitespace, and one escaped in a value
+	input := "{\"level\":\t\"error\",\t\"msg\": \"disk\\tfull\"}\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		NormalizeControlChars: true,
+		GroupBy:               "msg",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if got := events[0].Contexts["Log Data"]["msg"]; got != "disk\tfull" {
+		t.Errorf("Expected the JSON fields to be extracted, got msg %q", got)
+	}
+	if want := `{"level":\x09"error",\x09"msg": "disk\tfull"}`; events[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, events[0].Message)
+	}
+}
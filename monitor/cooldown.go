@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// cooldown makes reporting edge-triggered: the first event fires, and events
+// in the following period are only counted. If any were counted, one summary
+// is sent when the period ends and a new period starts, so a flapping source
+// produces one alert plus a summary per period instead of an event per flap.
+type cooldown struct {
+	period time.Duration
+
+	mu         sync.Mutex
+	active     bool
+	suppressed int // matched lines held back in the current period
+	timer      *time.Timer
+}
+
+// cooldownAllow reports whether an event holding the given number of lines
+// may be sent, starting a cooldown period if it may. Suppressed lines are
+// counted for the summary.
+func (m *Monitor) cooldownAllow(lines int) bool {
+	c := m.cooldown
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.active {
+		c.active = true
+		c.timer = time.AfterFunc(c.period, func() { m.endCooldown(true) })
+		return true
+	}
+	c.suppressed += lines
+	m.metricCooldownSuppressed.Inc()
+	return false
+}
+
+// endCooldown ends the current period, sending a summary if anything was
+// suppressed. With restart set, a summary starts a new period; otherwise
+// (the monitor is stopping) the cooldown is left idle.
+func (m *Monitor) endCooldown(restart bool) {
+	c := m.cooldown
+	c.mu.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	n := c.suppressed
+	c.suppressed = 0
+	if n == 0 || !restart {
+		c.active = false
+		c.timer = nil
+	} else {
+		c.timer = time.AfterFunc(c.period, func() { m.endCooldown(true) })
+	}
+	c.mu.Unlock()
+
+	if n == 0 {
+		return
+	}
+	if m.Verbose {
+		log.Printf("[%s] Cooldown ended with %d suppressed matches, sending summary.", m.Source.Name(), n)
+	}
+	m.sendAlert("cooldown_summary", sentry.LevelWarning,
+		fmt.Sprintf("%d more matches on %s during the %v cooldown after the last alert", n, m.Source.Name(), c.period))
+}
@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// snapshotEvents returns a copy of the events captured so far.
+func snapshotEvents(tr *MockTransport) []*sentry.Event {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return append([]*sentry.Event(nil), tr.events...)
+}
+
+func TestCooldownSummary(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	mon, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{
+		Cooldown: "200ms",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	// A burst: the first event fires, the rest are counted
+	mon.sendToSentry("error: link down", BatchMetadata{})
+	mon.sendToSentry("error: link down\nerror: link down", BatchMetadata{Lines: 2})
+	// One line that spans two, e.g. a decoded stack trace, counts once
+	mon.sendToSentry("error: link down\n  at probe()", BatchMetadata{Lines: 1})
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 || events[0].Message != "error: link down" {
+		t.Fatalf("Expected only the first event during the burst, got %d", len(events))
+	}
+
+	// The cooldown ends with a summary of the 3 suppressed lines
+	time.Sleep(300 * time.Millisecond)
+	sentry.Flush(time.Second)
+	events = snapshotEvents(transport)
+	if len(events) != 2 {
+		t.Fatalf("Expected a summary after cooldown, got %d events", len(events))
+	}
+	summary := events[1]
+	if summary.Tags["alert_type"] != "cooldown_summary" {
+		t.Errorf("Expected alert_type cooldown_summary, got %q", summary.Tags["alert_type"])
+	}
+	if !strings.HasPrefix(summary.Message, "3 more matches on mock") {
+		t.Errorf("Unexpected summary message %q", summary.Message)
+	}
+
+	// The summary starts another period, which ends quietly; then the next match fires again
+	time.Sleep(300 * time.Millisecond)
+	mon.sendToSentry("error: link down again", BatchMetadata{})
+	sentry.Flush(time.Second)
+	events = snapshotEvents(transport)
+	if len(events) != 3 || events[2].Message != "error: link down again" {
+		t.Errorf("Expected a new alert after a quiet cooldown, got %d events", len(events))
+	}
+}
+
+func TestCooldownSummaryAtEOF(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// More than 5s apart, so each line is its own event
+	input := "[100.0] error one\n[110.0] error two\n[120.0] error three\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{
+		Cooldown: "1h",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 2 {
+		t.Fatalf("Expected the first event and a summary, got %d", len(events))
+	}
+	if !strings.HasPrefix(events[1].Message, "2 more matches") {
+		t.Errorf("Unexpected summary message %q", events[1].Message)
+	}
+}
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+// statusDetector reports lines with a 5xx "status=" value.
+type statusDetector struct{}
+
+func (d *statusDetector) Detect(line []byte) bool {
+	i := bytes.Index(line, []byte("status="))
+	return i >= 0 && len(line) > i+7 && line[i+7] == '5'
+}
+
+func TestRegisteredDetector(t *testing.T) {
+	detectors.Register("status5xx", func(string) (detectors.Detector, error) {
+		return &statusDetector{}, nil
+	})
+
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, err := detectors.GetDetector("status5xx", "")
+	if err != nil {
+		t.Fatalf("GetDetector failed: %v", err)
+	}
+	input := "GET / status=200\nGET /api status=502\nGET /health status=204\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != 1 || transport.events[0].Message != "GET /api status=502" {
+		t.Errorf("Expected one event for the 5xx line, got %d", len(transport.events))
+	}
+}
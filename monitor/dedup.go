@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"log"
+	"sync"
+)
+
+// lifetimeDedup sends one event per distinct message for the life of the
+// monitor: later events with a message already seen are only counted. Unlike
+// a cooldown, it never expires. At most max messages are remembered; the
+// least recently seen one is forgotten to make room, and is sent again if it
+// comes back.
+type lifetimeDedup struct {
+	max int
+
+	mu   sync.Mutex
+	seen map[string]*dedupEntry
+	tick uint64 // orders entries by when they were last seen
+}
+
+type dedupEntry struct {
+	lastSeen   uint64
+	suppressed int
+}
+
+// dedupKey returns the message an event is deduplicated by: its fingerprint
+// with fingerprint_normalizers, and otherwise its first line.
+func (m *Monitor) dedupKey(line string, meta BatchMetadata) string {
+	if fp := m.eventFingerprint(line, meta); fp != "" {
+		return fp
+	}
+	return m.firstLine(line, meta)
+}
+
+// dedupAllow reports whether an event for line may be sent, which it may the
+// first time its message is seen.
+func (m *Monitor) dedupAllow(line string, meta BatchMetadata) bool {
+	d := m.dedup
+	key := m.dedupKey(line, meta)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tick++
+	if e, ok := d.seen[key]; ok {
+		e.lastSeen = d.tick
+		e.suppressed++
+		m.metricDedupSuppressed.Inc()
+		return false
+	}
+	if len(d.seen) >= d.max {
+		var oldest string
+		for k, e := range d.seen {
+			if oldest == "" || e.lastSeen < d.seen[oldest].lastSeen {
+				oldest = k
+			}
+		}
+		if m.Verbose {
+			log.Printf("[%s] Forgetting deduplicated message after %d repeats: %s", m.Source.Name(), d.seen[oldest].suppressed, oldest)
+		}
+		delete(d.seen, oldest)
+	}
+	d.seen[key] = &dedupEntry{lastSeen: d.tick}
+	return true
+}
@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestDedupLifetime(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// More than 5s apart, so each line is its own event; hours apart, so no
+	// windowed suppression would hold them back
+	input := "[100.0] worker 1 crashed\n[3700.0] disk full\n[7300.0] worker 7 crashed\n[10900.0] worker 1 crashed\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{
+		DedupLifetime:          true,
+		FingerprintNormalizers: []string{"numbers"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 2 {
+		t.Fatalf("Expected one event per distinct message, got %d", len(events))
+	}
+	if events[0].Message != "[100.0] worker 1 crashed" || events[1].Message != "[3700.0] disk full" {
+		t.Errorf("Unexpected events %q, %q", events[0].Message, events[1].Message)
+	}
+	if got := mon.dedup.seen["worker # crashed"].suppressed; got != 2 {
+		t.Errorf("Expected 2 suppressed repeats, got %d", got)
+	}
+}
+
+func TestDedupLifetimeForgetsLeastRecentlySeen(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	mon, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{
+		DedupLifetime:   true,
+		MaxFingerprints: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	for _, line := range []string{"error a", "error b", "error a", "error c", "error a", "error b"} {
+		mon.sendToSentry(line, BatchMetadata{})
+	}
+	sentry.Flush(time.Second)
+
+	// "error c" makes room by forgetting "error b", so it is sent again
+	var got []string
+	for _, e := range snapshotEvents(transport) {
+		got = append(got, e.Message)
+	}
+	want := []string{"error a", "error b", "error c", "error b"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected events %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected events %q, got %q", want, got)
+			break
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorDeployInfo(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, err := detectors.NewGenericDetector("error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	mon, err := New(context.Background(), &MockSource{content: "[100.0] error\n"}, det, nil, Options{
+		DeployInfo: map[string]interface{}{
+			"commit":   "4673c66",
+			"deployer": "ci",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	deploy, ok := transport.events[0].Contexts["Deploy"]
+	if !ok {
+		t.Fatal("Expected 'Deploy' context")
+	}
+	if deploy["commit"] != "4673c66" || deploy["deployer"] != "ci" {
+		t.Errorf("Expected the deploy info in the Deploy context, got %v", deploy)
+	}
+}
@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// DefaultMaxEventBytes is the default limit on the estimated serialized event size.
+// Sentry rejects events over ~1MB with HTTP 413, which the SDK drops silently.
+const DefaultMaxEventBytes = 1000 * 1000
+
+const truncatedSuffix = "\n... [truncated]"
+
+// eventSize estimates the serialized size of an event, including attachment payloads.
+func eventSize(event *sentry.Event) int {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return 0
+	}
+	n := len(b)
+	for _, a := range event.Attachments {
+		n += len(a.Payload)
+	}
+	return n
+}
+
+// trimEvent sheds optional parts of an event until it fits within maxBytes:
+// attachments first, then the "Server State" context, then the message and
+// raw_line extra are truncated, and finally the "Monitor Info" and "Log Data"
+// contexts are dropped. It reports whether anything was trimmed, and whether
+// the event fits now; one that doesn't, e.g. because of huge tags, should be
+// dropped rather than sent to be rejected.
+func trimEvent(event *sentry.Event, maxBytes int) (trimmed, fits bool) {
+	size := eventSize(event)
+	if size <= maxBytes {
+		return false, true
+	}
+
+	if len(event.Attachments) > 0 {
+		event.Attachments = nil
+		if size = eventSize(event); size <= maxBytes {
+			return true, true
+		}
+	}
+
+	if _, ok := event.Contexts["Server State"]; ok {
+		delete(event.Contexts, "Server State")
+		if size = eventSize(event); size <= maxBytes {
+			return true, true
+		}
+	}
+
+	// JSON escaping may make the estimate shrink by less than the bytes cut, so retry a few times.
+	for i := 0; i < 3 && size > maxBytes; i++ {
+		excess := size - maxBytes + len(truncatedSuffix)
+		event.Message = truncateString(event.Message, len(event.Message)-excess)
+		if raw, ok := event.Extra["raw_line"].(string); ok {
+			event.Extra["raw_line"] = truncateString(raw, len(raw)-excess)
+		}
+		size = eventSize(event)
+	}
+
+	// The contexts are what is left when they dominate, e.g. a huge JSON field
+	for _, name := range []string{"Monitor Info", "Log Data"} {
+		if size <= maxBytes {
+			break
+		}
+		if _, ok := event.Contexts[name]; ok {
+			delete(event.Contexts, name)
+			size = eventSize(event)
+		}
+	}
+	return true, size <= maxBytes
+}
+
+// truncateString cuts s to at most n bytes on a rune boundary and marks it as truncated.
+func truncateString(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	if n < 0 {
+		n = 0
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + truncatedSuffix
+}
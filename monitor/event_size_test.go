@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/metrics"
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func hugeEvent(messageSize int) *sentry.Event {
+	msg := strings.Repeat("x", messageSize)
+	return &sentry.Event{
+		Message: msg,
+		Extra:   map[string]interface{}{"raw_line": msg},
+		Contexts: map[string]sentry.Context{
+			"Server State": {"processes": strings.Repeat("p", 50*1024)},
+			"Log Data":     {"request_id": "abc"},
+		},
+		Attachments: []*sentry.Attachment{
+			{Filename: "dump.bin", Payload: make([]byte, 200*1024)},
+		},
+	}
+}
+
+func TestTrimEvent(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxBytes        int
+		wantTrimmed     bool
+		wantAttachments bool
+		wantServerState bool
+		wantTruncated   bool
+	}{
+		{"fits", 10 * 1024 * 1024, false, true, true, false},
+		{"drop attachments", 300 * 1024, true, false, true, false},
+		{"drop server state", 250 * 1024, true, false, false, false},
+		{"truncate message", 64 * 1024, true, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := hugeEvent(100 * 1024)
+
+			got, fits := trimEvent(event, tt.maxBytes)
+			if got != tt.wantTrimmed || !fits {
+				t.Errorf("trimEvent() = %v, %v, want %v, true", got, fits, tt.wantTrimmed)
+			}
+			if size := eventSize(event); size > tt.maxBytes {
+				t.Errorf("Event size %d exceeds limit %d", size, tt.maxBytes)
+			}
+			if got := len(event.Attachments) > 0; got != tt.wantAttachments {
+				t.Errorf("attachments kept = %v, want %v", got, tt.wantAttachments)
+			}
+			if _, got := event.Contexts["Server State"]; got != tt.wantServerState {
+				t.Errorf("Server State kept = %v, want %v", got, tt.wantServerState)
+			}
+			if got := strings.HasSuffix(event.Message, truncatedSuffix); got != tt.wantTruncated {
+				t.Errorf("message truncated = %v, want %v", got, tt.wantTruncated)
+			}
+			if _, ok := event.Contexts["Log Data"]; !ok {
+				t.Error("Log Data context should be kept while the message can be truncated")
+			}
+		})
+	}
+}
+
+func TestTrimEventContextsDominate(t *testing.T) {
+	const maxBytes = 16 * 1024
+	event := &sentry.Event{
+		Message: "error: payload rejected",
+		Contexts: map[string]sentry.Context{
+			"Monitor Info": {"team": "payments"},
+			"Log Data":     {"payload": strings.Repeat("z", 64*1024)},
+		},
+	}
+	trimmed, fits := trimEvent(event, maxBytes)
+	if !trimmed || !fits {
+		t.Fatalf("trimEvent() = %v, %v, want true, true", trimmed, fits)
+	}
+	if size := eventSize(event); size > maxBytes {
+		t.Errorf("Event size %d exceeds limit %d", size, maxBytes)
+	}
+	if _, ok := event.Contexts["Log Data"]; ok {
+		t.Error("Expected the oversized Log Data context to be shed")
+	}
+
+	// Nothing optional is left to shed when the tags alone are too big
+	event = &sentry.Event{
+		Message: "error",
+		Tags:    map[string]string{"payload": strings.Repeat("z", 64*1024)},
+	}
+	if trimmed, fits := trimEvent(event, maxBytes); !trimmed || fits {
+		t.Errorf("trimEvent() = %v, %v, want true, false", trimmed, fits)
+	}
+}
+
+func TestTruncateStringRuneBoundary(t *testing.T) {
+	got := truncateString("héllo", 2) // cut falls inside 'é'
+	if got != "h"+truncatedSuffix {
+		t.Errorf("Expected cut on rune boundary, got %q", got)
+	}
+}
+
+func TestMonitorOversizeEventTrimmed(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, err := detectors.NewGenericDetector("error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	const maxBytes = 16 * 1024
+	input := "error " + strings.Repeat("y", 64*1024) + "\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		MaxEventBytes: maxBytes,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+
+	var before dto.Metric
+	counter := metrics.OversizeTrimmedTotal.With(prometheus.Labels{"source": "mock"})
+	if err := counter.Write(&before); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	event := transport.events[0]
+	if size := eventSize(event); size > maxBytes {
+		t.Errorf("Event size %d exceeds limit %d", size, maxBytes)
+	}
+	if !strings.HasSuffix(event.Message, truncatedSuffix) {
+		t.Error("Expected message to be truncated")
+	}
+
+	var after dto.Metric
+	if err := counter.Write(&after); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+	if after.GetCounter().GetValue()-before.GetCounter().GetValue() != 1 {
+		t.Errorf("Expected oversize_trimmed metric to increase by 1")
+	}
+}
+
+func TestMonitorOversizeEventDropped(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+	det, err := detectors.NewJsonDetector("level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	// The fingerprint can't be shed, so the event can't be made to fit
+	const maxBytes = 16 * 1024
+	input := `{"level":"error","msg":"rejected","payload":"` + strings.Repeat("z", 64*1024) + `"}` + "\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		MaxEventBytes:   maxBytes,
+		FingerprintFrom: "payload",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+
+	var before dto.Metric
+	counter := metrics.OversizeTrimmedTotal.With(prometheus.Labels{"source": "mock"})
+	if err := counter.Write(&before); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	if events := snapshotEvents(transport); len(events) != 0 {
+		t.Fatalf("Expected the oversized event to be dropped, got %d events", len(events))
+	}
+	var after dto.Metric
+	if err := counter.Write(&after); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+	if after.GetCounter().GetValue()-before.GetCounter().GetValue() != 1 {
+		t.Errorf("Expected the dropped event to be counted in the oversize_trimmed metric")
+	}
+}
@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func countAlerts(transport *MockTransport, alertType string, level sentry.Level) int {
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	n := 0
+	for _, e := range transport.events {
+		if e.Tags["alert_type"] == alertType && e.Level == level {
+			n++
+		}
+	}
+	return n
+}
+
+func TestExpectedLineAlert(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantAlert bool
+	}{
+		{"expected line seen", "backup completed\n", false},
+		{"expected line missing", "backup started\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			err := sentry.Init(sentry.ClientOptions{
+				Transport: transport,
+			})
+			if err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+
+			source := NewMockPipeSource()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// The error detector never matches, so only the expected-line watchdog can send events
+			det, err := detectors.NewGenericDetector("FATAL")
+			if err != nil {
+				t.Fatalf("Failed to create detector: %v", err)
+			}
+			mon, err := New(ctx, source, det, nil, Options{
+				ExpectPattern: "backup completed",
+				ExpectWithin:  "300ms",
+			})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			go mon.Start()
+
+			// Feed the line every 100ms, well within the 300ms window
+			for i := 0; i < 8; i++ {
+				source.Write([]byte(tt.line))
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			got := countAlerts(transport, "missing_expected", sentry.LevelWarning)
+			if tt.wantAlert && got != 1 {
+				t.Errorf("Expected 1 missing_expected alert, got %d", got)
+			}
+			if !tt.wantAlert && got != 0 {
+				t.Errorf("Expected no missing_expected alert, got %d", got)
+			}
+
+			if tt.wantAlert {
+				// Seeing the expected line again resolves the alert
+				source.Write([]byte("backup completed\n"))
+				time.Sleep(300 * time.Millisecond)
+				if got := countAlerts(transport, "missing_expected", sentry.LevelInfo); got != 1 {
+					t.Errorf("Expected 1 recovery event, got %d", got)
+				}
+			}
+
+			source.Close()
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package monitor
+
+import (
+	"log"
+	"time"
+
+	"github.com/angch/sentrylogmon/exporters"
+	"github.com/getsentry/sentry-go"
+)
+
+// export hands an event that was sent to Sentry to the exporters. Errors are
+// logged; they don't affect the Sentry event.
+func (m *Monitor) export(message string, level sentry.Level) {
+	if len(m.exporters) == 0 {
+		return
+	}
+	if level == "" {
+		level = sentry.LevelInfo
+	}
+	ev := exporters.Event{
+		Time:    time.Now(),
+		Source:  m.Source.Name(),
+		Level:   string(level),
+		Message: message,
+	}
+	for _, e := range m.exporters {
+		if err := e.Export(ev); err != nil {
+			log.Printf("[%s] Failed to export event: %v", m.Source.Name(), err)
+		}
+	}
+}
+
+func (m *Monitor) closeExporters() {
+	for _, e := range m.exporters {
+		e.Close()
+	}
+}
@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/exporters"
+	"github.com/getsentry/sentry-go"
+)
+
+type recordingExporter struct {
+	mu     sync.Mutex
+	events []exporters.Event
+	closed bool
+}
+
+func (e *recordingExporter) Export(ev exporters.Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, ev)
+	return nil
+}
+
+func (e *recordingExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	return nil
+}
+
+func TestMonitorExporters(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	input := `{"level":"error","msg":"db down password=hunter2"}
+`
+	det, err := detectors.NewJsonDetector("level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	exp := &recordingExporter{}
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		Redact:    []string{`password=\w+`},
+		Exporters: []exporters.Exporter{exp},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	if got := len(snapshotEvents(transport)); got != 1 {
+		t.Fatalf("Expected 1 Sentry event, got %d", got)
+	}
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	if len(exp.events) != 1 {
+		t.Fatalf("Expected 1 exported event, got %+v", exp.events)
+	}
+	ev := exp.events[0]
+	if ev.Source != "mock" || ev.Level != "error" || ev.Message != `{"level":"error","msg":"db down [REDACTED]"}` {
+		t.Errorf("Unexpected exported event: %+v", ev)
+	}
+	if !exp.closed {
+		t.Error("Expected the exporter to be closed when the monitor stopped")
+	}
+}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func newMockHub(t *testing.T, transport *MockTransport) *sentry.Hub {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return sentry.NewHub(client, sentry.NewScope())
+}
+
+func TestMonitorFanOut(t *testing.T) {
+	team := &MockTransport{}
+	firehose := &MockTransport{}
+
+	input := "error one\nerror two\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{
+		RateLimitBurst:  1,
+		RateLimitWindow: "1m",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.Hub = newMockHub(t, team)
+	mon.mirrorHubs = []*sentry.Hub{newMockHub(t, firehose)}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	mon.Flush(time.Second)
+
+	for name, transport := range map[string]*MockTransport{"team": team, "firehose": firehose} {
+		transport.mu.Lock()
+		// The rate limiter is shared, so each target gets the same single event
+		if len(transport.events) != 1 {
+			t.Errorf("%s: expected 1 event, got %d", name, len(transport.events))
+		} else if transport.events[0].Tags["source"] != "mock" {
+			t.Errorf("%s: expected source tag, got %v", name, transport.events[0].Tags)
+		}
+		transport.mu.Unlock()
+	}
+}
+
+func TestMonitorSentryTargetsHubs(t *testing.T) {
+	target := SentryTarget{DSN: "http://public@127.0.0.1:1/2"}
+
+	tests := []struct {
+		name        string
+		opts        Options
+		wantMirrors int
+		wantCurrent bool
+	}{
+		{"default", Options{}, 0, true},
+		{"targets with global hub", Options{SentryTargets: []SentryTarget{target}}, 1, true},
+		{"targets with monitor DSN", Options{SentryDSN: "http://public@127.0.0.1:1/1", SentryTargets: []SentryTarget{target}}, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mon, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			if len(mon.mirrorHubs) != tt.wantMirrors {
+				t.Fatalf("Expected %d mirror hubs, got %d", tt.wantMirrors, len(mon.mirrorHubs))
+			}
+			if got := mon.Hub == sentry.CurrentHub(); got != tt.wantCurrent {
+				t.Errorf("Hub is global hub = %v, want %v", got, tt.wantCurrent)
+			}
+			if got := len(mon.hubs()); got != tt.wantMirrors+1 {
+				t.Errorf("Expected %d hubs in fan-out, got %d", tt.wantMirrors+1, got)
+			}
+		})
+	}
+}
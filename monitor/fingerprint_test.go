@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorFingerprintNormalizers(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Lines 10s apart are reported as separate events
+	input := `[100.0] error: worker 12 exited after 300ms
+[110.0] error: worker 7 exited after 45ms
+[120.0] error: disk   full
+`
+	det, err := detectors.NewGenericDetector("error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		FingerprintNormalizers: []string{"numbers", "whitespace"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+	want := []string{
+		"error: worker # exited after #ms",
+		"error: worker # exited after #ms",
+		"error: disk full",
+	}
+	for i, event := range events {
+		if len(event.Fingerprint) != 1 || event.Fingerprint[0] != want[i] {
+			t.Errorf("Event %d: expected fingerprint %q, got %q", i, want[i], event.Fingerprint)
+		}
+	}
+	// The message itself is reported unchanged
+	if events[0].Message != "[100.0] error: worker 12 exited after 300ms" {
+		t.Errorf("Unexpected message %q", events[0].Message)
+	}
+}
+
+func TestMonitorInvalidFingerprintNormalizer(t *testing.T) {
+	_, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{FingerprintNormalizers: []string{"digits"}})
+	if err == nil {
+		t.Error("Expected an error for an unknown fingerprint normalizer")
+	}
+}
+
+func TestMonitorFingerprintFrom(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// With MaxBatchLines 1, each line is its own event
+	input := `{"level":"error","msg":"upstream timed out","error_code":500}
+{"level":"error","msg":"database connection refused","error_code":500}
+{"level":"error","msg":"no error code here"}
+`
+	det, err := detectors.NewJsonDetector("level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		FingerprintFrom:        "error_code",
+		FingerprintNormalizers: []string{"numbers"},
+		MaxBatchLines:          1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+	for i := 0; i < 2; i++ {
+		if len(events[i].Fingerprint) != 1 || events[i].Fingerprint[0] != "500" {
+			t.Errorf("Event %d: expected fingerprint [500], got %q", i, events[i].Fingerprint)
+		}
+	}
+	// Without the key, the normalized first line is used
+	if fp := events[2].Fingerprint; len(fp) != 1 || fp[0] != `{"level":"error","msg":"no error code here"}` {
+		t.Errorf("Event 2: expected the normalized line as fingerprint, got %q", fp)
+	}
+}
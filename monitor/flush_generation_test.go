@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestFlushBufferIgnoresStaleGeneration(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, _ := detectors.NewGenericDetector("error")
+	mon, err := New(context.Background(), &MockSource{}, det, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	mon.processMatch([]byte("[100.0] error one"))
+	mon.bufferMutex.Lock()
+	b := mon.batches[""]
+	staleGen := b.gen
+	mon.bufferMutex.Unlock()
+
+	// A new line resets the timer, so a callback from the first timer is stale
+	mon.processMatch([]byte("[100.5] error two"))
+	mon.flushBuffer(b, staleGen)
+
+	mon.bufferMutex.Lock()
+	currentGen := b.gen
+	count := b.count
+	mon.bufferMutex.Unlock()
+	if count != 2 {
+		t.Fatalf("Stale flush must not take the batch, count = %d", count)
+	}
+
+	// The current generation flushes
+	mon.flushBuffer(b, currentGen)
+	// Firing the same generation again is a no-op
+	mon.flushBuffer(b, currentGen)
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	if want := "[100.0] error one\n[100.5] error two"; transport.events[0].Message != want {
+		t.Errorf("Expected %q, got %q", want, transport.events[0].Message)
+	}
+}
+
+func TestFlushBufferRapidResets(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, _ := detectors.NewGenericDetector("error")
+	mon, err := New(context.Background(), &MockSource{}, det, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	const total = 500
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; i++ {
+			mon.processMatch([]byte(fmt.Sprintf("[100.0] error %d", i)))
+		}
+	}()
+
+	// Concurrently fire callbacks as a timer that raced with a reset would:
+	// with the generation observed just before the next reset.
+	for i := 0; i < total; i++ {
+		mon.bufferMutex.Lock()
+		b := mon.batches[""]
+		var gen uint64
+		if b != nil {
+			gen = b.gen - 1
+		}
+		mon.bufferMutex.Unlock()
+		if b != nil {
+			mon.flushBuffer(b, gen)
+		}
+	}
+	wg.Wait()
+
+	mon.forceFlush()
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	// Stale callbacks never flush, so all lines arrive in a single event
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	if got := len(strings.Split(transport.events[0].Message, "\n")); got != total {
+		t.Errorf("Expected %d lines, got %d", total, got)
+	}
+}
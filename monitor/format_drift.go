@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+// FormatDriftWindow is how many lines Options.FormatDrift samples at a time.
+var FormatDriftWindow = 200
+
+const (
+	// A window is drifted when its rate of lines in the expected format falls
+	// below this fraction of the baseline...
+	formatDriftRatio = 0.5
+	// ... as long as the baseline is at least this, so sources that rarely
+	// parse (e.g. few lines with timestamps) don't alert on noise.
+	formatDriftMinBaseline = 0.2
+	// Weight of each healthy window in the baseline
+	formatDriftSmoothing = 0.2
+)
+
+// formatDrift tracks the share of lines in the format the detector expects,
+// to catch a source whose format changed (e.g. from plain text to JSON) and
+// which the pattern silently stopped matching. Only used from the scan loop.
+type formatDrift struct {
+	parses  func(line []byte) bool
+	format  string // what parses checks for, for the alert
+	lines   int
+	parsed  int
+	base    float64 // parse rate of the healthy windows so far; -1 before the first
+	drifted bool
+}
+
+// newFormatDrift checks lines with the detector's own format, if it has one,
+// and otherwise for a timestamp.
+func (m *Monitor) newFormatDrift() *formatDrift {
+	d := &formatDrift{base: -1}
+	if fc, ok := m.Detector.(detectors.FormatChecker); ok {
+		d.parses = fc.ParsesFormat
+		d.format = fc.FormatName()
+	} else {
+		d.parses = func(line []byte) bool {
+			ts, _ := m.lineTimestamp(line)
+			return ts != 0
+		}
+		d.format = "timestamped"
+	}
+	return d
+}
+
+// checkFormatDrift counts line and, at the end of each window, compares its
+// parse rate with the baseline, sending a warning (alert_type=format_drift)
+// once when it drops sharply.
+func (m *Monitor) checkFormatDrift(line []byte) {
+	d := m.formatDrift
+	d.lines++
+	if d.parses(line) {
+		d.parsed++
+	}
+	if d.lines < FormatDriftWindow {
+		return
+	}
+	rate := float64(d.parsed) / float64(d.lines)
+	d.lines, d.parsed = 0, 0
+
+	switch {
+	case d.base < 0:
+		d.base = rate
+	case d.base >= formatDriftMinBaseline && rate < d.base*formatDriftRatio:
+		if !d.drifted {
+			d.drifted = true
+			msg := fmt.Sprintf("Only %.0f%% of the last %d lines from %s are %s, down from %.0f%%. Has the log format changed? The pattern may no longer match.",
+				rate*100, FormatDriftWindow, m.Source.Name(), d.format, d.base*100)
+			log.Printf("[%s] %s", m.Source.Name(), msg)
+			m.sendAlert("format_drift", sentry.LevelWarning, msg)
+		}
+	default:
+		if d.drifted {
+			log.Printf("[%s] %.0f%% of lines are %s again.", m.Source.Name(), rate*100, d.format)
+			d.drifted = false
+		}
+		d.base += formatDriftSmoothing * (rate - d.base)
+	}
+}
@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestFormatDriftJSONToPlaintext(t *testing.T) {
+	prev := FormatDriftWindow
+	FormatDriftWindow = 10
+	t.Cleanup(func() { FormatDriftWindow = prev })
+
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// The team switched from JSON to plain text logging halfway through
+	var input strings.Builder
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&input, `{"level":"info","msg":"request %d"}`+"\n", i)
+	}
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&input, "INFO request %d\n", i)
+	}
+	det, err := detectors.NewJsonDetector("level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	mon, err := New(context.Background(), &MockSource{content: input.String()}, det, nil, Options{FormatDrift: true})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	if got := countAlerts(transport, "format_drift", sentry.LevelWarning); got != 1 {
+		t.Fatalf("Expected 1 format_drift warning, got %d", got)
+	}
+	events := snapshotEvents(transport)
+	if !strings.Contains(events[0].Message, "Only 0% of the last 10 lines from mock are JSON, down from 100%") {
+		t.Errorf("Unexpected alert message %q", events[0].Message)
+	}
+}
+
+func TestFormatDriftSteadyFormat(t *testing.T) {
+	prev := FormatDriftWindow
+	FormatDriftWindow = 10
+	t.Cleanup(func() { FormatDriftWindow = prev })
+
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Without a format of its own, the detector's lines are checked for timestamps;
+	// an occasional line without one is not a drift
+	var input strings.Builder
+	for i := 0; i < 50; i++ {
+		if i%10 == 3 {
+			input.WriteString("  continued stack trace line\n")
+			continue
+		}
+		fmt.Fprintf(&input, "[%d.0] kernel: all good\n", 100+i)
+	}
+	mon, err := New(context.Background(), &MockSource{content: input.String()}, &MockDetector{}, nil, Options{FormatDrift: true})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	if got := countAlerts(transport, "format_drift", sentry.LevelWarning); got != 0 {
+		t.Errorf("Expected no format_drift warning, got %d", got)
+	}
+}
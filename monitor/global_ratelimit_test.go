@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestGlobalRateLimiterSharedAcrossMonitors(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	input := `[100.0] Line 1
+[110.0] Line 2
+[120.0] Line 3
+[130.0] Line 4
+`
+	// Each monitor alone would send all 4 events; together they are capped at 5.
+	global := NewRateLimiter(5, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{
+			RateLimitBurst:    4,
+			RateLimitWindow:   "1m",
+			GlobalRateLimiter: global,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create monitor: %v", err)
+		}
+		mon.StopOnEOF = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mon.Start()
+		}()
+	}
+	wg.Wait()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 5 {
+		t.Errorf("Expected 5 events across both monitors, got %d", len(transport.events))
+	}
+}
+
+func TestRateLimiterConcurrentAllow(t *testing.T) {
+	limiter := NewRateLimiter(50, time.Minute)
+
+	var mu sync.Mutex
+	allowed := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if limiter.Allow() {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 50 {
+		t.Errorf("Expected exactly 50 allowed, got %d", allowed)
+	}
+}
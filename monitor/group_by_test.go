@@ -0,0 +1,208 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/metrics"
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestMonitorGroupBy(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Two request IDs interleaved within the same 5s window.
+	input := `[100.0] req=a error one
+[100.5] req=b error two
+[101.0] req=a error three
+[101.5] req=b error four
+`
+	det, err := detectors.NewGenericDetector(`req=(?P<request_id>\w+) error`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		GroupBy: "request_id",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 2 {
+		for i, e := range transport.events {
+			t.Logf("Event %d: %s", i, e.Message)
+		}
+		t.Fatalf("Expected 2 events, got %d", len(transport.events))
+	}
+
+	expected := []string{
+		"[100.0] req=a error one\n[101.0] req=a error three",
+		"[100.5] req=b error two\n[101.5] req=b error four",
+	}
+	for i, want := range expected {
+		if got := transport.events[i].Message; got != want {
+			t.Errorf("Event %d mismatch.\nExpected:\n%s\nGot:\n%s", i, want, got)
+		}
+	}
+}
+
+func TestMonitorGroupByWithoutKey(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Without group_by, interleaved lines are grouped by time only.
+	input := `[100.0] req=a error one
+[100.5] req=b error two
+`
+	det, err := detectors.NewGenericDetector(`req=(?P<request_id>\w+) error`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+}
+
+func TestMonitorGroupByMaxGroups(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	var sb strings.Builder
+	total := MaxGroups + 5
+	for i := 0; i < total; i++ {
+		fmt.Fprintf(&sb, "[100.0] req=r%d error\n", i)
+	}
+
+	det, err := detectors.NewGenericDetector(`req=(?P<request_id>\w+) error`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	mon, err := New(context.Background(), &MockSource{content: sb.String()}, det, nil, Options{
+		GroupBy: "request_id",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	// Feed lines without EOF flush to check the bound holds while running
+	for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+		mon.processMatch([]byte(line))
+	}
+
+	mon.bufferMutex.Lock()
+	groups := len(mon.batches)
+	mon.bufferMutex.Unlock()
+	if groups > MaxGroups {
+		t.Errorf("Expected at most %d groups, got %d", MaxGroups, groups)
+	}
+
+	mon.forceFlush()
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != total {
+		t.Errorf("Expected %d events, got %d", total, len(transport.events))
+	}
+}
+
+func TestMonitorGroupByMaxFingerprints(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, err := detectors.NewGenericDetector(`req=(?P<request_id>\w+) error`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	const maxFingerprints = 3
+	mon, err := New(context.Background(), &MockSource{}, det, nil, Options{
+		GroupBy:         "request_id",
+		MaxFingerprints: maxFingerprints,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	var before dto.Metric
+	counter := metrics.GroupEvictionsTotal.With(prometheus.Labels{"source": "mock"})
+	if err := counter.Write(&before); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+
+	// Every line has a unique key, as from a high-cardinality source
+	const total = 50
+	for i := 0; i < total; i++ {
+		mon.processMatch([]byte(fmt.Sprintf("[100.0] req=r%d error", i)))
+
+		mon.bufferMutex.Lock()
+		groups := len(mon.batches)
+		mon.bufferMutex.Unlock()
+		if groups > maxFingerprints {
+			t.Fatalf("After %d lines: expected at most %d groups, got %d", i+1, maxFingerprints, groups)
+		}
+	}
+
+	var after dto.Metric
+	if err := counter.Write(&after); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+	if got := after.GetCounter().GetValue() - before.GetCounter().GetValue(); got != total-maxFingerprints {
+		t.Errorf("Expected %d evictions, got %v", total-maxFingerprints, got)
+	}
+
+	// Evicted groups are sent, not lost
+	mon.forceFlush()
+	sentry.Flush(time.Second)
+	if events := snapshotEvents(transport); len(events) != total {
+		t.Errorf("Expected %d events, got %d", total, len(events))
+	}
+}
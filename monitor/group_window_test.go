@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestGroupWindowZero(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Only the two lines at 100.0 share a timestamp
+	input := "[100.0] Line 1\n[100.0] Line 2\n[100.5] Line 3\n[101.0] Line 4\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{GroupWindow: "0"})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	want := []string{"[100.0] Line 1\n[100.0] Line 2", "[100.5] Line 3", "[101.0] Line 4"}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %d", len(want), len(events))
+	}
+	for i, e := range events {
+		if e.Message != want[i] {
+			t.Errorf("Event %d: expected %q, got %q", i, want[i], e.Message)
+		}
+	}
+}
+
+func TestGroupWindowKeepsSizeLimit(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// A burst at a single timestamp is still split by max lines
+	input := "[100.0] a\n[100.0] b\n[100.0] c\n[100.0] d\n[100.0] e\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{GroupWindow: "0", MaxBatchLines: 2})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	if got := len(snapshotEvents(transport)); got != 3 {
+		t.Errorf("Expected 3 events of at most 2 lines, got %d", got)
+	}
+}
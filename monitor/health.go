@@ -0,0 +1,44 @@
+package monitor
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var errNoStartupData = errors.New("no data read since start (startup_deadline passed)")
+
+// Health is a snapshot of a monitor's source status.
+type Health struct {
+	Source       string
+	LastActivity time.Time // when the last line was read (or the monitor started)
+	Err          error     // non-nil while the source cannot be started, or has read nothing past startup_deadline
+}
+
+// Health returns the monitor's current source status.
+func (m *Monitor) Health() Health {
+	m.healthMu.Lock()
+	err := m.sourceErr
+	m.healthMu.Unlock()
+
+	// Until the first line, a source that missed its startup deadline counts as failing
+	if silent := atomic.LoadInt64(&m.startupSilentAt); err == nil && m.startupUnhealthy && silent != 0 && atomic.LoadInt64(&m.lastReadTime) == silent {
+		err = errNoStartupData
+	}
+
+	var last time.Time
+	if ns := atomic.LoadInt64(&m.lastReadTime); ns != 0 {
+		last = time.Unix(0, ns)
+	}
+	return Health{
+		Source:       m.Source.Name(),
+		LastActivity: last,
+		Err:          err,
+	}
+}
+
+func (m *Monitor) setSourceErr(err error) {
+	m.healthMu.Lock()
+	m.sourceErr = err
+	m.healthMu.Unlock()
+}
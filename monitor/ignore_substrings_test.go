@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/metrics"
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestMonitorIgnoreSubstrings(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, err := detectors.NewGenericDetector("error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	input := `[100.0] error: disk full
+[100.5] error: GET /healthz timed out
+[101.0] error: connection reset
+[101.5] error: probe from kube-probe failed
+`
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		IgnoreSubstrings: []string{"/healthz", "kube-probe"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	var before dto.Metric
+	counter := metrics.IgnoredLinesTotal.With(prometheus.Labels{"source": "mock"})
+	if err := counter.Write(&before); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	var after dto.Metric
+	if err := counter.Write(&after); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+	if got := after.GetCounter().GetValue() - before.GetCounter().GetValue(); got != 2 {
+		t.Errorf("Expected 2 ignored lines, got %v", got)
+	}
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	want := "[100.0] error: disk full\n[101.0] error: connection reset"
+	if events[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, events[0].Message)
+	}
+}
@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorGroupBySystemdUnit(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// journalctl -o json output with two units interleaved
+	input := `{"_SYSTEMD_UNIT":"nginx.service","PRIORITY":"3","MESSAGE":"upstream timed out"}
+{"_SYSTEMD_UNIT":"postgresql.service","PRIORITY":"3","MESSAGE":"could not accept connection"}
+{"_SYSTEMD_UNIT":"nginx.service","PRIORITY":"3","MESSAGE":"upstream reset"}
+{"_SYSTEMD_UNIT":"postgresql.service","PRIORITY":"3","MESSAGE":"too many clients"}
+`
+	det, err := detectors.NewJsonDetector("PRIORITY:^[0-3]$")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		GroupBy: SystemdUnitKey,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 2 {
+		t.Fatalf("Expected 2 events (one per unit), got %d", len(transport.events))
+	}
+
+	byUnit := make(map[string]string)
+	for _, e := range transport.events {
+		byUnit[e.Tags["systemd_unit"]] = e.Message
+	}
+	nginx, ok := byUnit["nginx.service"]
+	if !ok {
+		t.Fatalf("Expected an event tagged systemd_unit=nginx.service, got %v", byUnit)
+	}
+	if !strings.Contains(nginx, "upstream timed out") || !strings.Contains(nginx, "upstream reset") || strings.Contains(nginx, "postgresql") {
+		t.Errorf("Expected only nginx lines in the nginx event, got %q", nginx)
+	}
+	pg, ok := byUnit["postgresql.service"]
+	if !ok {
+		t.Fatalf("Expected an event tagged systemd_unit=postgresql.service, got %v", byUnit)
+	}
+	if strings.Contains(pg, "nginx") {
+		t.Errorf("Expected only postgresql lines in the postgresql event, got %q", pg)
+	}
+}
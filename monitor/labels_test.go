@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/sources"
+	"github.com/getsentry/sentry-go"
+)
+
+// MockLabelledSource prefixes lines like sources.K8sSource does
+type MockLabelledSource struct {
+	MockSource
+}
+
+func (s *MockLabelledSource) Labels(line []byte) (map[string]string, []byte) {
+	return (&sources.K8sSource{}).Labels(line)
+}
+
+func TestLineLabelsBecomeTags(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	content := "[default/web-1/app] Error: connection refused\n" +
+		"[default/web-2/app] Error: connection refused\n" +
+		"[default/web-1/app] Error: retry failed\n"
+	mon, err := New(context.Background(), &MockLabelledSource{MockSource{content: content}}, &MockDetector{}, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 2 {
+		t.Fatalf("Expected one event per pod, got %d", len(events))
+	}
+	pods := map[string]*sentry.Event{}
+	for _, event := range events {
+		if event.Tags[sources.K8sNamespaceLabel] != "default" || event.Tags[sources.K8sContainerLabel] != "app" {
+			t.Errorf("Unexpected tags %v", event.Tags)
+		}
+		if strings.Contains(event.Message, "[default/") {
+			t.Errorf("Expected prefix to be stripped, got %q", event.Message)
+		}
+		pods[event.Tags[sources.K8sPodLabel]] = event
+	}
+	web1 := pods["web-1"]
+	if web1 == nil || pods["web-2"] == nil {
+		t.Fatalf("Expected events for web-1 and web-2, got %v", pods)
+	}
+	if !strings.Contains(web1.Message, "retry failed") {
+		t.Errorf("Expected web-1 lines to be batched together, got %q", web1.Message)
+	}
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// offsetScanner is a bufio.Scanner that also tells where each line starts in
+// what it reads, so a sources.ContextSource can find the line again.
+type offsetScanner struct {
+	*bufio.Scanner
+	pos   int64 // bytes consumed so far
+	start int64 // where the current line starts
+}
+
+// newLineScanner returns a scanner of the lines of r with a buffer of
+// MaxScanTokenSize. Unlike a plain bufio.Scanner, which fails with
+// bufio.ErrTooLong on a longer line and so would restart the source into the
+// same line forever, it truncates such a line to its first MaxScanTokenSize
+// bytes and discards the rest, calling truncated (if not nil) for each.
+func newLineScanner(r io.Reader, truncated func()) *offsetScanner {
+	s := &offsetScanner{Scanner: bufio.NewScanner(r)}
+	s.Buffer(make([]byte, 0, MaxScanTokenSize), MaxScanTokenSize)
+	split := truncatingScanLines(MaxScanTokenSize, truncated)
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := split(data, atEOF)
+		if token != nil {
+			s.start = s.pos
+		}
+		s.pos += int64(advance)
+		return advance, token, err
+	})
+	return s
+}
+
+// Offset returns where the current line starts.
+func (s *offsetScanner) Offset() int64 {
+	return s.start
+}
+
+// truncatingScanLines is bufio.ScanLines for a scanner whose buffer holds at
+// most max bytes: once the buffer is full without a newline, its content is
+// the line's token and the rest of the line is skipped.
+func truncatingScanLines(max int, truncated func()) bufio.SplitFunc {
+	skipping := false
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if skipping {
+			if i := bytes.IndexByte(data, '\n'); i >= 0 {
+				skipping = false
+				return i + 1, nil, nil
+			}
+			return len(data), nil, nil
+		}
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if advance == 0 && err == nil && len(data) >= max {
+			skipping = true
+			if truncated != nil {
+				truncated()
+			}
+			return len(data), data, nil
+		}
+		return advance, token, err
+	}
+}
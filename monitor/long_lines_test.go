@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/angch/sentrylogmon/detectors"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestMonitorLongLine(t *testing.T) {
+	det, err := detectors.NewGenericDetector("error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	// A line of several MB fails a plain bufio.Scanner, which used to end the
+	// stream there (and restart into the same line when tailing)
+	huge := "error huge " + strings.Repeat("x", 3*MaxScanTokenSize)
+	input := "error before\n" + huge + "\n" + strings.Repeat("y", 2*MaxScanTokenSize) + "\nerror after\n"
+	src := &MockSource{content: input}
+	mon, err := New(context.Background(), src, det, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	var matched []string
+	mon.OnMatch = func(line []byte) { matched = append(matched, string(line)) }
+	mon.StopOnEOF = true
+	mon.Start()
+
+	if len(matched) != 3 {
+		t.Fatalf("Expected 3 matches, got %d", len(matched))
+	}
+	if matched[0] != "error before" || matched[2] != "error after" {
+		t.Errorf("Expected the lines around the long ones to match, got %q and %q", matched[0], matched[2])
+	}
+	if matched[1] != huge[:MaxScanTokenSize] {
+		t.Errorf("Expected the huge line truncated to %d bytes, got %d bytes", MaxScanTokenSize, len(matched[1]))
+	}
+
+	var metric dto.Metric
+	if err := mon.metricTruncated.Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	if got := metric.GetCounter().GetValue(); got != 2 {
+		t.Errorf("Expected 2 truncated lines, got %v", got)
+	}
+}
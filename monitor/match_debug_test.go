@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMatchDebug(t *testing.T) {
+	input := `[200.000000] ata1.00: exception Emask 0x0 SAct 0x0
+[200.100000] ata1.00: failed command: READ FPDMA QUEUED
+[200.200000] ata1.00: cmd 60/08:00:00:00:00/00:00:00:00:00/40 tag 0 ncq
+         res 41/40:00:00:00:00/00:00:00:00:00/00 Emask 0x409
+`
+	for _, tc := range []struct {
+		name       string
+		matchDebug bool
+	}{
+		{"Enabled", true},
+		{"Disabled", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+			mon, err := New(context.Background(), &MockSource{content: input}, detectors.NewDmesgDetector(), nil, Options{MatchDebug: tc.matchDebug})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+			sentry.Flush(time.Second)
+
+			events := snapshotEvents(transport)
+			if len(events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(events))
+			}
+			event := events[0]
+			if !tc.matchDebug {
+				if _, ok := event.Tags["match_reason"]; ok {
+					t.Errorf("Expected no match_reason tag without match_debug, got %q", event.Tags["match_reason"])
+				}
+				return
+			}
+			if event.Tags["match_reason"] != "pattern" {
+				t.Errorf("Expected match_reason tag 'pattern', got %q", event.Tags["match_reason"])
+			}
+			want := []string{"pattern", "pattern", "context", "continuation"}
+			if got := event.Extra["match_reasons"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected match_reasons %v, got %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorMessageFrom(t *testing.T) {
+	testCases := []struct {
+		name            string
+		input           string
+		expectedMessage string
+	}{
+		{
+			name:            "Field Present",
+			input:           `{"level": "error", "msg": "payment failed", "order_id": 42}`,
+			expectedMessage: "payment failed",
+		},
+		{
+			name:            "Field Missing Falls Back To Raw Line",
+			input:           `{"level": "error", "error": "payment failed"}`,
+			expectedMessage: `{"level": "error", "error": "payment failed"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			err := sentry.Init(sentry.ClientOptions{
+				Transport: transport,
+			})
+			if err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+
+			detector, err := detectors.NewJsonDetector("level:error")
+			if err != nil {
+				t.Fatalf("Failed to create detector: %v", err)
+			}
+
+			mon, err := New(context.Background(), &MockSource{content: tc.input + "\n"}, detector, nil, Options{
+				MessageFrom: "msg",
+			})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+
+			sentry.Flush(time.Second)
+
+			transport.mu.Lock()
+			defer transport.mu.Unlock()
+
+			if len(transport.events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(transport.events))
+			}
+			event := transport.events[0]
+
+			if event.Message != tc.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tc.expectedMessage, event.Message)
+			}
+
+			// The full object is still available as context and raw line
+			logData, ok := event.Contexts["Log Data"]
+			if !ok {
+				t.Fatal("Expected 'Log Data' context")
+			}
+			if logData["level"] != "error" {
+				t.Errorf("Expected level in Log Data, got %v", logData["level"])
+			}
+			if event.Extra["raw_line"] != tc.input {
+				t.Errorf("Expected raw_line %q, got %v", tc.input, event.Extra["raw_line"])
+			}
+		})
+	}
+}
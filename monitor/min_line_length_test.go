@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorMinLineLength(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// A broad pattern that also matches blank and tiny lines
+	det, err := detectors.NewGenericDetector(`^.*$`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	input := "2026-03-01T10:00:00Z request failed\n\n   \n}\n2026-03-01T10:01:00Z another failure\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		MinLineLength: 5,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	var messages []string
+	for _, event := range transport.events {
+		messages = append(messages, event.Message)
+	}
+	all := strings.Join(messages, "\n")
+
+	for _, want := range []string{"request failed", "another failure"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("Expected %q to be reported, got %q", want, all)
+		}
+	}
+	for _, line := range strings.Split(all, "\n") {
+		if len(strings.TrimSpace(line)) < 5 {
+			t.Errorf("Expected short lines to be skipped, got %q", all)
+			break
+		}
+	}
+}
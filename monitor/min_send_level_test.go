@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/metrics"
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestMinSendLevelDropsLowerLevels(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// The level comes from the named group, like a JSON level field would.
+	det, err := detectors.NewGenericDetector(`(?P<level>warning|error): `)
+	if err != nil {
+		t.Fatalf("NewGenericDetector failed: %v", err)
+	}
+	// Lines 10s apart are reported as separate events.
+	input := `[100.0] warning: disk 85% full
+[110.0] error: disk full
+`
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		MinSendLevel: "error",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	counter := metrics.SentryEventsTotal.With(prometheus.Labels{"source": "mock", "status": "below_min_level"})
+	var before dto.Metric
+	if err := counter.Write(&before); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected only the error event to be sent, got %d events", len(events))
+	}
+	if events[0].Level != sentry.LevelError || events[0].Message != "[110.0] error: disk full" {
+		t.Errorf("Unexpected event: level=%s message=%q", events[0].Level, events[0].Message)
+	}
+
+	var after dto.Metric
+	if err := counter.Write(&after); err != nil {
+		t.Fatalf("Failed to read metric: %v", err)
+	}
+	if got := after.GetCounter().GetValue() - before.GetCounter().GetValue(); got != 1 {
+		t.Errorf("Expected 1 event counted as below_min_level, got %v", got)
+	}
+}
+
+func TestMinSendLevelKeepsEventsWithoutLevel(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Neither the detector nor the line give a level
+	mon, err := New(context.Background(), &MockSource{content: "disk full\n"}, &MockDetector{}, nil, Options{
+		MinSendLevel: "warning",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	if events := snapshotEvents(transport); len(events) != 1 {
+		t.Fatalf("Expected the event without a level to be sent as an error, got %d events", len(events))
+	}
+}
+
+func TestMinSendLevelInvalid(t *testing.T) {
+	_, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{MinSendLevel: "loud"})
+	if err == nil {
+		t.Fatal("Expected an error for an unknown min_send_level")
+	}
+}
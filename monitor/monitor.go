@@ -1,9 +1,16 @@
 package monitor
 
 import (
-	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand"
+	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -11,6 +18,8 @@ import (
 	"time"
 
 	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/exporters"
+	"github.com/angch/sentrylogmon/fingerprint"
 	"github.com/angch/sentrylogmon/metrics"
 	"github.com/angch/sentrylogmon/sources"
 	"github.com/angch/sentrylogmon/sysstat"
@@ -20,6 +29,10 @@ import (
 
 var severityKeys = []string{"level", "severity", "log_level", "type"}
 
+// SystemdUnitKey is the journal field (journalctl -o json) naming the systemd unit.
+// Events whose context has it are tagged systemd_unit.
+const SystemdUnitKey = "_SYSTEMD_UNIT"
+
 func extractSyslogPriority(line []byte) (int, int, int, bool) {
 	// Fast path: must start with '<'
 	if len(line) < 3 || line[0] != '<' {
@@ -64,59 +77,66 @@ func extractSyslogPriority(line []byte) (int, int, int, bool) {
 	return pri, facility, severity, true
 }
 
-func extractTimestamp(line []byte) (float64, string) {
-	if len(line) == 0 {
-		return 0, ""
-	}
+// Facility and severity names as used by syslog(3) and RFC 5424.
+var syslogFacilityNames = [...]string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
 
-	// 1. Try dmesg format first (fastest/most common for this tool initially)
-	// Check if it starts with '['
-	if line[0] == '[' {
-		if ts, tsStr, ok := detectors.ParseDmesgTimestamp(line); ok {
-			return ts, tsStr
-		}
-	}
+var syslogSeverityNames = [...]string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
 
-	// 2. Try ISO8601/RFC3339 or Nginx
-	// Starts with digit
-	if line[0] >= '0' && line[0] <= '9' {
-		if ts, tsStr, ok := detectors.ParseISO8601(line); ok {
-			return ts, tsStr
-		}
+// syslogFacilityName returns the name for a syslog facility code, or "" if unknown.
+func syslogFacilityName(facility int) string {
+	if facility < 0 || facility >= len(syslogFacilityNames) {
+		return ""
+	}
+	return syslogFacilityNames[facility]
+}
 
-		if ts, tsStr, ok := detectors.ParseNginxError(line); ok {
-			return ts, tsStr
-		}
+// syslogSeverityName returns the name for a syslog severity code, or "" if unknown.
+func syslogSeverityName(severity int) string {
+	if severity < 0 || severity >= len(syslogSeverityNames) {
+		return ""
 	}
+	return syslogSeverityNames[severity]
+}
 
-	// 3. Try Syslog (Oct 27 10:00:00)
-	// Starts with '<' or uppercase letter
-	if line[0] == '<' || (line[0] >= 'A' && line[0] <= 'Z') {
-		if ts, tsStr, ok := detectors.ParseSyslogTimestamp(line); ok {
+func extractTimestamp(line []byte) (float64, string) {
+	return detectors.ExtractTimestamp(line)
+}
+
+// lineTimestamp returns the line's timestamp, preferring the detector's own
+// extraction. tsStr is empty if no timestamp was found.
+func (m *Monitor) lineTimestamp(line []byte) (float64, string) {
+	if extractor, ok := m.Detector.(detectors.TimestampExtractor); ok {
+		if ts, tsStr, ok := extractor.ExtractTimestamp(line); ok {
 			return ts, tsStr
 		}
 	}
-
-	// 4. Try Nginx Access ([27/Oct/2023:10:00:00 +0000])
-	// This handles IPv6 access logs starting with '[' or other custom formats.
-	if ts, tsStr, ok := detectors.ParseNginxAccess(line); ok {
-		return ts, tsStr
-	}
-
-	return 0, ""
+	return extractTimestamp(line)
 }
 
 const (
-	// Max buffer size to prevent memory leaks (e.g. 1000 lines)
+	// Default max lines per batch, to prevent memory leaks (see Options.MaxBatchLines)
 	MaxBufferSize = 1000
-	// Max buffer bytes to prevent memory exhaustion (256KB)
+	// Default max bytes per batch, to prevent memory exhaustion (see Options.MaxBatchBytes)
 	MaxBufferBytes = 256 * 1024
 	// Scanner buffer size (1MB) to handle long log lines
 	MaxScanTokenSize = 1024 * 1024
 	// Flush interval
 	FlushInterval = 5 * time.Second
+	// Default max number of concurrent group_by batches per monitor (see Options.MaxFingerprints).
+	// When exceeded, the least recently active batch is flushed early.
+	MaxGroups = 100
+	// Default max time between the timestamps of a batch's first and later lines (see Options.GroupWindow)
+	DefaultGroupWindow = 5 * time.Second
 )
 
+// RateLimiter allows at most limit events per window. It is safe for
+// concurrent use, so one limiter can be shared by several monitors.
 type RateLimiter struct {
 	limit       int
 	window      time.Duration
@@ -125,6 +145,16 @@ type RateLimiter struct {
 	mu          sync.Mutex
 }
 
+// NewRateLimiter returns a limiter allowing burst events per window.
+// A burst of 0 or less allows everything.
+func NewRateLimiter(burst int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:       burst,
+		window:      window,
+		windowStart: time.Now(),
+	}
+}
+
 func (r *RateLimiter) Allow() bool {
 	if r.limit <= 0 {
 		return true
@@ -152,7 +182,37 @@ type SyslogPriority struct {
 type BatchMetadata struct {
 	TimestampStr string
 	SyslogPri    *SyslogPriority
+	SyslogHeader *detectors.SyslogHeader // host, app and pid from the line's syslog header, if any
 	Context      map[string]interface{}
+	AlertType    string            // set as the alert_type tag, e.g. "sequence"
+	Labels       map[string]string // from a sources.LineLabeler, set as tags
+	Matched      time.Time         // when the first line matched; zero means when sent
+	MatchReasons []string          // with match_debug, why each line matched, e.g. "pattern"
+	RawLine      []byte            // with context_attach_lines, the first line as read, to find it again in the source
+	RawOffset    int64             // where RawLine starts in the source's stream
+	Repeats      int               // with collapse_repeats, identical lines left out after the batch's lines
+	Lines        int               // how many lines the event holds; a line may itself span several
+}
+
+// batch holds the buffered lines for one group key.
+// Without group_by, a monitor only ever uses the "" key.
+type batch struct {
+	key          string
+	seq          uint64 // creation order, used to flush deterministically
+	buffer       strings.Builder
+	starts       []int // offset of each line in buffer, only kept for newest-first batches
+	count        int
+	startTime    float64
+	opened       time.Time // when the first line was added, for max_batch_age
+	meta         BatchMetadata
+	lastActivity time.Time
+	timer        *time.Timer
+	gen          uint64 // incremented on every timer reset; stale timer callbacks are ignored
+}
+
+type pendingEvent struct {
+	message string
+	meta    BatchMetadata
 }
 
 type Monitor struct {
@@ -164,39 +224,289 @@ type Monitor struct {
 	Verbose           bool
 	StopOnEOF         bool
 	RateLimiter       *RateLimiter
+	GlobalRateLimiter *RateLimiter // shared across monitors, consulted after RateLimiter
 	Hub               *sentry.Hub
 
+	// OnMatch, if set, receives each matched line (transformed and redacted)
+	// instead of it being batched and reported to Sentry.
+	OnMatch func(line []byte)
+
 	// Cached metrics
-	metricProcessedLines prometheus.Counter
-	metricIssuesDetected prometheus.Counter
-	metricSentrySent     prometheus.Counter
-	metricSentryDropped  prometheus.Counter
-	metricLastActivity   prometheus.Gauge
+	metricProcessedLines     prometheus.Counter
+	metricIssuesDetected     prometheus.Counter
+	metricSentrySent         prometheus.Counter
+	metricSentryDropped      prometheus.Counter
+	metricGlobalDropped      prometheus.Counter
+	metricCooldownSuppressed prometheus.Counter
+	metricDedupSuppressed    prometheus.Counter
+	metricWarmupSuppressed   prometheus.Counter
+	metricBelowMinLevel      prometheus.Counter
+	metricOversize           prometheus.Counter
+	metricGroupEvicted       prometheus.Counter
+	metricBatchLines         prometheus.Observer
+	metricIgnored            prometheus.Counter
+	metricTruncated          prometheus.Counter
+	metricLastActivity       prometheus.Gauge
+	metricThroughput         prometheus.Gauge
+	metricMatchRate          prometheus.Gauge
+
+	throughput *throughput
+	recent     recentEvents // the last events sent, for --tail
+
+	// Static context and tags from config, attached to every event
+	staticContext map[string]interface{}
+	staticTags    map[string]string
+	deployInfo    map[string]interface{}
+	tagPolicy     tagPolicy // which extracted fields and labels become tags
+
+	maxEventBytes int
+	maxGroups     int    // max concurrent batches before the least recently active is flushed
+	maxBatchLines int    // a batch is sent early once it has this many lines
+	maxBatchBytes int    // ... or the next line would take it past this many bytes
+	omitRawLine   bool   // skip the raw_line extra
+	rawLineMax    int    // raw_line is truncated to this many bytes (0: no limit)
+	messageFrom   string // context key whose value is used as the event message
+	titleFrom     string // how the event title is computed, see eventTitle
+	escapeControl bool   // control characters in event messages are escaped, see escapeControlChars
+	minSendRank   int    // events whose level ranks below this (see levelRanks) are dropped
+	minLineLength int    // lines shorter than this after trimming are skipped
+	collapse      bool   // runs of identical lines are collapsed, see collapseScanner
+	redactor      *detectors.Redactor
+	timeWindow    *timeWindow
+	sequence      *sequenceMatcher // only used from the scan loop
+	ignoreSubstrs [][]byte         // lines containing any of these are skipped before matching
+	contextStore  *contextStore    // only used from the scan loop
+	formatDrift   *formatDrift     // nil unless Options.FormatDrift is set; only used from the scan loop
+	cooldown      *cooldown        // nil unless Options.Cooldown is set
+	dedup         *lifetimeDedup   // nil unless Options.DedupLifetime is set
+	warmupUntil   time.Time        // events before this are suppressed; zero unless Options.Warmup is set
+	maxBatchAge   time.Duration    // a batch is sent early once it was opened this long ago (0: no limit)
+	groupWindow   float64          // seconds a timestamped line may be after its batch's first line (0: same timestamp only)
+
+	// Normalizes event fingerprints; nil unless Options.FingerprintNormalizers is set
+	normalizer *fingerprint.Normalizer
+	// Context key whose value, when an event has it, is the fingerprint instead
+	fingerprintFrom string
+
+	batchSeparator   string // joins the lines of a batch (default: newline)
+	batchNewestFirst bool   // batch_order: desc
+
+	// Set when the source labels its lines (e.g. with the Kubernetes pod);
+	// lineLabels holds the current line's labels and is only used from the scan loop.
+	labeler     sources.LineLabeler
+	lineLabels  map[string]string
+	traceIDFrom string
+	spanIDFrom  string
+	userFrom    UserFrom
+
+	// With collapse set, lineRepeats is how many more identical lines the
+	// current line stands for; it is only used from the scan loop.
+	lineRepeats int
+
+	// With matchDebug set, why each line matched is attached to events;
+	// lineMatchReason is the current line's and is only used from the scan loop.
+	matchDebug      bool
+	lineMatchReason string
+
+	// With parallelDetect > 1, that many goroutines run the detector and lines
+	// are handled in order by one other goroutine (see detectPool), which then
+	// stands in for the scan loop in "only used from the scan loop".
+	parallelDetect int
+
+	// With contextAttachLines set, events carry the lines around their first
+	// line, reread from contextSource; lineRaw is the current line as read,
+	// before any transformation, and lineOffset where it starts in the
+	// stream. Both are only used from the scan loop.
+	contextSource      sources.ContextSource
+	contextAttachLines int
+	lineRaw            []byte
+	lineOffset         int64
+
+	// With stripSyslogHeader set, the header of the current line is removed
+	// and kept in lineSyslogHeader; only used from the scan loop.
+	stripSyslogHeader bool
+	lineSyslogHeader  *detectors.SyslogHeader
+
+	// Additional hubs that receive a copy of every event sent to Hub
+	mirrorHubs []*sentry.Hub
+
+	// Events matching a route by level go to its hub instead of Hub and mirrorHubs
+	routes []route
+
+	// Also receive every event sent to Sentry; closed when Start returns
+	exporters []exporters.Exporter
 
 	// Buffering
-	buffer           strings.Builder
-	bufferCount      int
-	bufferMutex      sync.Mutex
-	bufferStartTime  float64
-	currentBatchMeta BatchMetadata
-	flushTimer       *time.Timer
-	lastActivityTime time.Time
+	bufferMutex sync.Mutex
+	batches     map[string]*batch
+	batchSeq    uint64
+	groupBy     string
+
+	// Source health, reported by Health
+	healthMu  sync.Mutex
+	sourceErr error // last error starting the source, nil once it starts
 
 	// Inactivity detection
+	rngMu             sync.Mutex
+	rng               *rand.Rand // staggers the first watchdog check
 	maxInactivity     time.Duration
+	realertInterval   time.Duration
 	lastReadTime      int64 // atomic unix nano
 	inactivityAlerted int32 // atomic boolean
+
+	// Rate limit storm notifications; rateLimitQuiet is 0 from the first
+	// dropped event until none was dropped for a rate limit window
+	rateLimitAlerts bool
+	lastDropTime    int64 // atomic unix nano
+	rateLimitQuiet  int32 // atomic boolean
+	stormDrops      int64 // atomic count of events dropped in the current storm
+
+	// Startup data detection
+	startupDeadline  time.Duration
+	startupUnhealthy bool
+	startupSilentAt  int64 // atomic unix nano of the start, once startupDeadline passed without a line
+
+	// Stalled read detection
+	readTimeout time.Duration
+	stalls      int // consecutive stalls without data in between; only used from Start
+
+	// Expected line detection
+	expectDetector   detectors.Detector
+	expectPattern    string
+	expectWithin     time.Duration
+	lastExpectedTime int64 // atomic unix nano
+	expectAlerted    int32 // atomic boolean
+
+	// Misconfiguration hint, only used from the scan loop
+	noMatchLines  int // lines read without a match before hinting (0: off)
+	linesSeen     int
+	anyMatch      bool
+	noMatchHinted bool
+}
+
+// UserFrom names the context keys (e.g. JSON fields or named regex groups)
+// holding the Sentry user an event affects. Empty keys are not looked up.
+type UserFrom struct {
+	ID       string
+	Email    string
+	Username string
+}
+
+// SentryTarget is a Sentry project that events are sent to.
+type SentryTarget struct {
+	DSN         string
+	Environment string
+	Release     string
+	Dist        string
+	SampleRate  float64 // fraction of events the SDK sends; 0 sends all
+}
+
+// newHub returns a hub with its own client for target. httpTransport, if
+// set, is used for the client's requests to Sentry.
+func newHub(target SentryTarget, httpTransport http.RoundTripper) (*sentry.Hub, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:           target.DSN,
+		Environment:   target.Environment,
+		Release:       target.Release,
+		Dist:          target.Dist,
+		SampleRate:    target.SampleRate,
+		HTTPTransport: httpTransport,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sentry.NewHub(client, sentry.NewScope()), nil
 }
 
 type Options struct {
 	Verbose           bool
 	ExcludePattern    string
+	ExcludePatterns   []string // matched in addition to ExcludePattern, e.g. from an exclude file
 	MaxInactivity     string
+	RealertInterval   string // min time between two inactivity or missing_expected warnings, against a flapping source
+	StartupDeadline   string // warn once (alert_type=no_startup_data) if no line is read this long after Start
+	StartupUnhealthy  bool   // also report the source as failing in Health until a line is read
+	ReadTimeout       string // restart the source (alert_type=source_stalled) when a read returns nothing this long
+	FormatDrift       bool   // warn (alert_type=format_drift) when the share of lines in the expected format drops sharply
+	MatchDebug        bool   // tag events with why their lines matched (match_reason), to help tune patterns
 	RateLimitBurst    int
 	RateLimitWindow   string
+	RateLimitAlerts   bool // warn (alert_type=rate_limited) when RateLimiter starts dropping events, and note when it stops
 	SentryDSN         string
 	SentryEnvironment string
 	SentryRelease     string
+	SentryDist        string
+	SentrySampleRate  float64
+	GroupBy           string
+	Context           map[string]interface{}
+	Tags              map[string]string
+	DeployInfo        map[string]interface{} // attached as the "Deploy" context, e.g. the commit and deployer
+	MaxEventBytes     int
+	MaxFingerprints   int  // max concurrent group_by batches, and messages remembered by DedupLifetime (0: MaxGroups)
+	MaxBatchLines     int  // max lines per batched event (0: MaxBufferSize)
+	MaxBatchBytes     int  // max bytes per batched event (0: MaxBufferBytes)
+	ParallelDetect    int  // run the detector on this many goroutines at once; lines are still handled in order (0 or 1: none)
+	OmitRawLine       bool // don't attach the raw_line extra, which repeats the message unless MessageFrom is set
+	OmitSysstat       bool // don't attach the "Server State" context even when a collector is given
+	RawLineMaxBytes   int  // truncate the raw_line extra to this many bytes (0: no limit)
+	MessageFrom       string
+	FingerprintFrom   string // context key (e.g. JSON "error_code") whose value groups events in Sentry
+	TitleFrom         string // first_line, fingerprint or pattern; empty leaves the title to Sentry
+	MinSendLevel      string // events whose level is below this (debug, info, warning, error, fatal) are dropped
+	MaxBatchAge       string // flush a batch this long after its first line even while lines keep coming
+	GroupWindow       string // max time from a batch's first timestamped line to a later one (default: 5s; "0": same timestamp only)
+	BatchSeparator    string // joins the lines of a batched event (default: "\n")
+	BatchOrder        string // asc (default) or desc for newest line first
+	MinLineLength     int
+	CollapseRepeats   bool     // pass on runs of identical consecutive lines as one, counted in the repeated_lines extra
+	IgnoreSubstrings  []string // literal substrings checked with bytes.Contains, a cheap pre-filter before regexes
+	NoMatchLines      int      // hint once (alert_type=no_matches) after this many lines without a match
+	TraceIDFrom       string   // context key holding a trace ID to link events to traces
+	SpanIDFrom        string   // context key holding a span ID, used with TraceIDFrom
+	UserFrom          UserFrom // context keys holding the event's Sentry user
+	OnlyAfter         string   // RFC3339; lines timestamped earlier are skipped
+	OnlyBefore        string   // RFC3339; lines timestamped later are skipped
+	UntimedLines      string   // include (default) or exclude lines without a timestamp when a window is set
+	Sequence          []string // patterns that must match in order, reported as one event
+	SequenceWithin    string   // max duration from the first to the last line of Sequence
+	ContextPattern    string   // regex whose named groups are remembered and added to later events
+	ContextWithin     string   // how long ContextPattern captures are kept (default: 1m)
+	Cooldown          string   // after an event, count further matches for this long and send one summary
+	DedupLifetime     bool     // send one event per distinct message for the life of the monitor, counting the rest
+	Warmup            string   // after start, count matches for this long without sending them
+	ExpectPattern     string
+	ExpectWithin      string
+	Redact            []string // regex patterns replaced with [REDACTED] before lines are reported
+	StripSyslogHeader bool     // report only the message of "Oct 11 22:14:15 host tag[pid]: message" lines
+	TagAllowlist      []string // extracted fields set as tags ("*": all); the rest stay in the "Log Data" context
+	TagDenylist       []string // fields and line labels never set as tags, e.g. request_id
+	MaxTagValueLength int      // longer field and label values are not set as tags (0: DefaultMaxTagValueLength)
+
+	// NormalizeControlChars escapes control characters in matched lines as \xNN, e.g. a tab as \x09.
+	NormalizeControlChars bool
+
+	// ContextAttachLines attaches this many lines before and after each event's first line,
+	// reread from a sources.ContextSource such as a file, as "context.log".
+	ContextAttachLines int
+
+	// FingerprintNormalizers groups events in Sentry by their first line normalized with these
+	// (see fingerprint.Names), e.g. numbers so that lines differing only in counts group together.
+	FingerprintNormalizers []string
+
+	// SentryTargets sends events to additional Sentry projects alongside SentryDSN.
+	SentryTargets []SentryTarget
+
+	// Routes sends events to other Sentry projects by level, e.g. errors to a paging project.
+	Routes []Route
+
+	// HTTPTransport, if set, is used by the monitor's own Sentry clients, e.g. to count deliveries.
+	HTTPTransport http.RoundTripper
+
+	// GlobalRateLimiter, if set, is shared with other monitors to cap their combined volume.
+	GlobalRateLimiter *RateLimiter
+
+	// Exporters also receive every event sent to Sentry, e.g. to forward it to syslog.
+	Exporters []exporters.Exporter
 }
 
 func New(ctx context.Context, source sources.LogSource, detector detectors.Detector, collector *sysstat.Collector, opts Options) (*Monitor, error) {
@@ -206,6 +516,75 @@ func New(ctx context.Context, source sources.LogSource, detector detectors.Detec
 		Detector:  detector,
 		Collector: collector,
 		Verbose:   opts.Verbose,
+		batches:   make(map[string]*batch),
+		groupBy:   opts.GroupBy,
+
+		staticContext: opts.Context,
+		staticTags:    opts.Tags,
+		deployInfo:    opts.DeployInfo,
+		maxEventBytes: opts.MaxEventBytes,
+		maxGroups:     opts.MaxFingerprints,
+		maxBatchLines: opts.MaxBatchLines,
+		maxBatchBytes: opts.MaxBatchBytes,
+		omitRawLine:   opts.OmitRawLine,
+		rawLineMax:    opts.RawLineMaxBytes,
+		messageFrom:   opts.MessageFrom,
+		titleFrom:     opts.TitleFrom,
+		escapeControl: opts.NormalizeControlChars,
+		minLineLength: opts.MinLineLength,
+		collapse:      opts.CollapseRepeats,
+		traceIDFrom:   opts.TraceIDFrom,
+		spanIDFrom:    opts.SpanIDFrom,
+		userFrom:      opts.UserFrom,
+		exporters:     opts.Exporters,
+		matchDebug:    opts.MatchDebug,
+		tagPolicy:     newTagPolicy(opts.TagAllowlist, opts.TagDenylist, opts.MaxTagValueLength),
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+
+		stripSyslogHeader: opts.StripSyslogHeader,
+	}
+	m.labeler, _ = source.(sources.LineLabeler)
+	if opts.ContextAttachLines > 0 {
+		if cs, ok := source.(sources.ContextSource); ok {
+			m.contextSource = cs
+			m.contextAttachLines = opts.ContextAttachLines
+		} else {
+			log.Printf("[%s] context_attach_lines is ignored: the source can't reread its lines", source.Name())
+		}
+	}
+	if opts.OmitSysstat {
+		// e.g. an access log, where host stats only add size
+		m.Collector = nil
+	}
+	if m.maxEventBytes <= 0 {
+		m.maxEventBytes = DefaultMaxEventBytes
+	}
+	if m.maxGroups <= 0 {
+		m.maxGroups = MaxGroups
+	}
+	if m.maxBatchLines <= 0 {
+		m.maxBatchLines = MaxBufferSize
+	}
+	if m.maxBatchBytes <= 0 {
+		m.maxBatchBytes = MaxBufferBytes
+	}
+	if opts.MaxBatchAge != "" {
+		d, err := time.ParseDuration(opts.MaxBatchAge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_batch_age: %w", err)
+		}
+		m.maxBatchAge = d
+	}
+	m.groupWindow = DefaultGroupWindow.Seconds()
+	if opts.GroupWindow != "" {
+		d, err := time.ParseDuration(opts.GroupWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group_window: %w", err)
+		}
+		m.groupWindow = d.Seconds()
+	}
+	for _, sub := range opts.IgnoreSubstrings {
+		m.ignoreSubstrs = append(m.ignoreSubstrs, []byte(sub))
 	}
 
 	// Initialize cached metrics
@@ -213,24 +592,157 @@ func New(ctx context.Context, source sources.LogSource, detector detectors.Detec
 	m.metricIssuesDetected = metrics.IssuesDetectedTotal.With(prometheus.Labels{"source": source.Name()})
 	m.metricSentrySent = metrics.SentryEventsTotal.With(prometheus.Labels{"source": source.Name(), "status": "sent"})
 	m.metricSentryDropped = metrics.SentryEventsTotal.With(prometheus.Labels{"source": source.Name(), "status": "dropped"})
+	m.metricGlobalDropped = metrics.SentryEventsTotal.With(prometheus.Labels{"source": source.Name(), "status": "global_rate_limited"})
+	m.metricCooldownSuppressed = metrics.SentryEventsTotal.With(prometheus.Labels{"source": source.Name(), "status": "cooldown_suppressed"})
+	m.metricDedupSuppressed = metrics.SentryEventsTotal.With(prometheus.Labels{"source": source.Name(), "status": "dedup_suppressed"})
+	m.metricWarmupSuppressed = metrics.SentryEventsTotal.With(prometheus.Labels{"source": source.Name(), "status": "warmup_suppressed"})
+	m.metricBelowMinLevel = metrics.SentryEventsTotal.With(prometheus.Labels{"source": source.Name(), "status": "below_min_level"})
+	m.metricOversize = metrics.OversizeTrimmedTotal.With(prometheus.Labels{"source": source.Name()})
+	m.metricGroupEvicted = metrics.GroupEvictionsTotal.With(prometheus.Labels{"source": source.Name()})
+	m.metricBatchLines = metrics.BatchLines.With(prometheus.Labels{"source": source.Name()})
+	m.metricIgnored = metrics.IgnoredLinesTotal.With(prometheus.Labels{"source": source.Name()})
+	m.metricTruncated = metrics.TruncatedLinesTotal.With(prometheus.Labels{"source": source.Name()})
 	m.metricLastActivity = metrics.LastActivityTimestamp.With(prometheus.Labels{"source": source.Name()})
+	m.metricThroughput = metrics.ThroughputLinesPerSec.With(prometheus.Labels{"source": source.Name()})
+	m.metricMatchRate = metrics.MatchRate.With(prometheus.Labels{"source": source.Name()})
+	m.throughput = newThroughput(ThroughputWindow)
 
 	// Initialize Sentry Hub
 	if opts.SentryDSN != "" {
-		client, err := sentry.NewClient(sentry.ClientOptions{
-			Dsn:         opts.SentryDSN,
+		hub, err := newHub(SentryTarget{
+			DSN:         opts.SentryDSN,
 			Environment: opts.SentryEnvironment,
 			Release:     opts.SentryRelease,
-		})
+			Dist:        opts.SentryDist,
+			SampleRate:  opts.SentrySampleRate,
+		}, opts.HTTPTransport)
 		if err != nil {
 			return nil, err
 		}
-		m.Hub = sentry.NewHub(client, sentry.NewScope())
+		m.Hub = hub
 	} else {
 		m.Hub = sentry.CurrentHub()
 	}
+	for _, target := range opts.SentryTargets {
+		hub, err := newHub(target, opts.HTTPTransport)
+		if err != nil {
+			return nil, err
+		}
+		m.mirrorHubs = append(m.mirrorHubs, hub)
+	}
+	for _, r := range opts.Routes {
+		rt, err := newRoute(r, opts.HTTPTransport)
+		if err != nil {
+			return nil, err
+		}
+		m.routes = append(m.routes, rt)
+	}
+
+	if len(opts.Redact) > 0 {
+		r, err := detectors.NewRedactor(opts.Redact, false)
+		if err != nil {
+			return nil, err
+		}
+		m.redactor = r
+	}
+
+	if err := validateTitleFrom(opts.TitleFrom); err != nil {
+		return nil, err
+	}
+	normalizer, err := fingerprint.New(opts.FingerprintNormalizers)
+	if err != nil {
+		return nil, err
+	}
+	m.normalizer = normalizer
+	m.fingerprintFrom = opts.FingerprintFrom
+	if opts.MinSendLevel != "" {
+		rank, ok := parseLevelRank(opts.MinSendLevel)
+		if !ok {
+			return nil, fmt.Errorf("unknown min_send_level '%s' (valid levels: debug, info, warning, error, fatal)", opts.MinSendLevel)
+		}
+		m.minSendRank = rank
+	}
+	m.batchSeparator = opts.BatchSeparator
+	if m.batchSeparator == "" {
+		m.batchSeparator = "\n"
+	}
+	switch opts.BatchOrder {
+	case "", "asc":
+	case "desc":
+		m.batchNewestFirst = true
+	default:
+		return nil, fmt.Errorf("unknown batch_order '%s' (valid values: asc, desc)", opts.BatchOrder)
+	}
+
+	tw, err := newTimeWindow(opts.OnlyAfter, opts.OnlyBefore, opts.UntimedLines)
+	if err != nil {
+		return nil, err
+	}
+	m.timeWindow = tw
 
-	if opts.ExcludePattern != "" {
+	if len(opts.Sequence) > 0 {
+		within, err := time.ParseDuration(opts.SequenceWithin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequence within duration '%s': %w", opts.SequenceWithin, err)
+		}
+		seq, err := newSequenceMatcher(opts.Sequence, within)
+		if err != nil {
+			return nil, err
+		}
+		m.sequence = seq
+	}
+
+	if opts.Cooldown != "" {
+		period, err := time.ParseDuration(opts.Cooldown)
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("invalid cooldown duration '%s'", opts.Cooldown)
+		}
+		m.cooldown = &cooldown{period: period}
+	}
+	if opts.ParallelDetect > 1 {
+		if od, ok := detector.(detectors.OrderedDetector); ok && od.DependsOnLineOrder() {
+			return nil, fmt.Errorf("parallel_detect can't be used with this format, whose detector depends on line order (e.g. dmesg, iis)")
+		}
+		m.parallelDetect = opts.ParallelDetect
+	}
+	if opts.DedupLifetime {
+		m.dedup = &lifetimeDedup{max: m.maxGroups, seen: make(map[string]*dedupEntry)}
+	}
+
+	if opts.Warmup != "" {
+		warmup, err := time.ParseDuration(opts.Warmup)
+		if err != nil || warmup <= 0 {
+			return nil, fmt.Errorf("invalid warmup duration '%s'", opts.Warmup)
+		}
+		m.warmupUntil = time.Now().Add(warmup)
+	}
+
+	if opts.ContextPattern != "" {
+		var within time.Duration
+		if opts.ContextWithin != "" {
+			within, err = time.ParseDuration(opts.ContextWithin)
+			if err != nil {
+				return nil, fmt.Errorf("invalid context within duration '%s': %w", opts.ContextWithin, err)
+			}
+		}
+		cs, err := newContextStore(opts.ContextPattern, within)
+		if err != nil {
+			return nil, err
+		}
+		m.contextStore = cs
+	}
+
+	if len(opts.ExcludePatterns) > 0 {
+		patterns := opts.ExcludePatterns
+		if opts.ExcludePattern != "" {
+			patterns = append([]string{opts.ExcludePattern}, patterns...)
+		}
+		ed, err := detectors.NewMultiDetector(patterns)
+		if err != nil {
+			return nil, err
+		}
+		m.ExclusionDetector = ed
+	} else if opts.ExcludePattern != "" {
 		ed, err := detectors.NewGenericDetector(opts.ExcludePattern)
 		if err != nil {
 			return nil, err
@@ -255,11 +767,15 @@ func New(ctx context.Context, source sources.LogSource, detector detectors.Detec
 				log.Printf("Rate limit window not specified, defaulting to 1s")
 			}
 		}
-		m.RateLimiter = &RateLimiter{
-			limit:       opts.RateLimitBurst,
-			window:      window,
-			windowStart: time.Now(),
-		}
+		m.RateLimiter = NewRateLimiter(opts.RateLimitBurst, window)
+		m.rateLimitAlerts = opts.RateLimitAlerts
+		m.rateLimitQuiet = 1
+	}
+	m.GlobalRateLimiter = opts.GlobalRateLimiter
+
+	// Without a detector (e.g. only a sequence) there is nothing to match
+	if detector != nil {
+		m.noMatchLines = opts.NoMatchLines
 	}
 
 	// Initialize MaxInactivity
@@ -271,12 +787,49 @@ func New(ctx context.Context, source sources.LogSource, detector detectors.Detec
 			log.Printf("Invalid max inactivity duration '%s': %v", opts.MaxInactivity, err)
 		}
 	}
+	if opts.RealertInterval != "" {
+		d, err := time.ParseDuration(opts.RealertInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid realert_interval: %w", err)
+		}
+		m.realertInterval = d
+	}
+
+	if opts.StartupDeadline != "" {
+		d, err := time.ParseDuration(opts.StartupDeadline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid startup_deadline: %w", err)
+		}
+		m.startupDeadline = d
+		m.startupUnhealthy = opts.StartupUnhealthy
+	}
+	if opts.ReadTimeout != "" {
+		d, err := time.ParseDuration(opts.ReadTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read_timeout: %w", err)
+		}
+		m.readTimeout = d
+	}
+	if opts.FormatDrift {
+		m.formatDrift = m.newFormatDrift()
+	}
+
+	// Initialize expected line detection
+	if opts.ExpectPattern != "" && opts.ExpectWithin != "" {
+		ed, err := detectors.NewGenericDetector(opts.ExpectPattern)
+		if err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(opts.ExpectWithin)
+		if err == nil {
+			m.expectDetector = ed
+			m.expectPattern = opts.ExpectPattern
+			m.expectWithin = d
+		} else {
+			log.Printf("Invalid expect within duration '%s': %v", opts.ExpectWithin, err)
+		}
+	}
 
-	// Initialize timer as stopped
-	m.flushTimer = time.AfterFunc(FlushInterval, func() {
-		m.flushBuffer()
-	})
-	m.flushTimer.Stop()
 	return m, nil
 }
 
@@ -285,28 +838,67 @@ func (m *Monitor) Start() {
 		log.Printf("Starting monitor for %s", m.Source.Name())
 	}
 
-	atomic.StoreInt64(&m.lastReadTime, time.Now().UnixNano())
+	started := time.Now().UnixNano()
+	atomic.StoreInt64(&m.lastReadTime, started)
 
 	if m.maxInactivity > 0 {
 		go m.watchdog()
 	}
+	if m.startupDeadline > 0 {
+		go m.startupWatchdog(started)
+	}
+	if m.rateLimitAlerts {
+		go m.rateLimitWatchdog()
+	}
+	if m.expectDetector != nil {
+		atomic.StoreInt64(&m.lastExpectedTime, time.Now().UnixNano())
+		go m.expectWatchdog()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go m.throughputLoop(done)
+	defer m.closeExporters()
 
 	for {
+		// A closed source keeps failing to start, so stop once cancelled
+		if m.ctx.Err() != nil {
+			return
+		}
 		reader, err := m.Source.Stream()
+		m.setSourceErr(err)
 		if err != nil {
 			log.Printf("Error starting source %s: %v", m.Source.Name(), err)
-			time.Sleep(1 * time.Second)
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-time.After(1 * time.Second):
+			}
 			continue
 		}
 
-		scanner := bufio.NewScanner(reader)
-		// Increase buffer size to handle long lines
-		buf := make([]byte, 0, MaxScanTokenSize)
-		scanner.Buffer(buf, MaxScanTokenSize)
+		if m.readTimeout > 0 {
+			reader = newStallReader(reader, m.readTimeout)
+		}
+		streamStarted := time.Now().UnixNano()
+
+		var scanner lineScanner
+		var collapser *collapseScanner
+		if m.collapse {
+			collapser = newCollapseScanner(reader, m.lineTruncated)
+			scanner = collapser
+		} else {
+			scanner = newLineScanner(reader, m.lineTruncated)
+		}
 
+		var pool *detectPool
+		if m.parallelDetect > 1 {
+			pool = m.startDetectPool(m.parallelDetect)
+		}
 		var lastMetricUpdateTime time.Time
 		for scanner.Scan() {
 			m.metricProcessedLines.Inc()
+			atomic.AddInt64(&m.throughput.lines, 1)
 
 			now := time.Now()
 			// Update lastReadTime for inactivity detection
@@ -318,26 +910,40 @@ func (m *Monitor) Start() {
 			}
 
 			lineBytes := scanner.Bytes()
-			if m.Detector.Detect(lineBytes) {
-				if m.ExclusionDetector != nil && m.ExclusionDetector.Detect(lineBytes) {
-					if m.Verbose {
-						log.Printf("[%s] Excluded: %s", m.Source.Name(), string(lineBytes))
-					}
-					continue
-				}
-				m.metricIssuesDetected.Inc()
-				if m.Verbose {
-					log.Printf("[%s] Matched: %s", m.Source.Name(), string(lineBytes))
-				}
-				m.processMatch(lineBytes)
+			var labels map[string]string
+			if m.labeler != nil {
+				labels, lineBytes = m.labeler.Labels(lineBytes)
+			}
+			repeats := 0
+			if collapser != nil {
+				repeats = collapser.Count() - 1
 			}
+			if pool != nil {
+				pool.submit(lineBytes, labels, repeats, scanner.Offset(), now)
+				continue
+			}
+			m.lineLabels = labels
+			m.lineRepeats = repeats
+			m.lineOffset = scanner.Offset()
+			m.handleLine(lineBytes, now, nil)
+		}
+		if pool != nil {
+			// Handle the lines still in the pool before flushing
+			pool.close()
 		}
 
+		m.noMatchHint()
 		// Flush any remaining buffer
 		m.forceFlush()
 		m.metricLastActivity.Set(float64(time.Now().Unix()))
 
-		if err := scanner.Err(); err != nil {
+		restartDelay := 1 * time.Second
+		if atomic.LoadInt64(&m.lastReadTime) > streamStarted {
+			m.stalls = 0
+		}
+		if err := scanner.Err(); errors.Is(err, errSourceStalled) {
+			restartDelay = m.sourceStalled()
+		} else if err != nil {
 			// Suppress specific errors when stopping on EOF is enabled
 			if !m.StopOnEOF || !strings.Contains(err.Error(), "file already closed") {
 				log.Printf("Error reading from source %s: %v", m.Source.Name(), err)
@@ -345,6 +951,10 @@ func (m *Monitor) Start() {
 		}
 
 		if m.StopOnEOF {
+			if m.cooldown != nil {
+				// Don't lose the summary of a cooldown still running at the end of the input
+				m.endCooldown(false)
+			}
 			if m.Verbose {
 				log.Printf("Monitor for %s stopped (StopOnEOF set).", m.Source.Name())
 			}
@@ -352,19 +962,101 @@ func (m *Monitor) Start() {
 		}
 
 		if m.Verbose {
-			log.Printf("Monitor for %s stopped, restarting in 1s...", m.Source.Name())
+			log.Printf("Monitor for %s stopped, restarting in %v...", m.Source.Name(), restartDelay)
 		}
 		select {
 		case <-m.ctx.Done():
 			return
-		case <-time.After(1 * time.Second):
+		case <-time.After(restartDelay):
 		}
 	}
 }
 
+// lineTruncated logs and counts a line longer than MaxScanTokenSize, of which
+// only the start is handled.
+func (m *Monitor) lineTruncated() {
+	m.metricTruncated.Inc()
+	log.Printf("[%s] Line longer than %d bytes, only its first %d bytes are checked", m.Source.Name(), MaxScanTokenSize, MaxScanTokenSize)
+}
+
 func (m *Monitor) watchdog() {
-	// Check at half the inactivity duration or at least every 100ms
-	interval := m.maxInactivity / 2
+	m.silenceWatchdog(m.maxInactivity, m.realertInterval, &m.lastReadTime, &m.inactivityAlerted,
+		func(silence time.Duration) {
+			if m.Verbose {
+				log.Printf("[%s] Inactivity detected: %v > %v", m.Source.Name(), silence, m.maxInactivity)
+			}
+			m.sendAlert("inactivity", sentry.LevelWarning, m.Source.Name()+": Monitor source inactivity detected (silence for "+silence.String()+")")
+		},
+		func() {
+			if m.Verbose {
+				log.Printf("[%s] Activity resumed.", m.Source.Name())
+			}
+			m.sendAlert("inactivity", sentry.LevelInfo, m.Source.Name()+": Monitor source activity resumed")
+		})
+}
+
+// startupWatchdog warns once if not a single line was read within
+// startupDeadline of started, which points to a misconfigured source (a wrong
+// path, an empty journal filter) rather than a quiet log.
+func (m *Monitor) startupWatchdog(started int64) {
+	timer := time.NewTimer(m.startupDeadline)
+	defer timer.Stop()
+	select {
+	case <-m.ctx.Done():
+		return
+	case <-timer.C:
+	}
+	if atomic.LoadInt64(&m.lastReadTime) != started {
+		return
+	}
+	atomic.StoreInt64(&m.startupSilentAt, started)
+	if m.Verbose {
+		log.Printf("[%s] No data read within %v of starting", m.Source.Name(), m.startupDeadline)
+	}
+	m.sendAlert("no_startup_data", sentry.LevelWarning, fmt.Sprintf("%s: no data read within %v of starting; the source may be misconfigured", m.Source.Name(), m.startupDeadline))
+}
+
+// noMatchHint sends a one-time hint once noMatchLines lines were read without
+// a single match, since that usually means the pattern has a typo.
+func (m *Monitor) noMatchHint() {
+	if m.noMatchLines == 0 || m.anyMatch || m.noMatchHinted || m.linesSeen < m.noMatchLines {
+		return
+	}
+	m.noMatchHinted = true
+	if m.Verbose {
+		log.Printf("[%s] No matches in %d lines", m.Source.Name(), m.linesSeen)
+	}
+	m.sendAlert("no_matches", sentry.LevelInfo, fmt.Sprintf("%s: %d lines read without a single match; the pattern may be wrong", m.Source.Name(), m.linesSeen))
+}
+
+// expectWatchdog alerts when no line matching the expected pattern is seen within expectWithin.
+func (m *Monitor) expectWatchdog() {
+	pattern := m.expectPattern
+	m.silenceWatchdog(m.expectWithin, m.realertInterval, &m.lastExpectedTime, &m.expectAlerted,
+		func(silence time.Duration) {
+			if m.Verbose {
+				log.Printf("[%s] Expected line not seen: %v > %v", m.Source.Name(), silence, m.expectWithin)
+			}
+			m.sendAlert("missing_expected", sentry.LevelWarning, m.Source.Name()+": Expected line matching '"+pattern+"' not seen for "+silence.String())
+		},
+		func() {
+			if m.Verbose {
+				log.Printf("[%s] Expected line seen again.", m.Source.Name())
+			}
+			m.sendAlert("missing_expected", sentry.LevelInfo, m.Source.Name()+": Expected line matching '"+pattern+"' seen again")
+		})
+}
+
+// silenceWatchdog periodically checks the time since *last (unix nano) and calls
+// onSilence once it exceeds maxSilence, then onResume once it is updated again.
+// alerted holds the alert state so each transition is reported only once.
+// With realert > 0, onSilence is held back until realert after the previous
+// call, so a source flapping around maxSilence doesn't send an alert and
+// recovery pair on every crossing. A silence that ends while held back is not
+// reported at all; one that lasts is reported once realert has passed.
+func (m *Monitor) silenceWatchdog(maxSilence, realert time.Duration, last *int64, alerted *int32, onSilence func(time.Duration), onResume func()) {
+	// Check at half the silence duration or at least every 100ms
+	interval := maxSilence / 2
 	if interval < 100*time.Millisecond {
 		interval = 100 * time.Millisecond
 	}
@@ -372,46 +1064,77 @@ func (m *Monitor) watchdog() {
 		interval = 10 * time.Second
 	}
 
+	// Stagger the first check by up to one interval, so monitors started
+	// together (e.g. across a fleet) do not check and alert in lockstep
+	m.rngMu.Lock()
+	delay := time.Duration(m.rng.Int63n(int64(interval)))
+	m.rngMu.Unlock()
+	select {
+	case <-m.ctx.Done():
+		return
+	case <-time.After(delay):
+	}
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	var lastAlert time.Time
 	for {
 		select {
 		case <-m.ctx.Done():
 			return
 		case <-ticker.C:
-			lastRead := time.Unix(0, atomic.LoadInt64(&m.lastReadTime))
-			silenceDuration := time.Since(lastRead)
-
-			if silenceDuration > m.maxInactivity {
-				if atomic.CompareAndSwapInt32(&m.inactivityAlerted, 0, 1) {
-					if m.Verbose {
-						log.Printf("[%s] Inactivity detected: %v > %v", m.Source.Name(), silenceDuration, m.maxInactivity)
-					}
-					m.Hub.WithScope(func(scope *sentry.Scope) {
-						scope.SetTag("source", m.Source.Name())
-						scope.SetTag("alert_type", "inactivity")
-						scope.SetLevel(sentry.LevelWarning)
-						m.Hub.CaptureMessage(m.Source.Name() + ": Monitor source inactivity detected (silence for " + silenceDuration.String() + ")")
-					})
+			silence := time.Since(time.Unix(0, atomic.LoadInt64(last)))
+			if silence > maxSilence {
+				if realert > 0 && !lastAlert.IsZero() && time.Since(lastAlert) < realert {
+					continue
 				}
-			} else {
-				if atomic.CompareAndSwapInt32(&m.inactivityAlerted, 1, 0) {
-					if m.Verbose {
-						log.Printf("[%s] Activity resumed.", m.Source.Name())
-					}
-					m.Hub.WithScope(func(scope *sentry.Scope) {
-						scope.SetTag("source", m.Source.Name())
-						scope.SetTag("alert_type", "inactivity")
-						scope.SetLevel(sentry.LevelInfo)
-						m.Hub.CaptureMessage(m.Source.Name() + ": Monitor source activity resumed")
-					})
+				if atomic.CompareAndSwapInt32(alerted, 0, 1) {
+					lastAlert = time.Now()
+					onSilence(silence)
 				}
+			} else if atomic.CompareAndSwapInt32(alerted, 1, 0) {
+				onResume()
 			}
 		}
 	}
 }
 
+// sendAlert sends a monitor-generated event (not a log line) to all hubs.
+func (m *Monitor) sendAlert(alertType string, level sentry.Level, message string) {
+	for _, hub := range m.hubs() {
+		hub.WithScope(func(scope *sentry.Scope) {
+			scope.SetTag("source", m.Source.Name())
+			scope.SetTag("alert_type", alertType)
+			scope.SetLevel(level)
+			hub.CaptureMessage(message)
+		})
+	}
+}
+
+// redactValue returns v with the redact patterns applied to the strings in it.
+// Context values need it on top of the line's redaction for fields decoded
+// from the line, such as decode_field; maps and slices are copied, not changed.
+func (m *Monitor) redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return string(m.redactor.TransformMessage([]byte(v)))
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[k] = m.redactValue(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = m.redactValue(val)
+		}
+		return out
+	}
+	return v
+}
+
 func (m *Monitor) extractMetadata(line []byte, tsStr string) BatchMetadata {
 	meta := BatchMetadata{
 		TimestampStr: tsStr,
@@ -430,222 +1153,779 @@ func (m *Monitor) extractMetadata(line []byte, tsStr string) BatchMetadata {
 			meta.Context = ctx
 		}
 	}
+	if m.contextStore != nil {
+		meta.Context = m.contextStore.merge(meta.Context)
+	}
+	if m.redactor != nil && meta.Context != nil {
+		meta.Context = m.redactValue(meta.Context).(map[string]any)
+	}
+	meta.Labels = m.lineLabels
+	meta.SyslogHeader = m.lineSyslogHeader
+	if meta.SyslogHeader == nil && !m.stripSyslogHeader {
+		meta.SyslogHeader = detectors.ParseSyslogHeader(line)
+	}
+	meta.Matched = time.Now()
 
 	return meta
 }
 
-func (m *Monitor) processMatch(line []byte) {
-	m.bufferMutex.Lock()
-	m.lastActivityTime = time.Now()
-
-	var timestamp float64
-	var tsStr string
-	var ok bool
-
-	if extractor, isExtractor := m.Detector.(detectors.TimestampExtractor); isExtractor {
-		timestamp, tsStr, ok = extractor.ExtractTimestamp(line)
+// groupKey returns the batch key for the line.
+// Lines are grouped by the value of the group_by context key; lines without it share the "" batch.
+func (m *Monitor) groupKey(line []byte) string {
+	if m.groupBy == "" {
+		return ""
 	}
-
+	extractor, ok := m.Detector.(detectors.ContextExtractor)
 	if !ok {
-		timestamp, tsStr = extractTimestamp(line)
+		return ""
+	}
+	ctx := extractor.GetContext(line)
+	if val, ok := ctx[m.groupBy]; ok && val != nil {
+		return fmt.Sprint(val)
+	}
+	return ""
+}
+
+func (m *Monitor) processMatch(line []byte) {
+	timestamp, tsStr := m.lineTimestamp(line)
+	if m.contextSource != nil {
+		m.lineRaw = line
 	}
 
 	if transformer, ok := m.Detector.(detectors.MessageTransformer); ok {
 		line = transformer.TransformMessage(line)
 	}
+	if m.stripSyslogHeader {
+		line, m.lineSyslogHeader = detectors.StripSyslogHeader(line)
+	}
+	if m.redactor != nil {
+		line = m.redactor.TransformMessage(line)
+	}
+	if m.OnMatch != nil {
+		if m.escapeControl {
+			m.OnMatch(escapeControlChars(line))
+		} else {
+			m.OnMatch(line)
+		}
+		return
+	}
 
-	var msgToSend string
-	var metaToSend BatchMetadata
+	m.bufferMutex.Lock()
+	now := time.Now()
 
-	if m.bufferCount == 0 {
-		m.buffer.Write(line)
-		m.bufferCount = 1
-		m.bufferStartTime = timestamp
-		m.currentBatchMeta = m.extractMetadata(line, tsStr)
-		m.resetTimerLocked()
-	} else {
-		// Check max buffer size to prevent memory leaks
-		if m.bufferCount >= MaxBufferSize || (m.buffer.Len()+len(line)) >= MaxBufferBytes {
-			// Force flush current buffer and start new
-			msgToSend = m.buffer.String()
-			metaToSend = m.currentBatchMeta
-
-			m.buffer.Reset()
-			m.buffer.Write(line)
-			m.bufferCount = 1
-			m.bufferStartTime = timestamp
-			m.currentBatchMeta = m.extractMetadata(line, tsStr)
-			m.resetTimerLocked()
-		} else {
-			// Group by 5 seconds window
-			if timestamp == 0 || (timestamp-m.bufferStartTime) <= 5.0 {
-				m.buffer.WriteByte('\n')
-				m.buffer.Write(line)
-				m.bufferCount++
-				m.resetTimerLocked()
-			} else {
-				// Flush current
-				msgToSend = m.buffer.String()
-				metaToSend = m.currentBatchMeta
-
-				m.buffer.Reset()
-				m.buffer.Write(line)
-				m.bufferCount = 1
-				m.bufferStartTime = timestamp
-				m.currentBatchMeta = m.extractMetadata(line, tsStr)
-				m.resetTimerLocked()
+	var toSend []pendingEvent
+
+	key := m.groupKey(line)
+	if len(m.lineLabels) > 0 {
+		// Lines from different pods (or other labelled origins) are never batched together
+		key = labelsKey(m.lineLabels) + "\x00" + key
+	}
+	b := m.batches[key]
+	if b == nil {
+		if len(m.batches) >= m.maxGroups {
+			if evicted := m.evictOldestLocked(); evicted.message != "" {
+				toSend = append(toSend, evicted)
 			}
 		}
+		b = m.newBatchLocked(key)
+	}
+	b.lastActivity = now
+
+	if b.count == 0 {
+		m.startBatchLocked(b, line, timestamp, tsStr)
+	} else if b.count >= m.maxBatchLines || (b.buffer.Len()+len(line)) >= m.maxBatchBytes {
+		// Check max buffer size to prevent memory leaks.
+		// Force flush current buffer and start new.
+		toSend = append(toSend, m.takeBatch(b, flushSize))
+		m.startBatchLocked(b, line, timestamp, tsStr)
+	} else if m.maxBatchAge > 0 && now.Sub(b.opened) >= m.maxBatchAge {
+		// Check max age so long bursts are delivered promptly
+		toSend = append(toSend, m.takeBatch(b, flushAge))
+		m.startBatchLocked(b, line, timestamp, tsStr)
+	} else if timestamp == 0 || (timestamp-b.startTime) <= m.groupWindow {
+		// Group by the group window; with a window of 0, only lines with the same timestamp
+		b.buffer.WriteString(m.batchSeparator)
+		if m.batchNewestFirst {
+			b.starts = append(b.starts, b.buffer.Len())
+		}
+		b.buffer.Write(line)
+		b.count++
+		b.meta.Repeats += m.lineRepeats
+		if m.matchDebug {
+			b.meta.MatchReasons = append(b.meta.MatchReasons, m.lineMatchReason)
+		}
+		m.resetTimerLocked(b)
+	} else {
+		// Flush current
+		toSend = append(toSend, m.takeBatch(b, flushTimeWindow))
+		m.startBatchLocked(b, line, timestamp, tsStr)
 	}
 	m.bufferMutex.Unlock()
 
-	if msgToSend != "" {
-		m.sendToSentry(msgToSend, metaToSend)
+	for _, ev := range toSend {
+		m.sendToSentry(ev.message, ev.meta)
 	}
 }
 
-func (m *Monitor) resetTimerLocked() {
-	if m.flushTimer != nil {
-		m.flushTimer.Stop()
-		m.flushTimer.Reset(FlushInterval)
+// labelsKey returns a stable string for a set of line labels.
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(labels[k])
+		sb.WriteByte(';')
+	}
+	return sb.String()
+}
+
+func (m *Monitor) newBatchLocked(key string) *batch {
+	m.batchSeq++
+	b := &batch{key: key, seq: m.batchSeq}
+	m.batches[key] = b
+	return b
+}
+
+func (m *Monitor) startBatchLocked(b *batch, line []byte, timestamp float64, tsStr string) {
+	b.buffer.Write(line)
+	if m.batchNewestFirst {
+		b.starts = append(b.starts[:0], 0)
+	}
+	b.count = 1
+	b.startTime = timestamp
+	b.opened = time.Now()
+	b.meta = m.extractMetadata(line, tsStr)
+	b.meta.Repeats = m.lineRepeats
+	if m.matchDebug {
+		b.meta.MatchReasons = []string{m.lineMatchReason}
+	}
+	if m.contextSource != nil {
+		b.meta.RawLine = bytes.Clone(m.lineRaw)
+		b.meta.RawOffset = m.lineOffset
+	}
+	m.resetTimerLocked(b)
+}
+
+// evictOldestLocked removes the least recently active batch to keep the number of groups bounded.
+func (m *Monitor) evictOldestLocked() pendingEvent {
+	var oldest *batch
+	for _, b := range m.batches {
+		if oldest == nil || b.lastActivity.Before(oldest.lastActivity) {
+			oldest = b
+		}
+	}
+	if oldest == nil {
+		return pendingEvent{}
+	}
+	stopTimerLocked(oldest)
+	delete(m.batches, oldest.key)
+	m.metricGroupEvicted.Inc()
+	if oldest.count == 0 {
+		return pendingEvent{}
+	}
+	return m.takeBatch(oldest, flushEvicted)
+}
+
+// Why a batch was sent, for the sentrylogmon_batch_flushes_total metric
+const (
+	flushTimer      = "timer"       // FlushInterval passed without a line for it
+	flushSize       = "size"        // max_batch_lines or max_batch_bytes reached
+	flushAge        = "age"         // max_batch_age reached
+	flushTimeWindow = "time_window" // a line fell outside the batch's group_window
+	flushEvicted    = "evicted"     // max_fingerprints reached
+	flushForce      = "force"       // end of the input, or the monitor stopping
+)
+
+// takeBatch returns the batch contents and resets it to empty, counting the
+// flush for the given reason.
+func (m *Monitor) takeBatch(b *batch, reason string) pendingEvent {
+	metrics.BatchFlushesTotal.WithLabelValues(m.Source.Name(), reason).Inc()
+	m.metricBatchLines.Observe(float64(b.count))
+	ev := pendingEvent{message: b.buffer.String(), meta: b.meta}
+	ev.meta.Lines = b.count
+	if m.batchNewestFirst && len(b.starts) > 1 {
+		ev.message = reverseLines(ev.message, b.starts, m.batchSeparator)
+	}
+	b.buffer.Reset()
+	b.starts = b.starts[:0]
+	b.count = 0
+	b.meta = BatchMetadata{}
+	return ev
+}
+
+// reverseLines returns the lines of s, which start at the given offsets and
+// are joined by sep, in reverse order.
+func reverseLines(s string, starts []int, sep string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := len(starts) - 1; i >= 0; i-- {
+		end := len(s)
+		if i+1 < len(starts) {
+			end = starts[i+1] - len(sep)
+		}
+		sb.WriteString(s[starts[i]:end])
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+	}
+	return sb.String()
+}
+
+// resetTimerLocked (re)starts the batch's flush timer. Each reset starts a new
+// generation; a timer that already fired and is waiting on bufferMutex carries
+// the old generation and is ignored by flushBuffer.
+func (m *Monitor) resetTimerLocked(b *batch) {
+	stopTimerLocked(b)
+	gen := b.gen
+	delay := FlushInterval
+	if m.maxBatchAge > 0 {
+		// Don't wait for a pause in a batch that has reached its max age
+		delay = min(delay, max(time.Until(b.opened.Add(m.maxBatchAge)), 0))
+	}
+	b.timer = time.AfterFunc(delay, func() {
+		m.flushBuffer(b, gen)
+	})
+}
+
+// stopTimerLocked stops the batch's flush timer and invalidates any callback already in flight.
+func stopTimerLocked(b *batch) {
+	if b.timer != nil {
+		b.timer.Stop()
 	}
+	b.gen++
 }
 
-func (m *Monitor) flushBuffer() {
+func (m *Monitor) flushBuffer(b *batch, gen uint64) {
 	m.bufferMutex.Lock()
-	// Check for staleness to handle race conditions
-	// If activity happened recently (less than FlushInterval), it means the timer was reset
-	// but this execution is from a previous firing that wasn't stopped in time (or just concurrent scheduling).
-	// We use a slightly smaller duration to allow for jitter.
-	if time.Since(m.lastActivityTime) < (FlushInterval - 100*time.Millisecond) {
+	// The batch may already have been flushed and replaced (e.g. evicted or force flushed),
+	// or its timer reset after this callback fired.
+	if m.batches[b.key] != b || b.gen != gen {
 		m.bufferMutex.Unlock()
 		return
 	}
 
-	if m.bufferCount == 0 {
+	delete(m.batches, b.key)
+	if b.count == 0 {
 		m.bufferMutex.Unlock()
 		return
 	}
 
-	msg := m.buffer.String()
-	meta := m.currentBatchMeta
-	m.buffer.Reset()
-	m.bufferCount = 0
-	m.currentBatchMeta = BatchMetadata{}
+	ev := m.takeBatch(b, flushTimer)
 	m.bufferMutex.Unlock()
 
-	m.sendToSentry(msg, meta)
+	m.sendToSentry(ev.message, ev.meta)
 }
 
+// forceFlush sends every pending batch, e.g. at the end of the input. Each
+// batch only holds its group's current time window, since processMatch sends
+// a window off as soon as a line falls outside it, so nothing is merged here.
 func (m *Monitor) forceFlush() {
 	m.bufferMutex.Lock()
-	if m.flushTimer != nil {
-		m.flushTimer.Stop()
+	pending := make([]*batch, 0, len(m.batches))
+	for _, b := range m.batches {
+		stopTimerLocked(b)
+		if b.count > 0 {
+			pending = append(pending, b)
+		}
 	}
+	m.batches = make(map[string]*batch)
 
-	if m.bufferCount == 0 {
-		m.bufferMutex.Unlock()
-		return
+	// Flush in creation order so output is deterministic across groups
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].seq < pending[j].seq
+	})
+	toSend := make([]pendingEvent, 0, len(pending))
+	for _, b := range pending {
+		toSend = append(toSend, m.takeBatch(b, flushForce))
 	}
-
-	msg := m.buffer.String()
-	meta := m.currentBatchMeta
-	m.buffer.Reset()
-	m.bufferCount = 0
-	m.currentBatchMeta = BatchMetadata{}
 	m.bufferMutex.Unlock()
 
-	m.sendToSentry(msg, meta)
+	for _, ev := range toSend {
+		m.sendToSentry(ev.message, ev.meta)
+	}
 }
 
 func (m *Monitor) sendToSentry(line string, meta BatchMetadata) {
+	// Startup noise (e.g. after a reboot) is counted but neither sent nor allowed to start a cooldown.
+	// A batch that started matching during warmup is suppressed even if it is flushed later.
+	matched := meta.Matched
+	if matched.IsZero() {
+		matched = time.Now()
+	}
+	if matched.Before(m.warmupUntil) {
+		m.metricWarmupSuppressed.Inc()
+		if m.Verbose {
+			log.Printf("[%s] In warmup, suppressing event.", m.Source.Name())
+		}
+		return
+	}
+	// Checked before cooldown and rate limits, so dropped events don't use them up.
+	// A matched line without a level is taken as an error, which is what detectors look for.
+	level := eventLevel(meta)
+	if levelRank(cmp.Or(level, sentry.LevelError)) < m.minSendRank {
+		m.metricBelowMinLevel.Inc()
+		if m.Verbose {
+			log.Printf("[%s] Level %s is below min_send_level, dropping event.", m.Source.Name(), level)
+		}
+		return
+	}
+	if m.dedup != nil && !m.dedupAllow(line, meta) {
+		if m.Verbose {
+			log.Printf("[%s] Message already reported, suppressing event.", m.Source.Name())
+		}
+		return
+	}
+	lines := max(meta.Lines, 1)
+	if m.cooldown != nil && !m.cooldownAllow(lines) {
+		if m.Verbose {
+			log.Printf("[%s] In cooldown, suppressing event.", m.Source.Name())
+		}
+		return
+	}
 	if m.RateLimiter != nil && !m.RateLimiter.Allow() {
 		m.metricSentryDropped.Inc()
+		if m.rateLimitAlerts {
+			m.rateLimitDropped()
+		}
 		if m.Verbose {
 			log.Printf("[%s] Rate limited, dropping event.", m.Source.Name())
 		}
 		return
 	}
+	if m.GlobalRateLimiter != nil && !m.GlobalRateLimiter.Allow() {
+		m.metricGlobalDropped.Inc()
+		if m.Verbose {
+			log.Printf("[%s] Global rate limit reached, dropping event.", m.Source.Name())
+		}
+		return
+	}
 
 	m.metricSentrySent.Inc()
 
-	m.Hub.WithScope(func(scope *sentry.Scope) {
-		scope.SetTag("source", m.Source.Name())
-
-		if meta.TimestampStr != "" {
-			scope.SetTag("log_timestamp", meta.TimestampStr)
-		}
-
-		if meta.SyslogPri != nil {
-			scope.SetTag("syslog_priority", strconv.Itoa(meta.SyslogPri.Pri))
-			scope.SetTag("syslog_facility", strconv.Itoa(meta.SyslogPri.Facility))
-			scope.SetTag("syslog_severity", strconv.Itoa(meta.SyslogPri.Severity))
-
-			// Map severity to Sentry Level
-			var level sentry.Level
-			switch meta.SyslogPri.Severity {
-			case 0, 1, 2: // Emergency, Alert, Critical
-				level = sentry.LevelFatal
-			case 3: // Error
-				level = sentry.LevelError
-			case 4: // Warning
-				level = sentry.LevelWarning
-			case 5, 6: // Notice, Informational
-				level = sentry.LevelInfo
-			case 7: // Debug
-				level = sentry.LevelDebug
-			default:
-				level = sentry.LevelInfo
+	message := m.eventMessage(line, meta)
+	title := m.eventTitle(line, meta)
+	if m.escapeControl {
+		message = string(escapeControlChars([]byte(message)))
+		title = string(escapeControlChars([]byte(title)))
+	}
+	m.recordRecent(message, lines, level)
+	attachment := m.contextAttachment(meta)
+	for _, hub := range m.routedHubs(level) {
+		hub.WithScope(func(scope *sentry.Scope) {
+			m.configureScope(scope, line, meta)
+			if attachment != nil {
+				scope.AddAttachment(attachment)
 			}
-			scope.SetLevel(level)
+			if fp := m.eventFingerprint(line, meta); fp != "" {
+				scope.SetFingerprint([]string{fp})
+			} else if m.titleFrom == "fingerprint" && title != "" {
+				// Group by the title instead of the whole batch
+				scope.SetFingerprint([]string{title})
+			}
+			if title != "" {
+				scope.SetTag("title", titleTag(title))
+			}
+
+			// We send the line (or its message_from field) as the message.
+			// Sentry will group these based on the message content.
+			hub.CaptureMessage(message)
+		})
+	}
+	m.export(message, level)
+}
+
+// traceIDs returns the trace and span IDs found under the trace_id_from and
+// span_id_from context keys. IDs that are not valid hex of the right length
+// (32 for traces, 16 for spans; dashes are ignored) are not returned.
+func (m *Monitor) traceIDs(meta BatchMetadata) (traceID, spanID string) {
+	if m.traceIDFrom == "" || meta.Context == nil {
+		return "", ""
+	}
+	traceID = normalizeHexID(meta.Context[m.traceIDFrom], 32)
+	if traceID == "" {
+		return "", ""
+	}
+	if m.spanIDFrom != "" {
+		spanID = normalizeHexID(meta.Context[m.spanIDFrom], 16)
+	}
+	return traceID, spanID
+}
+
+// eventUser returns the Sentry user found under the user_from context keys.
+func (m *Monitor) eventUser(meta BatchMetadata) (sentry.User, bool) {
+	if meta.Context == nil {
+		return sentry.User{}, false
+	}
+	value := func(key string) string {
+		if key == "" {
+			return ""
+		}
+		val, ok := meta.Context[key]
+		if !ok || val == nil {
+			return ""
 		}
+		// JSON numbers are float64; %v prints whole ones without a decimal point
+		return fmt.Sprint(val)
+	}
+	user := sentry.User{
+		ID:       value(m.userFrom.ID),
+		Email:    value(m.userFrom.Email),
+		Username: value(m.userFrom.Username),
+	}
+	return user, !user.IsEmpty()
+}
 
-		scope.SetExtra("raw_line", line)
+func normalizeHexID(val interface{}, length int) string {
+	s, ok := val.(string)
+	if !ok {
+		return ""
+	}
+	s = strings.ToLower(strings.ReplaceAll(s, "-", ""))
+	if len(s) != length {
+		return ""
+	}
+	if _, err := hex.DecodeString(s); err != nil {
+		return ""
+	}
+	return s
+}
 
-		if m.Collector != nil {
-			state := m.Collector.GetState()
-			// Use ToMap() to directly convert struct to map, avoiding double JSON marshaling
-			scope.SetContext("Server State", state.ToMap())
+// eventMessage returns the message_from context value when present, otherwise the raw line.
+func (m *Monitor) eventMessage(line string, meta BatchMetadata) string {
+	if m.messageFrom == "" {
+		return line
+	}
+	val, ok := meta.Context[m.messageFrom]
+	if !ok || val == nil {
+		return line
+	}
+	if msg := fmt.Sprint(val); msg != "" {
+		return msg
+	}
+	return line
+}
+
+// hubs returns the primary hub followed by any mirror hubs.
+func (m *Monitor) hubs() []*sentry.Hub {
+	if len(m.mirrorHubs) == 0 {
+		return []*sentry.Hub{m.Hub}
+	}
+	return append([]*sentry.Hub{m.Hub}, m.mirrorHubs...)
+}
+
+// ignored reports whether line contains one of the ignore substrings.
+func (m *Monitor) ignored(line []byte) bool {
+	for _, sub := range m.ignoreSubstrs {
+		if bytes.Contains(line, sub) {
+			return true
 		}
+	}
+	return false
+}
 
-		if meta.Context != nil {
-			scope.SetContext("Log Data", meta.Context)
+// tooShort reports whether line is shorter than min_line_length, ignoring
+// surrounding whitespace. Blank and tiny lines (e.g. a lone "}") only add noise.
+func (m *Monitor) tooShort(line []byte) bool {
+	return m.minLineLength > 0 && len(bytes.TrimSpace(line)) < m.minLineLength
+}
 
-			// Try to extract level/severity from context
-			var levelStr string
+// filtered reports whether min_line_length, ignore or the time window drop
+// line. Unlike handleLine it doesn't change the monitor, so parallel_detect
+// workers can check lines before running the detector on them.
+func (m *Monitor) filtered(line []byte) bool {
+	return m.tooShort(line) || m.ignored(line) || !m.inTimeWindow(line)
+}
 
-			for _, key := range severityKeys {
-				if val, ok := meta.Context[key]; ok {
-					if s, ok := val.(string); ok {
-						levelStr = strings.ToLower(s)
-						break
-					}
-				}
+// handleLine runs a line read from the source through the filters, the other
+// per-line checks and then the detector, in that order. det is the line's
+// detection if parallel_detect already ran it, or nil to run it here. Either
+// way, the detector only sees lines that passed the filters.
+func (m *Monitor) handleLine(lineBytes []byte, now time.Time, det *lineDetection) {
+	if m.noMatchLines > 0 {
+		m.noMatchHint()
+		m.linesSeen++
+	}
+	if m.tooShort(lineBytes) {
+		return
+	}
+	if m.ignored(lineBytes) {
+		m.metricIgnored.Inc()
+		return
+	}
+	if m.formatDrift != nil {
+		m.checkFormatDrift(lineBytes)
+	}
+	if !m.inTimeWindow(lineBytes) {
+		return
+	}
+	if m.expectDetector != nil && m.expectDetector.Detect(lineBytes) {
+		atomic.StoreInt64(&m.lastExpectedTime, now.UnixNano())
+	}
+	if m.contextStore != nil {
+		// Captured fields end up in events, so they are taken from the line as reported
+		contextLine := lineBytes
+		if m.redactor != nil {
+			contextLine = m.redactor.TransformMessage(lineBytes)
+		}
+		m.contextStore.feed(contextLine, m.lineTime(lineBytes, now))
+	}
+	if m.sequence != nil {
+		m.processSequence(lineBytes, now)
+	}
+	if m.Detector == nil {
+		return
+	}
+	if det == nil {
+		d := m.detectLine(lineBytes)
+		det = &d
+	}
+	if !det.matched {
+		return
+	}
+	if det.excluded {
+		if m.Verbose {
+			log.Printf("[%s] Excluded: %s", m.Source.Name(), string(lineBytes))
+		}
+		return
+	}
+	m.lineMatchReason = det.reason
+	m.metricIssuesDetected.Inc()
+	atomic.AddInt64(&m.throughput.matches, 1)
+	m.anyMatch = true
+	if m.Verbose {
+		log.Printf("[%s] Matched (%s): %s", m.Source.Name(), m.lineMatchReason, string(lineBytes))
+	}
+	m.processMatch(lineBytes)
+}
+
+// lineDetection is the result of running the detectors on a line.
+type lineDetection struct {
+	matched  bool
+	excluded bool   // matched, but so did the exclusion detector
+	reason   string // why the line matched, with matchDebug or Verbose set
+}
+
+// detectLine runs the detector on line, and the exclusion detector if it
+// matched. With matchDebug or Verbose set, it also gives why the line
+// matched: the detector's reason if it gives one, otherwise "pattern". It
+// doesn't change the monitor, so parallel_detect can run it on several lines
+// at once.
+func (m *Monitor) detectLine(line []byte) lineDetection {
+	var d lineDetection
+	if !m.matchDebug && !m.Verbose {
+		d.matched = m.Detector.Detect(line)
+	} else if rd, ok := m.Detector.(detectors.ReasonDetector); ok {
+		d.matched, d.reason = rd.DetectReason(line)
+	} else {
+		d.matched, d.reason = m.Detector.Detect(line), detectors.ReasonPattern
+	}
+	if d.matched && m.ExclusionDetector != nil {
+		d.excluded = m.ExclusionDetector.Detect(line)
+	}
+	return d
+}
+
+// Flush waits up to timeout for events queued on the monitor's own hubs to be
+// sent, and reports whether they all were. The global hub is not flushed here;
+// it is flushed by the caller that initialized it.
+func (m *Monitor) Flush(timeout time.Duration) bool {
+	hubs := m.hubs()
+	for _, r := range m.routes {
+		hubs = append(hubs, r.hub)
+	}
+	deadline := time.Now().Add(timeout)
+	flushed := true
+	for _, hub := range hubs {
+		if hub != sentry.CurrentHub() {
+			if !hub.Flush(time.Until(deadline)) {
+				flushed = false
 			}
+		}
+	}
+	return flushed
+}
 
-			if levelStr != "" {
-				var level sentry.Level
-				switch levelStr {
-				case "fatal", "critical", "alert", "emergency", "panic":
-					level = sentry.LevelFatal
-				case "error", "err":
-					level = sentry.LevelError
-				case "warning", "warn":
-					level = sentry.LevelWarning
-				case "info", "information":
-					level = sentry.LevelInfo
-				case "debug", "trace":
-					level = sentry.LevelDebug
-				}
+// configureScope sets the tags, level and contexts for an event built from line.
+func (m *Monitor) configureScope(scope *sentry.Scope, line string, meta BatchMetadata) {
+	// Runs after the scope is applied, so the estimate covers contexts and extras
+	scope.AddEventProcessor(func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		trimmed, fits := trimEvent(event, m.maxEventBytes)
+		if !fits {
+			m.metricOversize.Inc()
+			log.Printf("[%s] Event exceeded %d bytes even after trimming, dropped.", m.Source.Name(), m.maxEventBytes)
+			return nil
+		}
+		if trimmed {
+			m.metricOversize.Inc()
+			if m.Verbose {
+				log.Printf("[%s] Event exceeded %d bytes, trimmed before sending.", m.Source.Name(), m.maxEventBytes)
+			}
+		}
+		return event
+	})
+
+	if traceID, spanID := m.traceIDs(meta); traceID != "" {
+		// The scope always writes its own trace context, so this has to run after it is applied
+		scope.AddEventProcessor(func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+			trace := sentry.Context{"trace_id": traceID}
+			if spanID != "" {
+				trace["span_id"] = spanID
+			} else if existing, ok := event.Contexts["trace"]; ok {
+				trace["span_id"] = existing["span_id"]
+			}
+			if event.Contexts == nil {
+				event.Contexts = make(map[string]sentry.Context)
+			}
+			event.Contexts["trace"] = trace
+			return event
+		})
+	}
+
+	if user, ok := m.eventUser(meta); ok {
+		scope.SetUser(user)
+	}
+
+	// Static and extracted tags are set first so built-in tags (source, syslog_*) take precedence
+	for k, v := range m.staticTags {
+		scope.SetTag(k, v)
+	}
+	m.setExtractedTags(scope, meta)
+	scope.SetTag("source", m.Source.Name())
+	if len(meta.MatchReasons) > 0 {
+		scope.SetTag("match_reason", meta.MatchReasons[0])
+		scope.SetExtra("match_reasons", meta.MatchReasons)
+	}
+	if meta.AlertType != "" {
+		scope.SetTag("alert_type", meta.AlertType)
+	}
+
+	if meta.TimestampStr != "" {
+		scope.SetTag("log_timestamp", meta.TimestampStr)
+	}
+
+	if meta.SyslogPri != nil {
+		scope.SetTag("syslog_priority", strconv.Itoa(meta.SyslogPri.Pri))
+		scope.SetTag("syslog_facility", strconv.Itoa(meta.SyslogPri.Facility))
+		scope.SetTag("syslog_severity", strconv.Itoa(meta.SyslogPri.Severity))
+		if name := syslogFacilityName(meta.SyslogPri.Facility); name != "" {
+			scope.SetTag("syslog_facility_name", name)
+		}
+		if name := syslogSeverityName(meta.SyslogPri.Severity); name != "" {
+			scope.SetTag("syslog_severity_name", name)
+		}
+	}
+	if h := meta.SyslogHeader; h != nil {
+		headerCtx := map[string]interface{}{
+			"timestamp": h.Timestamp,
+			"host":      h.Host,
+			"app":       h.App,
+			"tag":       h.App, // the name before RFC 5424 support, kept for existing searches
+			"pid":       h.PID,
+		}
+		// RFC 5424 headers may leave any of these out
+		if h.Host != "" {
+			scope.SetTag("syslog_host", h.Host)
+		}
+		if h.App != "" {
+			scope.SetTag("syslog_app", h.App)
+			scope.SetTag("syslog_tag", h.App)
+		}
+		if h.PID != "" {
+			scope.SetTag("syslog_pid", h.PID)
+		}
+		if h.Version > 0 {
+			headerCtx["version"] = h.Version
+			headerCtx["msgid"] = h.MsgID
+		}
+		scope.SetContext("Syslog Header", headerCtx)
+	}
+	if level := eventLevel(meta); level != "" {
+		scope.SetLevel(level)
+	}
 
-				if level != "" {
-					scope.SetLevel(level)
+	if !m.omitRawLine {
+		raw := line
+		if m.rawLineMax > 0 {
+			raw = truncateString(raw, m.rawLineMax)
+		}
+		scope.SetExtra("raw_line", raw)
+	}
+	if meta.Repeats > 0 {
+		scope.SetExtra("repeated_lines", meta.Repeats)
+	}
+
+	if m.Collector != nil {
+		state := m.Collector.GetState()
+		// Use ToMap() to directly convert struct to map, avoiding double JSON marshaling
+		scope.SetContext("Server State", state.ToMap())
+	}
+
+	// Static context is kept separate from extracted "Log Data" so neither overwrites the other
+	if len(m.staticContext) > 0 {
+		scope.SetContext("Monitor Info", m.staticContext)
+	}
+	if len(m.deployInfo) > 0 {
+		scope.SetContext("Deploy", m.deployInfo)
+	}
+
+	if meta.Context != nil {
+		scope.SetContext("Log Data", meta.Context)
+
+		if unit, ok := meta.Context[SystemdUnitKey].(string); ok && unit != "" {
+			scope.SetTag("systemd_unit", unit)
+		}
+	}
+}
+
+// eventLevel returns the Sentry level of an event: from a level/severity field
+// of the extracted context, otherwise from the syslog severity. It returns ""
+// if neither is known.
+func eventLevel(meta BatchMetadata) sentry.Level {
+	if meta.Context != nil {
+		var levelStr string
+		for _, key := range severityKeys {
+			if val, ok := meta.Context[key]; ok {
+				if s, ok := val.(string); ok {
+					levelStr = strings.ToLower(s)
+					break
 				}
 			}
 		}
 
-		// We send the line as the message.
-		// Sentry will group these based on the message content.
-		m.Hub.CaptureMessage(line)
-	})
+		switch levelStr {
+		case "fatal", "critical", "alert", "emergency", "panic":
+			return sentry.LevelFatal
+		case "error", "err":
+			return sentry.LevelError
+		case "warning", "warn":
+			return sentry.LevelWarning
+		case "info", "information":
+			return sentry.LevelInfo
+		case "debug", "trace":
+			return sentry.LevelDebug
+		}
+	}
+
+	if meta.SyslogPri != nil {
+		switch meta.SyslogPri.Severity {
+		case 0, 1, 2: // Emergency, Alert, Critical
+			return sentry.LevelFatal
+		case 3: // Error
+			return sentry.LevelError
+		case 4: // Warning
+			return sentry.LevelWarning
+		case 5, 6: // Notice, Informational
+			return sentry.LevelInfo
+		case 7: // Debug
+			return sentry.LevelDebug
+		default:
+			return sentry.LevelInfo
+		}
+	}
+	return ""
 }
@@ -393,3 +393,21 @@ func TestMonitorMultiTenancy(t *testing.T) {
 		t.Errorf("mon2 DSN mismatch. Expected %s, got %s", customDSN, mon2.Hub.Client().Options().Dsn)
 	}
 }
+
+func TestMonitorSentrySampleRate(t *testing.T) {
+	mon, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{
+		SentryDSN:        "https://custom@sentry.io/2",
+		SentrySampleRate: 0.25,
+		SentryTargets:    []SentryTarget{{DSN: "https://mirror@sentry.io/3", SampleRate: 0.5}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	if got := mon.Hub.Client().Options().SampleRate; got != 0.25 {
+		t.Errorf("Expected sample rate 0.25, got %v", got)
+	}
+	if got := mon.mirrorHubs[0].Client().Options().SampleRate; got != 0.5 {
+		t.Errorf("Expected mirror sample rate 0.5, got %v", got)
+	}
+}
@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorNoMatchHint(t *testing.T) {
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, fmt.Sprintf("[%d.0] INFO request %d served", 100+i, i))
+	}
+
+	testCases := []struct {
+		name      string
+		input     string
+		wantHints int
+	}{
+		{
+			name:      "Pattern Never Matches",
+			input:     strings.Join(lines, "\n") + "\n",
+			wantHints: 1,
+		},
+		{
+			name:      "Pattern Matched Early",
+			input:     "[99.0] ERORR disk full\n" + strings.Join(lines, "\n") + "\n",
+			wantHints: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+			// A typo'd pattern, as the hint is meant to catch
+			det, err := detectors.NewGenericDetector("ERORR")
+			if err != nil {
+				t.Fatalf("Failed to create detector: %v", err)
+			}
+			mon, err := New(context.Background(), &MockSource{content: tc.input}, det, nil, Options{
+				NoMatchLines: 10,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+			sentry.Flush(time.Second)
+
+			hints := 0
+			for _, event := range snapshotEvents(transport) {
+				if event.Tags["alert_type"] != "no_matches" {
+					continue
+				}
+				hints++
+				if event.Level != sentry.LevelInfo || !strings.Contains(event.Message, "10 lines read without a single match") {
+					t.Errorf("Unexpected hint: level=%s message=%q", event.Level, event.Message)
+				}
+			}
+			if hints != tc.wantHints {
+				t.Errorf("Expected %d no_matches hints, got %d", tc.wantHints, hints)
+			}
+		})
+	}
+}
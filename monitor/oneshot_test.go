@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+// At EOF only the last, partial time window of each group is force-flushed;
+// earlier windows have already been split off as their own events.
+func TestOneshotKeepsTimeWindowsApart(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	input := `[100.0] api error: timeout
+[100.5] db error: deadlock
+[101.0] api error: timeout again
+[110.0] api error: refused
+[111.0] db error: disk full
+`
+	det, err := detectors.NewGenericDetector(`(?P<svc>api|db) error`)
+	if err != nil {
+		t.Fatalf("NewGenericDetector failed: %v", err)
+	}
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{GroupBy: "svc"})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	want := []string{
+		"[100.0] api error: timeout\n[101.0] api error: timeout again",
+		"[100.5] db error: deadlock",
+		"[110.0] api error: refused",
+		"[111.0] db error: disk full",
+	}
+	events := snapshotEvents(transport)
+	if len(events) != len(want) {
+		for i, e := range events {
+			t.Logf("Event %d: %q", i, e.Message)
+		}
+		t.Fatalf("Expected %d events, got %d", len(want), len(events))
+	}
+	for i, w := range want {
+		if events[i].Message != w {
+			t.Errorf("Event %d: expected %q, got %q", i, w, events[i].Message)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"bytes"
+	"time"
+)
+
+// Lines each detect worker may be given ahead of the line being handled
+const detectQueuePerWorker = 64
+
+// detectPool runs the detector on several lines at once, for sources where
+// detection (e.g. parsing JSON) keeps the scan loop busy. Everything else,
+// including context extraction of matched lines, still happens one line at a
+// time in the order the lines were read, on a single handler goroutine: lines
+// are queued in that order, and the handler waits for each line's detection
+// before handling it, so batches get their lines in order. Workers skip lines
+// the filters drop (see filtered), as handleLine would, so the detector only
+// sees lines that passed them.
+type detectPool struct {
+	jobs    chan *detectJob // to the workers
+	ordered chan *detectJob // to the handler, in the order read
+	done    chan struct{}   // closed once the handler has handled every line
+}
+
+type detectJob struct {
+	line    []byte
+	labels  map[string]string
+	repeats int
+	offset  int64
+	read    time.Time
+	det     lineDetection // zero if the line was filtered out
+	ready   chan struct{} // closed once det is set
+}
+
+// startDetectPool starts workers detect goroutines and the handler.
+func (m *Monitor) startDetectPool(workers int) *detectPool {
+	p := &detectPool{
+		jobs:    make(chan *detectJob, workers*detectQueuePerWorker),
+		ordered: make(chan *detectJob, workers*detectQueuePerWorker),
+		done:    make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range p.jobs {
+				if !m.filtered(j.line) {
+					j.det = m.detectLine(j.line)
+				}
+				close(j.ready)
+			}
+		}()
+	}
+	go func() {
+		defer close(p.done)
+		for j := range p.ordered {
+			<-j.ready
+			m.lineLabels = j.labels
+			m.lineRepeats = j.repeats
+			m.lineOffset = j.offset
+			m.handleLine(j.line, j.read, &j.det)
+		}
+	}()
+	return p
+}
+
+// submit queues a line read at read, with its labels, collapsed repeats and
+// offset in the stream, for detection and handling. It blocks while the
+// queue is full, i.e. reading is too far ahead of handling.
+func (p *detectPool) submit(line []byte, labels map[string]string, repeats int, offset int64, read time.Time) {
+	j := &detectJob{
+		// The scanner reuses its buffer for the next line
+		line:    bytes.Clone(line),
+		labels:  labels,
+		repeats: repeats,
+		offset:  offset,
+		read:    read,
+		ready:   make(chan struct{}),
+	}
+	p.ordered <- j
+	p.jobs <- j
+}
+
+// close stops the workers and waits for the handler to handle the lines
+// already submitted.
+func (p *detectPool) close() {
+	close(p.jobs)
+	close(p.ordered)
+	<-p.done
+}
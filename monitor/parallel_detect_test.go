@@ -0,0 +1,142 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+// unevenDetector takes longer on some lines than others, so parallel workers
+// finish them out of order.
+type unevenDetector struct{}
+
+func (d *unevenDetector) Detect(line []byte) bool {
+	n, _ := strconv.Atoi(strings.TrimPrefix(string(line), "error "))
+	time.Sleep(time.Duration(n%4) * 100 * time.Microsecond)
+	return n%3 != 0
+}
+
+func TestParallelDetectPreservesOrder(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	var input strings.Builder
+	var want []string
+	for i := 1; i <= 300; i++ {
+		line := fmt.Sprintf("error %d", i)
+		fmt.Fprintln(&input, line)
+		if i%3 != 0 {
+			want = append(want, line)
+		}
+	}
+	// Without timestamps, all matched lines go into one batch
+	mon, err := New(context.Background(), &MockSource{content: input.String()}, &unevenDetector{}, nil, Options{
+		ParallelDetect: 4,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 batched event, got %d", len(events))
+	}
+	if got := strings.Split(events[0].Message, "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected the %d matched lines in the order read, got %d lines starting %q", len(want), len(got), got[:min(5, len(got))])
+	}
+}
+
+// seenDetector records the lines it is run on.
+type seenDetector struct {
+	mu   sync.Mutex
+	seen []string
+}
+
+func (d *seenDetector) Detect(line []byte) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seen = append(d.seen, string(line))
+	return true
+}
+
+func TestParallelDetectSkipsFilteredLines(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det := &seenDetector{}
+	mon, err := New(context.Background(), &MockSource{content: "error 1\n}\nhealthcheck error\nerror 2\n"}, det, nil, Options{
+		ParallelDetect:   2,
+		MinLineLength:    2,
+		IgnoreSubstrings: []string{"healthcheck"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	det.mu.Lock()
+	seen := slices.Sorted(slices.Values(det.seen))
+	det.mu.Unlock()
+	if want := []string{"error 1", "error 2"}; !slices.Equal(seen, want) {
+		t.Errorf("Expected the detector to see only %q, got %q", want, seen)
+	}
+}
+
+func TestParallelDetectRejectsOrderedDetector(t *testing.T) {
+	_, err := New(context.Background(), &MockSource{}, detectors.NewDmesgDetector(), nil, Options{ParallelDetect: 2})
+	if err == nil || !strings.Contains(err.Error(), "parallel_detect") {
+		t.Errorf("Expected a parallel_detect error for the dmesg detector, got %v", err)
+	}
+}
+
+func BenchmarkParallelDetect(b *testing.B) {
+	var input strings.Builder
+	for i := 0; i < 20000; i++ {
+		level := "info"
+		if i%10 == 0 {
+			level = "error"
+		}
+		fmt.Fprintf(&input, `{"time":"2026-03-01T10:00:%02dZ","level":"%s","msg":"request %d handled","user":{"id":%d,"roles":["a","b"]},"path":"/api/v1/items/%d","duration_ms":%d}`+"\n",
+			i%60, level, i, i%100, i, i%500)
+	}
+	content := input.String()
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			det, err := detectors.NewJsonDetector("level:error")
+			if err != nil {
+				b.Fatalf("Failed to create detector: %v", err)
+			}
+			b.SetBytes(int64(len(content)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				mon, err := New(context.Background(), &MockSource{content: content}, det, nil, Options{
+					ParallelDetect: workers,
+				})
+				if err != nil {
+					b.Fatalf("Failed to create monitor: %v", err)
+				}
+				mon.StopOnEOF = true
+				mon.OnMatch = func([]byte) {}
+				mon.Start()
+			}
+		})
+	}
+}
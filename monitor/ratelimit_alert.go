@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// rateLimitDropped records an event dropped by RateLimiter, warning at the
+// first drop of a storm.
+func (m *Monitor) rateLimitDropped() {
+	atomic.StoreInt64(&m.lastDropTime, time.Now().UnixNano())
+	atomic.AddInt64(&m.stormDrops, 1)
+	if atomic.CompareAndSwapInt32(&m.rateLimitQuiet, 1, 0) {
+		m.rateLimitStorm()
+	}
+}
+
+// rateLimitWatchdog notes the end of a storm once no event was dropped for a
+// rate limit window. It is the inactivity watchdog turned around: silence is
+// the healthy state, and rateLimitQuiet is set while there is silence.
+func (m *Monitor) rateLimitWatchdog() {
+	m.silenceWatchdog(m.RateLimiter.window, 0, &m.lastDropTime, &m.rateLimitQuiet,
+		func(time.Duration) {
+			dropped := atomic.SwapInt64(&m.stormDrops, 0)
+			if m.Verbose {
+				log.Printf("[%s] Rate limiting stopped after dropping %d events", m.Source.Name(), dropped)
+			}
+			m.sendAlert("rate_limited", sentry.LevelInfo, fmt.Sprintf("%s: rate limiting stopped; %d events were dropped", m.Source.Name(), dropped))
+		},
+		// A drop seen by the watchdog before rateLimitDropped claimed it
+		m.rateLimitStorm)
+}
+
+// rateLimitStorm warns (alert_type=rate_limited) that events are being dropped.
+func (m *Monitor) rateLimitStorm() {
+	if m.Verbose {
+		log.Printf("[%s] Rate limit reached, dropping events", m.Source.Name())
+	}
+	m.sendAlert("rate_limited", sentry.LevelWarning, fmt.Sprintf("%s: rate limit of %d events per %v reached; further events are dropped until the rate falls",
+		m.Source.Name(), m.RateLimiter.limit, m.RateLimiter.window))
+}
@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestRateLimitAlertsOncePerStorm(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := NewMockPipeSource()
+	mon, err := New(ctx, source, &MockDetector{}, nil, Options{
+		RateLimitBurst:  1,
+		RateLimitWindow: "200ms",
+		RateLimitAlerts: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	done := make(chan struct{})
+	go func() {
+		mon.Start()
+		close(done)
+	}()
+
+	// Lines 10s apart are separate events; each sends the one before it
+	storm := func(start int) {
+		for i := 0; i < 5; i++ {
+			source.Write([]byte(fmt.Sprintf("[%d.0] Line %d\n", start+i*10, i)))
+		}
+	}
+
+	storm(100)
+	time.Sleep(700 * time.Millisecond)
+	sentry.Flush(time.Second)
+	if got := countAlerts(transport, "rate_limited", sentry.LevelWarning); got != 1 {
+		t.Errorf("Expected 1 rate_limited warning for the first storm, got %d", got)
+	}
+	if got := countAlerts(transport, "rate_limited", sentry.LevelInfo); got != 1 {
+		t.Errorf("Expected 1 rate_limited recovery after the first storm, got %d", got)
+	}
+	for _, e := range snapshotEvents(transport) {
+		if e.Tags["alert_type"] == "rate_limited" && e.Level == sentry.LevelInfo && !strings.Contains(e.Message, "3 events were dropped") {
+			t.Errorf("Expected the recovery to count 3 dropped events, got %q", e.Message)
+		}
+	}
+
+	storm(200)
+	time.Sleep(700 * time.Millisecond)
+	sentry.Flush(time.Second)
+	if got := countAlerts(transport, "rate_limited", sentry.LevelWarning); got != 2 {
+		t.Errorf("Expected 2 rate_limited warnings after the second storm, got %d", got)
+	}
+	if got := countAlerts(transport, "rate_limited", sentry.LevelInfo); got != 2 {
+		t.Errorf("Expected 2 rate_limited recoveries after the second storm, got %d", got)
+	}
+
+	source.Close()
+	<-done
+}
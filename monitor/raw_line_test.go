@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestRawLineExtra(t *testing.T) {
+	const line = "Error: connection reset by peer"
+
+	testCases := []struct {
+		name        string
+		opts        Options
+		expectRaw   bool
+		expectedRaw string
+	}{
+		{
+			name:        "Included By Default",
+			expectRaw:   true,
+			expectedRaw: line,
+		},
+		{
+			name: "Omitted",
+			opts: Options{OmitRawLine: true},
+		},
+		{
+			name:        "Capped",
+			opts:        Options{RawLineMaxBytes: 16},
+			expectRaw:   true,
+			expectedRaw: "Error: connectio" + truncatedSuffix,
+		},
+		{
+			name:        "Cap Larger Than Line",
+			opts:        Options{RawLineMaxBytes: 1024},
+			expectRaw:   true,
+			expectedRaw: line,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+
+			mon, err := New(context.Background(), &MockSource{content: line + "\n"}, &MockDetector{}, nil, tc.opts)
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+
+			sentry.Flush(time.Second)
+
+			transport.mu.Lock()
+			defer transport.mu.Unlock()
+
+			if len(transport.events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(transport.events))
+			}
+			event := transport.events[0]
+			if event.Message != line {
+				t.Errorf("Expected message %q, got %q", line, event.Message)
+			}
+			raw, ok := event.Extra["raw_line"]
+			if ok != tc.expectRaw {
+				t.Fatalf("Expected raw_line present=%v, got %v", tc.expectRaw, event.Extra)
+			}
+			if ok && raw != tc.expectedRaw {
+				t.Errorf("Expected raw_line %q, got %q", tc.expectedRaw, raw)
+			}
+		})
+	}
+}
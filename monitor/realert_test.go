@@ -0,0 +1,53 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestRealertIntervalDebouncesFlappingSource(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := NewMockPipeSource()
+	mon, err := New(ctx, source, &MockDetector{}, nil, Options{
+		MaxInactivity:   "100ms",
+		RealertInterval: "1500ms",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	go mon.Start()
+	defer source.Close()
+
+	// Silent for a little longer than max_inactivity, then active again,
+	// which without realert_interval alerts and recovers every time
+	for i := 0; i < 3; i++ {
+		source.Write([]byte("tick\n"))
+		time.Sleep(300 * time.Millisecond)
+	}
+	source.Write([]byte("tick\n"))
+	time.Sleep(50 * time.Millisecond)
+	sentry.Flush(time.Second)
+	if got := countAlerts(transport, "inactivity", sentry.LevelWarning); got != 1 {
+		t.Errorf("Expected 1 inactivity warning while flapping, got %d", got)
+	}
+	if got := countAlerts(transport, "inactivity", sentry.LevelInfo); got != 1 {
+		t.Errorf("Expected 1 recovery while flapping, got %d", got)
+	}
+
+	// A silence that outlasts the interval is still reported
+	time.Sleep(1500 * time.Millisecond)
+	sentry.Flush(time.Second)
+	if got := countAlerts(transport, "inactivity", sentry.LevelWarning); got != 2 {
+		t.Errorf("Expected a second inactivity warning after realert_interval, got %d", got)
+	}
+}
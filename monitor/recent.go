@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// RecentEventsSize is how many of its last events each monitor keeps for
+// sentrylogmon --tail.
+var RecentEventsSize = 50
+
+// Messages of recent events are cut to this many bytes
+const recentMessageMax = 500
+
+// recentSeq numbers events across all monitors in the process, so a client
+// polling for recent events can ask for only those it hasn't seen.
+var recentSeq atomic.Uint64
+
+// RecentEvent summarizes an event the monitor sent to Sentry.
+type RecentEvent struct {
+	Seq     uint64
+	Time    time.Time
+	Source  string
+	Level   string
+	Message string // the first line, as the event is titled in Sentry
+	Lines   int    // lines in the event, more than one for batches
+}
+
+// recentEvents is a ring of the last events sent.
+type recentEvents struct {
+	mu     sync.Mutex
+	events []RecentEvent
+	next   int // where the next event goes once the ring is full
+}
+
+func (r *recentEvents) add(ev RecentEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) < RecentEventsSize {
+		r.events = append(r.events, ev)
+		return
+	}
+	if len(r.events) == 0 {
+		return
+	}
+	r.events[r.next] = ev
+	r.next = (r.next + 1) % len(r.events)
+}
+
+// snapshot returns the events oldest first.
+func (r *recentEvents) snapshot() []RecentEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]RecentEvent, 0, len(r.events))
+	out = append(out, r.events[r.next:]...)
+	return append(out, r.events[:r.next]...)
+}
+
+// recordRecent adds an event that is being sent, made of lines log lines, to
+// the recent events.
+func (m *Monitor) recordRecent(message string, lines int, level sentry.Level) {
+	if level == "" {
+		level = sentry.LevelInfo
+	}
+	first, _, _ := strings.Cut(message, "\n")
+	if len(first) > recentMessageMax {
+		first = first[:recentMessageMax] + "..."
+	}
+	m.recent.add(RecentEvent{
+		Seq:     recentSeq.Add(1),
+		Time:    time.Now(),
+		Source:  m.Source.Name(),
+		Level:   string(level),
+		Message: first,
+		Lines:   lines,
+	})
+}
+
+// RecentEvents returns the last events the monitor sent, oldest first.
+func (m *Monitor) RecentEvents() []RecentEvent {
+	return m.recent.snapshot()
+}
@@ -0,0 +1,18 @@
+package monitor
+
+import "testing"
+
+func TestRecentEventsRing(t *testing.T) {
+	prev := RecentEventsSize
+	RecentEventsSize = 3
+	t.Cleanup(func() { RecentEventsSize = prev })
+
+	var r recentEvents
+	for i := 1; i <= 5; i++ {
+		r.add(RecentEvent{Seq: uint64(i)})
+	}
+	got := r.snapshot()
+	if len(got) != 3 || got[0].Seq != 3 || got[1].Seq != 4 || got[2].Seq != 5 {
+		t.Errorf("Expected the last 3 events oldest first, got %+v", got)
+	}
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestRedactPatterns(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("sentry.Init failed: %v", err)
+	}
+
+	source := &MockSource{content: "Error: payment failed card=4111111111111111 user=alice\n"}
+	mon, err := New(context.Background(), source, &MockDetector{}, nil, Options{
+		Redact: []string{`card=\d+`, `user=\w+`},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	msg := transport.events[0].Message
+	if msg != "Error: payment failed [REDACTED] [REDACTED]" {
+		t.Errorf("Unexpected message: %q", msg)
+	}
+	if strings.Contains(msg, "alice") {
+		t.Errorf("Expected user to be redacted, got %q", msg)
+	}
+}
+
+func TestOnMatchSkipsSentry(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("sentry.Init failed: %v", err)
+	}
+
+	source := &MockSource{content: "Error one\nError two\n"}
+	mon, err := New(context.Background(), source, &MockDetector{}, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	var got []string
+	mon.StopOnEOF = true
+	mon.OnMatch = func(line []byte) { got = append(got, string(line)) }
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	if len(got) != 2 || got[0] != "Error one" || got[1] != "Error two" {
+		t.Errorf("Unexpected OnMatch lines: %q", got)
+	}
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != 0 {
+		t.Errorf("Expected no events with OnMatch set, got %d", len(transport.events))
+	}
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Route sends events whose level is between MinLevel and MaxLevel (inclusive)
+// to Target instead of the monitor's own Sentry projects. An empty bound is
+// open. Levels are debug, info, warning, error and fatal.
+type Route struct {
+	MinLevel string
+	MaxLevel string
+	Target   SentryTarget
+}
+
+// levelRanks orders the Sentry levels from least to most severe.
+var levelRanks = map[sentry.Level]int{
+	sentry.LevelDebug:   0,
+	sentry.LevelInfo:    1,
+	sentry.LevelWarning: 2,
+	sentry.LevelError:   3,
+	sentry.LevelFatal:   4,
+}
+
+type route struct {
+	min, max int
+	hub      *sentry.Hub
+}
+
+func newRoute(r Route, httpTransport http.RoundTripper) (route, error) {
+	rt := route{min: 0, max: len(levelRanks) - 1}
+	if r.MinLevel != "" {
+		rank, ok := parseLevelRank(r.MinLevel)
+		if !ok {
+			return rt, fmt.Errorf("invalid route min_level '%s'", r.MinLevel)
+		}
+		rt.min = rank
+	}
+	if r.MaxLevel != "" {
+		rank, ok := parseLevelRank(r.MaxLevel)
+		if !ok {
+			return rt, fmt.Errorf("invalid route max_level '%s'", r.MaxLevel)
+		}
+		rt.max = rank
+	}
+	hub, err := newHub(r.Target, httpTransport)
+	if err != nil {
+		return rt, err
+	}
+	rt.hub = hub
+	return rt, nil
+}
+
+func parseLevelRank(s string) (int, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warn" {
+		s = string(sentry.LevelWarning)
+	}
+	rank, ok := levelRanks[sentry.Level(s)]
+	return rank, ok
+}
+
+// levelRank returns the rank of level in levelRanks. Events without a level
+// are info, as Sentry records them.
+func levelRank(level sentry.Level) int {
+	if rank, ok := levelRanks[level]; ok {
+		return rank
+	}
+	return levelRanks[sentry.LevelInfo]
+}
+
+// routedHubs returns the hubs an event of the given level goes to: those of
+// the matching routes, or the monitor's own hubs if no route matches.
+func (m *Monitor) routedHubs(level sentry.Level) []*sentry.Hub {
+	if len(m.routes) == 0 {
+		return m.hubs()
+	}
+	rank := levelRank(level)
+	var hubs []*sentry.Hub
+	for _, r := range m.routes {
+		if rank >= r.min && rank <= r.max {
+			hubs = append(hubs, r.hub)
+		}
+	}
+	if len(hubs) == 0 {
+		return m.hubs()
+	}
+	return hubs
+}
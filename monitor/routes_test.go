@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorRoutesByLevel(t *testing.T) {
+	paging := &MockTransport{}
+	dashboard := &MockTransport{}
+	fallback := &MockTransport{}
+
+	mon, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{
+		Routes: []Route{
+			{MinLevel: "error", Target: SentryTarget{DSN: "http://public@127.0.0.1:1/2"}},
+			{MinLevel: "info", MaxLevel: "warn", Target: SentryTarget{DSN: "http://public@127.0.0.1:1/3"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	if len(mon.routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %d", len(mon.routes))
+	}
+	mon.routes[0].hub = newMockHub(t, paging)
+	mon.routes[1].hub = newMockHub(t, dashboard)
+	mon.Hub = newMockHub(t, fallback)
+
+	mon.sendToSentry("disk failed", BatchMetadata{Context: map[string]interface{}{"level": "error"}})
+	mon.sendToSentry("disk almost full", BatchMetadata{Context: map[string]interface{}{"level": "warning"}})
+	mon.sendToSentry("verbose trace", BatchMetadata{Context: map[string]interface{}{"level": "debug"}})
+	// Without a level an event is info
+	mon.sendToSentry("plain line", BatchMetadata{})
+	mon.Flush(time.Second)
+
+	tests := []struct {
+		name      string
+		transport *MockTransport
+		want      []sentry.Level
+	}{
+		{"paging", paging, []sentry.Level{sentry.LevelError}},
+		{"dashboard", dashboard, []sentry.Level{sentry.LevelWarning, sentry.LevelInfo}},
+		{"fallback", fallback, []sentry.Level{sentry.LevelDebug}},
+	}
+	for _, tt := range tests {
+		events := snapshotEvents(tt.transport)
+		if len(events) != len(tt.want) {
+			t.Errorf("%s: expected %d events, got %d", tt.name, len(tt.want), len(events))
+			continue
+		}
+		for i, level := range tt.want {
+			// The plain line has no level set; Sentry records it as info
+			if got := events[i].Level; got != level && !(level == sentry.LevelInfo && got == "") {
+				t.Errorf("%s: event %d has level %q, want %q", tt.name, i, got, level)
+			}
+		}
+	}
+}
+
+func TestNewRouteInvalidLevel(t *testing.T) {
+	_, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{
+		Routes: []Route{{MinLevel: "loud", Target: SentryTarget{DSN: "http://public@127.0.0.1:1/2"}}},
+	})
+	if err == nil {
+		t.Fatal("Expected an error for an unknown route level")
+	}
+}
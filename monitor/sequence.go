@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+)
+
+// sequenceMatcher recognizes lines matching its patterns in order, with the
+// last one arriving within a window of the first. It is only used from the
+// scan loop, so it needs no locking.
+type sequenceMatcher struct {
+	steps  []detectors.Detector
+	within time.Duration
+
+	step  int       // index of the next pattern to match
+	start time.Time // time of the line that matched the first pattern
+	lines []string
+}
+
+func newSequenceMatcher(patterns []string, within time.Duration) (*sequenceMatcher, error) {
+	s := &sequenceMatcher{within: within}
+	for _, p := range patterns {
+		d, err := detectors.NewGenericDetector(p)
+		if err != nil {
+			return nil, err
+		}
+		s.steps = append(s.steps, d)
+	}
+	return s, nil
+}
+
+// feed advances the state machine with a line seen at t. When the line
+// completes the sequence it returns all lines of the sequence and resets.
+func (s *sequenceMatcher) feed(line []byte, t time.Time) ([]string, bool) {
+	if s.step > 0 && t.Sub(s.start) > s.within {
+		s.reset()
+	}
+
+	if s.step > 0 && s.steps[s.step].Detect(line) {
+		s.lines = append(s.lines, string(line))
+		s.step++
+		if s.step == len(s.steps) {
+			lines := s.lines
+			s.reset()
+			return lines, true
+		}
+		return nil, false
+	}
+
+	// A new first line restarts the sequence, so the window is measured from the latest one
+	if s.steps[0].Detect(line) {
+		s.step = 1
+		s.start = t
+		s.lines = []string{string(line)}
+		if len(s.steps) == 1 {
+			lines := s.lines
+			s.reset()
+			return lines, true
+		}
+	}
+	return nil, false
+}
+
+func (s *sequenceMatcher) reset() {
+	s.step = 0
+	s.lines = nil
+}
+
+// lineTime returns the line's own timestamp if it has one, otherwise now.
+func (m *Monitor) lineTime(line []byte, now time.Time) time.Time {
+	if ts, tsStr := m.lineTimestamp(line); tsStr != "" {
+		sec := int64(ts)
+		return time.Unix(sec, int64((ts-float64(sec))*1e9))
+	}
+	return now
+}
+
+// processSequence feeds the line to the sequence rule and reports a completed sequence as one event.
+func (m *Monitor) processSequence(line []byte, now time.Time) {
+	lines, ok := m.sequence.feed(line, m.lineTime(line, now))
+	if !ok {
+		return
+	}
+	if m.Verbose {
+		log.Printf("[%s] Sequence matched: %d lines", m.Source.Name(), len(lines))
+	}
+	if m.redactor != nil {
+		for i, l := range lines {
+			lines[i] = string(m.redactor.TransformMessage([]byte(l)))
+		}
+	}
+	first := []byte(lines[0])
+	_, tsStr := m.lineTimestamp(first)
+	meta := m.extractMetadata(first, tsStr)
+	meta.AlertType = "sequence"
+	meta.Lines = len(lines)
+	m.sendToSentry(strings.Join(lines, m.batchSeparator), meta)
+}
@@ -0,0 +1,121 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestSequenceMatcher(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		lines    []string
+		offsets  []time.Duration
+		expected []string
+	}{
+		{
+			name:     "In Order Within Window",
+			lines:    []string{"db: connection lost", "unrelated", "api: 503 returned"},
+			offsets:  []time.Duration{0, time.Second, 5 * time.Second},
+			expected: []string{"db: connection lost", "api: 503 returned"},
+		},
+		{
+			name:    "Out Of Order",
+			lines:   []string{"api: 503 returned", "db: connection lost"},
+			offsets: []time.Duration{0, time.Second},
+		},
+		{
+			name:    "Too Far Apart",
+			lines:   []string{"db: connection lost", "api: 503 returned"},
+			offsets: []time.Duration{0, 31 * time.Second},
+		},
+		{
+			name:     "Window Restarts From Latest First Line",
+			lines:    []string{"db: connection lost", "db: connection lost again", "api: 503 returned"},
+			offsets:  []time.Duration{0, 25 * time.Second, 40 * time.Second},
+			expected: []string{"db: connection lost again", "api: 503 returned"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := newSequenceMatcher([]string{"connection lost", "503"}, 30*time.Second)
+			if err != nil {
+				t.Fatalf("Failed to create sequence matcher: %v", err)
+			}
+
+			var got []string
+			matches := 0
+			for i, line := range tc.lines {
+				if lines, ok := s.feed([]byte(line), base.Add(tc.offsets[i])); ok {
+					got = lines
+					matches++
+				}
+			}
+
+			if tc.expected == nil {
+				if matches != 0 {
+					t.Errorf("Expected no match, got %v", got)
+				}
+				return
+			}
+			if matches != 1 {
+				t.Fatalf("Expected 1 match, got %d", matches)
+			}
+			if strings.Join(got, "|") != strings.Join(tc.expected, "|") {
+				t.Errorf("Expected lines %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMonitorSequenceEvent(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	input := `2026-03-01T10:00:00Z db: connection lost
+2026-03-01T10:00:02Z worker: retrying
+2026-03-01T10:00:05Z api: 503 returned
+2026-03-01T10:10:00Z api: 503 returned
+`
+	// No line detector; only the sequence rule reports events
+	mon, err := New(context.Background(), &MockSource{content: input}, nil, nil, Options{
+		Sequence:       []string{"connection lost", "503"},
+		SequenceWithin: "30s",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	event := transport.events[0]
+	expected := "2026-03-01T10:00:00Z db: connection lost\n2026-03-01T10:00:05Z api: 503 returned"
+	if event.Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, event.Message)
+	}
+	if event.Tags["alert_type"] != "sequence" {
+		t.Errorf("Expected alert_type=sequence, got %q", event.Tags["alert_type"])
+	}
+	if event.Tags["log_timestamp"] == "" {
+		t.Error("Expected log_timestamp from the first line")
+	}
+}
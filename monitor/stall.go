@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// errSourceStalled ends the scan of a source that returned no data within
+// Options.ReadTimeout.
+var errSourceStalled = errors.New("no data read within read_timeout")
+
+// stallMaxBackoff caps the wait before restarting a source that keeps stalling.
+const stallMaxBackoff = time.Minute
+
+// stallReader fails with errSourceStalled once a read of r has not returned
+// within timeout, for sources that can block forever with neither data nor
+// EOF, such as a hung SSH connection. The blocked read is left running until
+// the source is closed; the stallReader must not be used after it stalled.
+type stallReader struct {
+	r       io.Reader
+	timeout time.Duration
+	buf     []byte // read into by the pending read, so a late result can't touch the caller's p
+	results chan stallResult
+	stalled bool
+}
+
+type stallResult struct {
+	n   int
+	err error
+}
+
+func newStallReader(r io.Reader, timeout time.Duration) *stallReader {
+	// Buffered, so a read that returns after the stall doesn't leak its goroutine
+	return &stallReader{r: r, timeout: timeout, results: make(chan stallResult, 1)}
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	if s.stalled {
+		return 0, errSourceStalled
+	}
+	if cap(s.buf) < len(p) {
+		s.buf = make([]byte, len(p))
+	}
+	buf := s.buf[:len(p)]
+	go func() {
+		n, err := s.r.Read(buf)
+		s.results <- stallResult{n, err}
+	}()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case res := <-s.results:
+		return copy(p, buf[:res.n]), res.err
+	case <-timer.C:
+		s.stalled = true
+		return 0, errSourceStalled
+	}
+}
+
+// sourceStalled closes a source whose read timed out, which unblocks the read
+// (e.g. kills a hung command), warns (alert_type=source_stalled) on the first
+// of consecutive stalls, and returns how long to wait before restarting the
+// source: 1s, doubling with each further stall up to stallMaxBackoff.
+func (m *Monitor) sourceStalled() time.Duration {
+	if err := m.Source.Close(); err != nil {
+		log.Printf("[%s] Error closing stalled source: %v", m.Source.Name(), err)
+	}
+	m.stalls++
+	delay := min(time.Second<<min(m.stalls-1, 6), stallMaxBackoff)
+	log.Printf("[%s] No data read for %v, restarting the source in %v", m.Source.Name(), m.readTimeout, delay)
+	if m.stalls == 1 {
+		m.sendAlert("source_stalled", sentry.LevelWarning, fmt.Sprintf("%s: no data read for %v; the source may be hung and is being restarted", m.Source.Name(), m.readTimeout))
+	}
+	return delay
+}
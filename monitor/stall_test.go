@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// hangingSource streams a new pipe that blocks until written to or closed,
+// like a hung command, and counts how often it was started.
+type hangingSource struct {
+	mu      sync.Mutex
+	writer  *io.PipeWriter
+	streams int
+}
+
+func (s *hangingSource) Name() string { return "hanging" }
+func (s *hangingSource) Stream() (io.Reader, error) {
+	pr, pw := io.Pipe()
+	s.mu.Lock()
+	s.writer = pw
+	s.streams++
+	s.mu.Unlock()
+	return pr, nil
+}
+func (s *hangingSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writer.Close()
+}
+func (s *hangingSource) started() (int, *io.PipeWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams, s.writer
+}
+
+func TestReadTimeoutRestartsStalledSource(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &hangingSource{}
+	mon, err := New(ctx, source, &MockDetector{}, nil, Options{ReadTimeout: "100ms"})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		mon.Start()
+		close(done)
+	}()
+
+	// The first stream stalls and is restarted after a 1s backoff
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if n, _ := source.started(); n >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the stalled source to be restarted")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	_, w := source.started()
+	if _, err := w.Write([]byte("error after restart\n")); err != nil {
+		t.Fatalf("Failed to write to the restarted source: %v", err)
+	}
+	// The line ends the stall episode, so the next stall (which flushes the line) warns again
+	time.Sleep(300 * time.Millisecond)
+	sentry.Flush(time.Second)
+
+	if got := countAlerts(transport, "source_stalled", sentry.LevelWarning); got != 2 {
+		t.Errorf("Expected 2 source_stalled warnings, got %d", got)
+	}
+	var reported bool
+	for _, e := range snapshotEvents(transport) {
+		if e.Message == "error after restart" {
+			reported = true
+		}
+	}
+	if !reported {
+		t.Error("Expected the line read after the restart to be reported")
+	}
+
+	cancel()
+	source.Close()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Monitor did not stop after cancel")
+	}
+}
+
+func TestStallReaderPassesData(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := newStallReader(pr, 100*time.Millisecond)
+	go func() {
+		pw.Write([]byte("hello"))
+		pw.Close()
+	}()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("Expected %q, got %q", "hello", data)
+	}
+}
+
+// failingSource can't be started, like a closed source.
+type failingSource struct{}
+
+func (s *failingSource) Name() string               { return "failing" }
+func (s *failingSource) Stream() (io.Reader, error) { return nil, errors.New("source closed") }
+func (s *failingSource) Close() error               { return nil }
+
+func TestStartReturnsWhenCancelledWhileRetrying(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mon, err := New(ctx, &failingSource{}, &MockDetector{}, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		mon.Start()
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Expected Start to return once cancelled, while retrying a source that fails to start")
+	}
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestStartupDeadlineSilentSource(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := NewMockPipeSource()
+	mon, err := New(ctx, source, &MockDetector{}, nil, Options{
+		StartupDeadline:  "100ms",
+		StartupUnhealthy: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	go mon.Start()
+
+	time.Sleep(300 * time.Millisecond)
+	sentry.Flush(time.Second)
+	if got := countAlerts(transport, "no_startup_data", sentry.LevelWarning); got != 1 {
+		t.Fatalf("Expected 1 no_startup_data warning, got %d", got)
+	}
+	if mon.Health().Err == nil {
+		t.Error("Expected the silent source to be unhealthy")
+	}
+
+	// The first line makes it healthy again
+	source.Write([]byte("first line\n"))
+	time.Sleep(50 * time.Millisecond)
+	if err := mon.Health().Err; err != nil {
+		t.Errorf("Expected the source to be healthy after a line, got %v", err)
+	}
+	source.Close()
+}
+
+func TestStartupDeadlineWithData(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mon, err := New(ctx, &MockSource{content: "hello\n"}, &MockDetector{}, nil, Options{StartupDeadline: "100ms"})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	time.Sleep(200 * time.Millisecond)
+	sentry.Flush(time.Second)
+	if got := countAlerts(transport, "no_startup_data", sentry.LevelWarning); got != 0 {
+		t.Errorf("Expected no startup alert for a source with data, got %d", got)
+	}
+}
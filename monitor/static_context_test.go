@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorStaticContextAndTags(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, err := detectors.NewGenericDetector(`req=(?P<request_id>\w+) error`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	mon, err := New(context.Background(), &MockSource{content: "[100.0] req=a error\n"}, det, nil, Options{
+		Context: map[string]interface{}{
+			"runbook_url": "https://example.com/runbook",
+			"request_id":  "static",
+		},
+		Tags: map[string]string{
+			"team":   "payments",
+			"source": "overridden",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	event := transport.events[0]
+
+	info, ok := event.Contexts["Monitor Info"]
+	if !ok {
+		t.Fatal("Expected 'Monitor Info' context")
+	}
+	if info["runbook_url"] != "https://example.com/runbook" {
+		t.Errorf("Expected runbook_url in Monitor Info, got %v", info["runbook_url"])
+	}
+
+	// Extracted context must not be overwritten by static context
+	logData, ok := event.Contexts["Log Data"]
+	if !ok {
+		t.Fatal("Expected 'Log Data' context")
+	}
+	if logData["request_id"] != "a" {
+		t.Errorf("Expected extracted request_id 'a', got %v", logData["request_id"])
+	}
+
+	if event.Tags["team"] != "payments" {
+		t.Errorf("Expected tag team=payments, got %q", event.Tags["team"])
+	}
+	if event.Tags["source"] != "mock" {
+		t.Errorf("Expected built-in source tag to win, got %q", event.Tags["source"])
+	}
+}
@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorStripSyslogHeader(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	input := "Oct 11 22:14:15 web1 app[4242]: Error: connection refused\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{
+		StripSyslogHeader: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Message != "Error: connection refused" {
+		t.Errorf("Expected the bare message, got %q", event.Message)
+	}
+	if event.Tags["syslog_host"] != "web1" || event.Tags["syslog_app"] != "app" || event.Tags["syslog_pid"] != "4242" {
+		t.Errorf("Expected syslog_host=web1, syslog_app=app and syslog_pid=4242, got %v", event.Tags)
+	}
+	// Still under the names used before RFC 5424 support
+	if event.Tags["syslog_tag"] != "app" || event.Contexts["Syslog Header"]["tag"] != "app" {
+		t.Errorf("Expected syslog_tag=app and tag=app in the Syslog Header context, got %v and %v", event.Tags, event.Contexts["Syslog Header"])
+	}
+	if pid := event.Contexts["Syslog Header"]["pid"]; pid != "4242" {
+		t.Errorf("Expected pid 4242 in the Syslog Header context, got %v", pid)
+	}
+}
+
+func TestMonitorSyslogHeaderTags(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Without strip_syslog_header the line is reported as is, but still tagged
+	input := "<11>1 2026-10-11T22:14:15.003Z web2 billing 812 PAY01 [meta sequenceId=\"7\"] Error: card declined\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Message != strings.TrimSuffix(input, "\n") {
+		t.Errorf("Expected the line unchanged, got %q", event.Message)
+	}
+	for tag, want := range map[string]string{"syslog_host": "web2", "syslog_app": "billing", "syslog_pid": "812", "syslog_severity": "3"} {
+		if got := event.Tags[tag]; got != want {
+			t.Errorf("Expected tag %s=%q, got %q", tag, want, got)
+		}
+	}
+	if msgid := event.Contexts["Syslog Header"]["msgid"]; msgid != "PAY01" {
+		t.Errorf("Expected msgid PAY01 in the Syslog Header context, got %v", msgid)
+	}
+}
@@ -1,7 +1,11 @@
 package monitor
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
 )
 
 func TestExtractTimestamp_SyslogWithPRI(t *testing.T) {
@@ -65,3 +69,70 @@ func TestExtractSyslogPriority(t *testing.T) {
 		}
 	}
 }
+
+func TestSyslogNames(t *testing.T) {
+	tests := []struct {
+		code    int
+		wantFac string
+		wantSev string
+	}{
+		{0, "kern", "emerg"},
+		{2, "mail", "crit"},
+		{4, "auth", "warning"},
+		{7, "news", "debug"},
+		{8, "uucp", ""},
+		{16, "local0", ""},
+		{23, "local7", ""},
+		{24, "", ""},
+		{-1, "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := syslogFacilityName(tt.code); got != tt.wantFac {
+			t.Errorf("syslogFacilityName(%d) = %q, want %q", tt.code, got, tt.wantFac)
+		}
+		if got := syslogSeverityName(tt.code); got != tt.wantSev {
+			t.Errorf("syslogSeverityName(%d) = %q, want %q", tt.code, got, tt.wantSev)
+		}
+	}
+}
+
+func TestSyslogNameTags(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	input := "<34>Oct 11 22:14:15 mymachine su: 'su root' failed\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+
+	want := map[string]string{
+		"syslog_priority":      "34",
+		"syslog_facility":      "4",
+		"syslog_severity":      "2",
+		"syslog_facility_name": "auth",
+		"syslog_severity_name": "crit",
+	}
+	for k, v := range want {
+		if got := transport.events[0].Tags[k]; got != v {
+			t.Errorf("Tag %s = %q, want %q", k, got, v)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// DefaultMaxTagValueLength is Sentry's own limit on tag values.
+const DefaultMaxTagValueLength = 200
+
+// tagPolicy decides which extracted fields and line labels become Sentry
+// tags, keeping high-cardinality keys such as request IDs out of tags, where
+// they degrade Sentry's search and storage. Fields that don't become tags are
+// still in the "Log Data" context.
+type tagPolicy struct {
+	allow    map[string]bool // extracted fields promoted to tags
+	allowAll bool            // "*": all extracted fields with scalar values
+	deny     map[string]bool // never tags, neither fields nor labels
+	maxLen   int             // longer values are not tags
+}
+
+func newTagPolicy(allowlist, denylist []string, maxLen int) tagPolicy {
+	p := tagPolicy{maxLen: maxLen}
+	if p.maxLen <= 0 {
+		p.maxLen = DefaultMaxTagValueLength
+	}
+	for _, k := range allowlist {
+		if k == "*" {
+			p.allowAll = true
+			continue
+		}
+		if p.allow == nil {
+			p.allow = make(map[string]bool)
+		}
+		p.allow[k] = true
+	}
+	for _, k := range denylist {
+		if p.deny == nil {
+			p.deny = make(map[string]bool)
+		}
+		p.deny[k] = true
+	}
+	return p
+}
+
+// fieldTag returns the tag value of an extracted field, and whether it may be
+// a tag.
+func (p tagPolicy) fieldTag(key string, value interface{}) (string, bool) {
+	if !p.allowAll && !p.allow[key] {
+		return "", false
+	}
+	switch value.(type) {
+	case string, float64, bool, int, int64:
+	default:
+		// Objects, arrays and nulls stay in the context only
+		return "", false
+	}
+	s := fmt.Sprint(value)
+	return s, s != "" && p.labelOK(key, s)
+}
+
+// labelOK reports whether a line label may be a tag.
+func (p tagPolicy) labelOK(key, value string) bool {
+	return !p.deny[key] && len(value) <= p.maxLen
+}
+
+// setExtractedTags sets the allowed fields of the extracted context and the
+// allowed labels as tags. Labels that are not allowed go to a "Labels"
+// context instead.
+func (m *Monitor) setExtractedTags(scope *sentry.Scope, meta BatchMetadata) {
+	if m.tagPolicy.allowAll || len(m.tagPolicy.allow) > 0 {
+		keys := make([]string, 0, len(meta.Context))
+		for k := range meta.Context {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if v, ok := m.tagPolicy.fieldTag(k, meta.Context[k]); ok {
+				scope.SetTag(k, v)
+			}
+		}
+	}
+
+	var untagged map[string]interface{}
+	for k, v := range meta.Labels {
+		if m.tagPolicy.labelOK(k, v) {
+			scope.SetTag(k, v)
+			continue
+		}
+		if untagged == nil {
+			untagged = make(map[string]interface{})
+		}
+		untagged[k] = v
+	}
+	if untagged != nil {
+		scope.SetContext("Labels", untagged)
+	}
+}
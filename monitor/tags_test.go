@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestTagAllowlistAndDenylist(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	long := strings.Repeat("x", 50)
+	input := `{"level":"error","region":"eu-west-1","status":503,"request_id":"7f3c9a0e","trace":"` + long + `","details":{"retry":true}}` + "\n"
+	det, err := detectors.NewJsonDetector("level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		TagAllowlist:      []string{"*"},
+		TagDenylist:       []string{"request_id"},
+		MaxTagValueLength: 40,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Tags["region"] != "eu-west-1" || event.Tags["status"] != "503" {
+		t.Errorf("Expected allowlisted fields as tags, got %v", event.Tags)
+	}
+	for _, key := range []string{"request_id", "trace", "details"} {
+		if _, ok := event.Tags[key]; ok {
+			t.Errorf("Expected %s not to be a tag, got %q", key, event.Tags[key])
+		}
+	}
+	// Fields that are not tags are still in the context
+	logData := event.Contexts["Log Data"]
+	if logData["request_id"] != "7f3c9a0e" || logData["trace"] != long {
+		t.Errorf("Expected request_id and trace in the Log Data context, got %v", logData)
+	}
+}
+
+func TestTagAllowlistDefault(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	input := `{"level":"error","region":"eu-west-1","request_id":"7f3c9a0e"}` + "\n"
+	det, err := detectors.NewJsonDetector("level:error")
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{TagAllowlist: []string{"region"}})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if events[0].Tags["region"] != "eu-west-1" {
+		t.Errorf("Expected the allowlisted region tag, got %v", events[0].Tags)
+	}
+	if _, ok := events[0].Tags["request_id"]; ok {
+		t.Error("Expected fields not in the allowlist not to be tags")
+	}
+}
+
+func TestTagDenylistLabels(t *testing.T) {
+	mon, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{TagDenylist: []string{"k8s_pod"}})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	scope := sentry.NewScope()
+	mon.setExtractedTags(scope, BatchMetadata{Labels: map[string]string{"k8s_namespace": "shop", "k8s_pod": "web-7d9f-x2x"}})
+
+	event := scope.ApplyToEvent(sentry.NewEvent(), nil, nil)
+	if event.Tags["k8s_namespace"] != "shop" {
+		t.Errorf("Expected the k8s_namespace label as a tag, got %v", event.Tags)
+	}
+	if _, ok := event.Tags["k8s_pod"]; ok {
+		t.Error("Expected the denylisted k8s_pod label not to be a tag")
+	}
+	if event.Contexts["Labels"]["k8s_pod"] != "web-7d9f-x2x" {
+		t.Errorf("Expected the k8s_pod label in the Labels context, got %v", event.Contexts["Labels"])
+	}
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// How often the throughput averages are updated
+	ThroughputTick = 1 * time.Second
+	// Time constant of the throughput averages, like the 1 minute load average
+	ThroughputWindow = 1 * time.Minute
+)
+
+// ewmaRate is an exponentially weighted moving average of a per-second rate.
+type ewmaRate struct {
+	window      time.Duration
+	rate        float64
+	initialized bool
+}
+
+// update folds count events observed over elapsed into the average.
+func (e *ewmaRate) update(count int64, elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+	sample := float64(count) / elapsed.Seconds()
+	if !e.initialized {
+		e.rate = sample
+		e.initialized = true
+		return
+	}
+	alpha := 1 - math.Exp(-elapsed.Seconds()/e.window.Seconds())
+	e.rate += alpha * (sample - e.rate)
+}
+
+// ThroughputStats is a snapshot of a monitor's average throughput.
+type ThroughputStats struct {
+	Source        string
+	LinesPerSec   float64
+	MatchesPerSec float64
+}
+
+// throughput counts lines and matches in the scan loop and turns them
+// into moving averages on a ticker, so the per-line cost is one atomic add.
+type throughput struct {
+	lines   int64 // atomic, since the last tick
+	matches int64 // atomic, since the last tick
+
+	mu          sync.Mutex
+	lineRate    ewmaRate
+	matchRate   ewmaRate
+	lastUpdated time.Time
+}
+
+func newThroughput(window time.Duration) *throughput {
+	return &throughput{
+		lineRate:    ewmaRate{window: window},
+		matchRate:   ewmaRate{window: window},
+		lastUpdated: time.Now(),
+	}
+}
+
+// tick folds the counts since the previous tick into the averages.
+func (t *throughput) tick(now time.Time) {
+	lines := atomic.SwapInt64(&t.lines, 0)
+	matches := atomic.SwapInt64(&t.matches, 0)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	elapsed := now.Sub(t.lastUpdated)
+	t.lastUpdated = now
+	t.lineRate.update(lines, elapsed)
+	t.matchRate.update(matches, elapsed)
+}
+
+func (t *throughput) rates() (linesPerSec, matchesPerSec float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lineRate.rate, t.matchRate.rate
+}
+
+// throughputLoop updates the throughput averages and gauges every ThroughputTick until done is closed.
+func (m *Monitor) throughputLoop(done <-chan struct{}) {
+	ticker := time.NewTicker(ThroughputTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-done:
+			return
+		case now := <-ticker.C:
+			m.throughput.tick(now)
+			lines, matches := m.throughput.rates()
+			m.metricThroughput.Set(lines)
+			m.metricMatchRate.Set(matches)
+		}
+	}
+}
+
+// Stats returns the monitor's moving average throughput.
+func (m *Monitor) Stats() ThroughputStats {
+	lines, matches := m.throughput.rates()
+	return ThroughputStats{
+		Source:        m.Source.Name(),
+		LinesPerSec:   lines,
+		MatchesPerSec: matches,
+	}
+}
@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThroughputEWMA(t *testing.T) {
+	tp := newThroughput(5 * time.Second)
+	now := tp.lastUpdated
+
+	// 100 lines/sec, 10% of them matching, for 30 one-second ticks
+	for i := 0; i < 30; i++ {
+		atomic.AddInt64(&tp.lines, 100)
+		atomic.AddInt64(&tp.matches, 10)
+		now = now.Add(time.Second)
+		tp.tick(now)
+	}
+
+	lines, matches := tp.rates()
+	if math.Abs(lines-100) > 5 {
+		t.Errorf("Expected ~100 lines/sec, got %.2f", lines)
+	}
+	if math.Abs(matches-10) > 1 {
+		t.Errorf("Expected ~10 matches/sec, got %.2f", matches)
+	}
+
+	// After the source goes quiet for one window, the average decays to about 1/e
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second)
+		tp.tick(now)
+	}
+	lines, _ = tp.rates()
+	if lines < 30 || lines > 45 {
+		t.Errorf("Expected lines/sec to decay to ~37 after one window, got %.2f", lines)
+	}
+}
+
+func TestEWMAIgnoresZeroElapsed(t *testing.T) {
+	e := ewmaRate{window: time.Minute}
+	e.update(10, 0)
+	if e.initialized || e.rate != 0 {
+		t.Errorf("Expected update with zero elapsed to be ignored, got rate %.2f", e.rate)
+	}
+}
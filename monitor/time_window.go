@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeWindow restricts matching to lines whose timestamp falls in [after, before].
+// A zero bound is open.
+type timeWindow struct {
+	after          float64
+	before         float64
+	includeUntimed bool
+}
+
+// newTimeWindow parses RFC3339 bounds. It returns nil if neither bound is set.
+func newTimeWindow(onlyAfter, onlyBefore, untimedLines string) (*timeWindow, error) {
+	if onlyAfter == "" && onlyBefore == "" {
+		return nil, nil
+	}
+	w := &timeWindow{}
+	if onlyAfter != "" {
+		t, err := time.Parse(time.RFC3339, onlyAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid only_after: %w", err)
+		}
+		w.after = float64(t.UnixNano()) / 1e9
+	}
+	if onlyBefore != "" {
+		t, err := time.Parse(time.RFC3339, onlyBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid only_before: %w", err)
+		}
+		w.before = float64(t.UnixNano()) / 1e9
+	}
+	switch untimedLines {
+	case "", "include":
+		w.includeUntimed = true
+	case "exclude":
+		w.includeUntimed = false
+	default:
+		return nil, fmt.Errorf("unknown untimed_lines '%s' (valid values: include, exclude)", untimedLines)
+	}
+	return w, nil
+}
+
+// contains reports whether a line with timestamp ts (ok=false if none was found) is in the window.
+func (w *timeWindow) contains(ts float64, ok bool) bool {
+	if !ok {
+		return w.includeUntimed
+	}
+	if w.after != 0 && ts < w.after {
+		return false
+	}
+	if w.before != 0 && ts > w.before {
+		return false
+	}
+	return true
+}
+
+// inTimeWindow reports whether the line should be considered for detection.
+func (m *Monitor) inTimeWindow(line []byte) bool {
+	if m.timeWindow == nil {
+		return true
+	}
+	ts, tsStr := m.lineTimestamp(line)
+	return m.timeWindow.contains(ts, tsStr != "")
+}
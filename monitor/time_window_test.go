@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorTimeWindow(t *testing.T) {
+	input := `2026-03-01T09:59:00Z error before the incident
+2026-03-01T10:05:00Z error during the incident
+untimed error line
+2026-03-01T10:30:00Z error also during the incident
+2026-03-01T11:01:00Z error after the incident
+`
+	testCases := []struct {
+		name         string
+		untimedLines string
+		expected     []string
+	}{
+		{
+			name:     "Untimed Included By Default",
+			expected: []string{"during the incident", "untimed error line", "also during the incident"},
+		},
+		{
+			name:         "Untimed Excluded",
+			untimedLines: "exclude",
+			expected:     []string{"during the incident", "also during the incident"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			err := sentry.Init(sentry.ClientOptions{
+				Transport: transport,
+			})
+			if err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+
+			mon, err := New(context.Background(), &MockSource{content: input}, &MockDetector{}, nil, Options{
+				OnlyAfter:    "2026-03-01T10:00:00Z",
+				OnlyBefore:   "2026-03-01T11:00:00Z",
+				UntimedLines: tc.untimedLines,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+
+			sentry.Flush(time.Second)
+
+			transport.mu.Lock()
+			defer transport.mu.Unlock()
+
+			var messages []string
+			for _, event := range transport.events {
+				messages = append(messages, event.Message)
+			}
+			all := strings.Join(messages, "\n")
+
+			for _, want := range tc.expected {
+				if !strings.Contains(all, want) {
+					t.Errorf("Expected %q to be reported, got %q", want, all)
+				}
+			}
+			for _, unwanted := range []string{"before the incident", "after the incident"} {
+				if strings.Contains(all, unwanted) {
+					t.Errorf("Expected %q to be skipped, got %q", unwanted, all)
+				}
+			}
+			if tc.untimedLines == "exclude" && strings.Contains(all, "untimed") {
+				t.Errorf("Expected untimed line to be skipped, got %q", all)
+			}
+		})
+	}
+}
+
+func TestNewTimeWindowInvalid(t *testing.T) {
+	if _, err := newTimeWindow("yesterday", "", ""); err == nil {
+		t.Error("Expected error for non-RFC3339 only_after")
+	}
+	if _, err := newTimeWindow("2026-03-01T10:00:00Z", "", "maybe"); err == nil {
+		t.Error("Expected error for unknown untimed_lines")
+	}
+	if w, err := newTimeWindow("", "", ""); w != nil || err != nil {
+		t.Errorf("Expected no window without bounds, got %v, %v", w, err)
+	}
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/angch/sentrylogmon/detectors"
+)
+
+// titleIDPattern matches numbers and hex IDs, which fingerprint titles mask
+// so that e.g. PIDs and addresses don't make every title unique. Digits
+// inside names such as "sda1" or "EXT4" are kept.
+var titleIDPattern = regexp.MustCompile(`(?i)\b(?:0x)?[0-9a-f]*[0-9][0-9a-f]*\b|\b[0-9]+`)
+
+// validateTitleFrom checks Options.TitleFrom.
+func validateTitleFrom(titleFrom string) error {
+	switch titleFrom {
+	case "", "first_line", "fingerprint", "pattern":
+		return nil
+	default:
+		return fmt.Errorf("unknown title_from '%s' (valid values: first_line, fingerprint, pattern)", titleFrom)
+	}
+}
+
+// eventTitle returns the title for an event built from lines (a batch), set
+// as its title tag, or "" for none.
+//
+//   - first_line: the first line without its timestamp
+//   - fingerprint: the same with numbers and hex IDs masked
+//   - pattern: the part of the first line the detector matched
+//
+// With fingerprint_normalizers, fingerprint titles are normalized by those
+// instead.
+func (m *Monitor) eventTitle(lines string, meta BatchMetadata) string {
+	if m.titleFrom == "" {
+		return ""
+	}
+	if m.titleFrom == "pattern" {
+		first, _, _ := strings.Cut(lines, m.batchSeparator)
+		if extractor, ok := m.Detector.(detectors.MatchExtractor); ok {
+			if match := strings.TrimSpace(string(extractor.Match([]byte(first)))); match != "" {
+				return match
+			}
+		}
+	}
+
+	title := m.firstLine(lines, meta)
+	if m.titleFrom == "fingerprint" {
+		if m.normalizer != nil {
+			return m.normalizer.Normalize(title)
+		}
+		title = titleIDPattern.ReplaceAllString(title, "<n>")
+	}
+	return title
+}
+
+// maxTagValue is the longest tag value Sentry accepts.
+const maxTagValue = 200
+
+// titleTag returns title cut to fit a tag value, on a rune boundary.
+func titleTag(title string) string {
+	if len(title) <= maxTagValue {
+		return title
+	}
+	n := maxTagValue
+	for n > 0 && !utf8.RuneStart(title[n]) {
+		n--
+	}
+	return title[:n]
+}
+
+// eventFingerprint returns the Sentry fingerprint for an event built from
+// lines, or "" to leave grouping to Sentry: the fingerprint_from context
+// value if the event has one, otherwise the first line without its
+// timestamp, normalized by fingerprint_normalizers.
+func (m *Monitor) eventFingerprint(lines string, meta BatchMetadata) string {
+	if m.fingerprintFrom != "" {
+		if val, ok := meta.Context[m.fingerprintFrom]; ok && val != nil {
+			// JSON numbers are float64; %v prints whole ones without a decimal point
+			if fp := fmt.Sprint(val); fp != "" {
+				return fp
+			}
+		}
+	}
+	if m.normalizer == nil {
+		return ""
+	}
+	return m.normalizer.Normalize(m.firstLine(lines, meta))
+}
+
+// firstLine returns the first line of a batch without its timestamp.
+func (m *Monitor) firstLine(lines string, meta BatchMetadata) string {
+	first, _, _ := strings.Cut(lines, m.batchSeparator)
+	if ts := meta.TimestampStr; ts != "" {
+		// e.g. "[123.456] " or "2026-03-01T10:00:00Z "
+		if i := strings.Index(first, ts); i >= 0 && i <= 1 {
+			first = strings.TrimLeft(first[i+len(ts):], "]) :-")
+		}
+	}
+	return strings.TrimSpace(first)
+}
@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorTitleFrom(t *testing.T) {
+	input := `[100.0] EXT4-fs error (device sda1): inode 48213 has corrupt extent
+[100.5] EXT4-fs error (device sda1): inode 48214 has corrupt extent
+[101.0] EXT4-fs error (device sda1): remounting filesystem read-only
+`
+	testCases := []struct {
+		name        string
+		titleFrom   string
+		title       string
+		fingerprint []string
+	}{
+		{
+			name:      "First Line",
+			titleFrom: "first_line",
+			title:     "EXT4-fs error (device sda1): inode 48213 has corrupt extent",
+		},
+		{
+			name:        "Fingerprint",
+			titleFrom:   "fingerprint",
+			title:       "EXT4-fs error (device sda1): inode <n> has corrupt extent",
+			fingerprint: []string{"EXT4-fs error (device sda1): inode <n> has corrupt extent"},
+		},
+		{
+			name:      "Pattern",
+			titleFrom: "pattern",
+			title:     "EXT4-fs error (device sda1)",
+		},
+		{
+			name: "Default",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+			det, err := detectors.NewGenericDetector(`EXT4-fs error \(device \w+\)`)
+			if err != nil {
+				t.Fatalf("Failed to create detector: %v", err)
+			}
+			mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+				TitleFrom: tc.titleFrom,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+			sentry.Flush(time.Second)
+
+			events := snapshotEvents(transport)
+			if len(events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(events))
+			}
+			event := events[0]
+			if got := event.Tags["title"]; got != tc.title {
+				t.Errorf("Expected title tag %q, got %q", tc.title, got)
+			}
+			if len(event.Exception) > 0 {
+				t.Errorf("Expected no exception, got %+v", event.Exception)
+			}
+			// The message is the batch as read
+			if event.Message != strings.TrimSuffix(input, "\n") {
+				t.Errorf("Expected the batch as the message, got %q", event.Message)
+			}
+			if strings.Join(event.Fingerprint, ",") != strings.Join(tc.fingerprint, ",") {
+				t.Errorf("Expected fingerprint %q, got %q", tc.fingerprint, event.Fingerprint)
+			}
+		})
+	}
+}
+
+func TestMonitorInvalidTitleFrom(t *testing.T) {
+	_, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{TitleFrom: "last_line"})
+	if err == nil || !strings.Contains(err.Error(), "unknown title_from") {
+		t.Errorf("Expected an unknown title_from error, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorTraceContext(t *testing.T) {
+	transport := &MockTransport{}
+	err := sentry.Init(sentry.ClientOptions{
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	det, err := detectors.NewGenericDetector(`error trace_id=(?P<trace_id>[0-9A-Fa-f-]+) span_id=(?P<span_id>[0-9a-f]+)`)
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	input := "[100.0] error trace_id=4BF92F3577B34DA6A3CE929D0E0E4736 span_id=00f067aa0ba902b7\n"
+	mon, err := New(context.Background(), &MockSource{content: input}, det, nil, Options{
+		TraceIDFrom: "trace_id",
+		SpanIDFrom:  "span_id",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+
+	sentry.Flush(time.Second)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	trace, ok := transport.events[0].Contexts["trace"]
+	if !ok {
+		t.Fatal("Expected trace context")
+	}
+	if trace["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected trace_id from the line, got %v", trace["trace_id"])
+	}
+	if trace["span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("Expected span_id from the line, got %v", trace["span_id"])
+	}
+}
+
+func TestNormalizeHexID(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		length   int
+		expected string
+	}{
+		{"4bf92f35-77b3-4da6-a3ce-929d0e0e4736", 32, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"00F067AA0BA902B7", 16, "00f067aa0ba902b7"},
+		{"not-a-trace", 32, ""},
+		{"zzf92f3577b34da6a3ce929d0e0e4736", 32, ""},
+		{12345, 32, ""},
+		{nil, 32, ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeHexID(tt.input, tt.length); got != tt.expected {
+			t.Errorf("normalizeHexID(%v, %d) = %q, want %q", tt.input, tt.length, got, tt.expected)
+		}
+	}
+}
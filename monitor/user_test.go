@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestMonitorUserFrom(t *testing.T) {
+	tests := []struct {
+		name     string
+		detector func() (detectors.Detector, error)
+		input    string
+		userFrom UserFrom
+		want     sentry.User
+	}{
+		{
+			name: "regex groups",
+			detector: func() (detectors.Detector, error) {
+				return detectors.NewGenericDetector(`error .*user_id=(?P<user_id>\d+) tenant=(?P<tenant>\w+)`)
+			},
+			input:    "[100.0] error payment declined user_id=123 tenant=acme\n",
+			userFrom: UserFrom{ID: "user_id", Username: "tenant", Email: "email"},
+			want:     sentry.User{ID: "123", Username: "acme"},
+		},
+		{
+			name: "json number",
+			detector: func() (detectors.Detector, error) {
+				return detectors.NewJsonDetector("level:error")
+			},
+			input:    `{"level":"error","msg":"payment declined","uid":123,"email":"ann@example.com"}` + "\n",
+			userFrom: UserFrom{ID: "uid", Email: "email"},
+			want:     sentry.User{ID: "123", Email: "ann@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &MockTransport{}
+			if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+				t.Fatalf("Failed to init sentry: %v", err)
+			}
+			det, err := tt.detector()
+			if err != nil {
+				t.Fatalf("Failed to create detector: %v", err)
+			}
+			mon, err := New(context.Background(), &MockSource{content: tt.input}, det, nil, Options{UserFrom: tt.userFrom})
+			if err != nil {
+				t.Fatalf("Failed to create monitor: %v", err)
+			}
+			mon.StopOnEOF = true
+			mon.Start()
+			sentry.Flush(time.Second)
+
+			events := snapshotEvents(transport)
+			if len(events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(events))
+			}
+			if got := events[0].User; got.ID != tt.want.ID || got.Email != tt.want.Email || got.Username != tt.want.Username {
+				t.Errorf("Expected user %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMonitorUserFromMissing(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+	mon, err := New(context.Background(), &MockSource{content: "[100.0] error without a user\n"}, &MockDetector{}, nil, Options{
+		UserFrom: UserFrom{ID: "user_id"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	events := snapshotEvents(transport)
+	if len(events) != 1 || !events[0].User.IsEmpty() {
+		t.Errorf("Expected 1 event without a user, got %+v", events)
+	}
+}
@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func TestWarmupSuppressesStartupEvents(t *testing.T) {
+	transport := &MockTransport{}
+	if err := sentry.Init(sentry.ClientOptions{Transport: transport}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	mon, err := New(context.Background(), &MockSource{}, &MockDetector{}, nil, Options{
+		Warmup:   "200ms",
+		Cooldown: "1h",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	mon.sendToSentry("error: eth0 link not ready", BatchMetadata{})
+	mon.sendToSentry("error: failed to mount /data", BatchMetadata{})
+	sentry.Flush(time.Second)
+	if events := snapshotEvents(transport); len(events) != 0 {
+		t.Fatalf("Expected events during warmup to be suppressed, got %d", len(events))
+	}
+
+	// Warmup events did not start the cooldown, so the first later event fires;
+	// a batch that matched during warmup stays suppressed when it is flushed later
+	matchedEarly := BatchMetadata{Matched: time.Now()}
+	time.Sleep(300 * time.Millisecond)
+	mon.sendToSentry("error: eth0 link not ready", matchedEarly)
+	mon.sendToSentry("error: disk failure", BatchMetadata{})
+	sentry.Flush(time.Second)
+	events := snapshotEvents(transport)
+	if len(events) != 1 || events[0].Message != "error: disk failure" {
+		t.Fatalf("Expected the event after warmup to be sent, got %d events", len(events))
+	}
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/exporters"
+	"github.com/angch/sentrylogmon/monitor"
+	"github.com/angch/sentrylogmon/sources"
+	"github.com/angch/sentrylogmon/sysstat"
+)
+
+// monitorBuilder creates the monitors for a monitor config, wired to what
+// all monitors share.
+type monitorBuilder struct {
+	cfg               *config.Config
+	collector         *sysstat.Collector
+	globalRateLimiter *monitor.RateLimiter
+	onMatch           func(line []byte) // e.g. the --sample-matches collector
+	httpTransport     http.RoundTripper // e.g. the delivery counter
+}
+
+// build returns the monitors for monCfg: one per matched file for a file glob,
+// otherwise one. Problems are logged and yield no monitors.
+func (b *monitorBuilder) build(ctx context.Context, monCfg config.MonitorConfig) []*monitor.Monitor {
+	var monitors []*monitor.Monitor
+	add := func(src sources.LogSource) {
+		if m := b.newMonitor(ctx, src, monCfg); m != nil {
+			monitors = append(monitors, m)
+		}
+	}
+
+	switch monCfg.Type {
+	case "file":
+		if monCfg.Path == "" {
+			log.Printf("Skipping file monitor '%s': path is empty", monCfg.Name)
+			return nil
+		}
+
+		fileOpts := sources.FileOptions{FromStart: monCfg.FromStart, Follow: monCfg.Follow, ReadBufferSize: monCfg.ReadBufferSize}
+		if strings.ContainsAny(monCfg.Path, "*?[]") {
+			files, err := globFileSources(monCfg.Name, monCfg.Path, monCfg.IgnoreGlobs)
+			if err != nil {
+				log.Printf("Error matching glob pattern %s: %v", monCfg.Path, err)
+				return nil
+			}
+			if len(files) == 0 {
+				log.Printf("No files matched glob pattern %s", monCfg.Path)
+				return nil
+			}
+			for _, f := range files {
+				add(newFileSource(f.name, f.path, monCfg, fileOpts))
+			}
+		} else {
+			add(newFileSource(monCfg.Name, monCfg.Path, monCfg, fileOpts))
+		}
+	case "journalctl":
+		add(sources.NewJournalctlSource(monCfg.Name, monCfg.Args))
+	case "dmesg":
+		add(sources.NewDmesgSource(monCfg.Name))
+	case "command":
+		parts := strings.Fields(monCfg.Args)
+		if len(parts) == 0 {
+			log.Printf("Skipping command monitor '%s': command is empty", monCfg.Name)
+			return nil
+		}
+		if monCfg.PollInterval != "" {
+			interval, err := time.ParseDuration(monCfg.PollInterval)
+			if err != nil {
+				log.Printf("Skipping command monitor '%s': invalid poll_interval: %v", monCfg.Name, err)
+				return nil
+			}
+			add(sources.NewPollingCommandSource(monCfg.Name, sources.PollOptions{Interval: interval, NewLinesOnly: monCfg.PollNewLines}, parts[0], parts[1:]...))
+		} else {
+			add(sources.NewCommandSource(monCfg.Name, parts[0], parts[1:]...))
+		}
+	case "syslog":
+		// path, if set, is listened on along with addresses
+		addresses := monCfg.Addresses
+		if monCfg.Path != "" || len(addresses) == 0 {
+			addresses = append([]string{monCfg.Path}, addresses...)
+		}
+		add(sources.NewMultiSyslogSource(monCfg.Name, addresses, sources.SyslogOptions{ReadBufferSize: monCfg.ReadBufferSize}))
+	case "ssh":
+		src, err := sources.NewSSHSource(monCfg.Name, monCfg.Path, sources.SSHOptions{
+			KeyFile:        monCfg.SSH.KeyFile,
+			KnownHostsFile: monCfg.SSH.KnownHostsFile,
+			Port:           monCfg.SSH.Port,
+		})
+		if err != nil {
+			log.Printf("Skipping ssh monitor '%s': %v", monCfg.Name, err)
+			return nil
+		}
+		add(src)
+	case "http":
+		add(sources.NewHTTPStreamSource(monCfg.Name, monCfg.Path))
+	case "k8s":
+		var resync time.Duration
+		if monCfg.K8s.Resync != "" {
+			resync, _ = time.ParseDuration(monCfg.K8s.Resync)
+		}
+		src, err := sources.NewK8sSource(monCfg.Name, sources.K8sOptions{
+			Namespace:     monCfg.K8s.Namespace,
+			LabelSelector: monCfg.K8s.LabelSelector,
+			Container:     monCfg.K8s.Container,
+			APIServer:     monCfg.K8s.APIServer,
+			TokenFile:     monCfg.K8s.TokenFile,
+			CAFile:        monCfg.K8s.CAFile,
+			Resync:        resync,
+		})
+		if err != nil {
+			log.Printf("Skipping k8s monitor '%s': %v", monCfg.Name, err)
+			return nil
+		}
+		add(src)
+	default:
+		log.Printf("Unknown monitor type: %s", monCfg.Type)
+	}
+	return monitors
+}
+
+// inheritTarget turns a mirror or route Sentry config into a target, taking
+// unset fields from the global config.
+func inheritTarget(t, global config.SentryConfig) monitor.SentryTarget {
+	target := monitor.SentryTarget{DSN: t.DSN, Environment: t.Environment, Release: t.Release, Dist: t.Dist, SampleRate: t.SampleRate}
+	if target.Environment == "" {
+		target.Environment = global.Environment
+	}
+	if target.Release == "" {
+		target.Release = global.Release
+	}
+	if target.Dist == "" {
+		target.Dist = global.Dist
+	}
+	if target.SampleRate == 0 {
+		target.SampleRate = global.SampleRate
+	}
+	return target
+}
+
+func (b *monitorBuilder) newMonitor(ctx context.Context, src sources.LogSource, monCfg config.MonitorConfig) *monitor.Monitor {
+	cfg := b.cfg
+	det, err := newDetector(monCfg)
+	if err != nil {
+		log.Printf("Failed to create detector for monitor '%s': %v", monCfg.Name, err)
+		return nil
+	}
+
+	var excludePatterns []string
+	if monCfg.ExcludeFile != "" {
+		patterns, err := detectors.ReadPatternFile(monCfg.ExcludeFile)
+		if err != nil {
+			log.Printf("Failed to load exclude file for monitor '%s': %v", monCfg.Name, err)
+			return nil
+		}
+		excludePatterns = patterns
+	}
+
+	var userFrom monitor.UserFrom
+	if u := monCfg.UserFrom; u != nil {
+		userFrom = monitor.UserFrom{ID: u.ID, Email: u.Email, Username: u.Username}
+	}
+
+	var exps []exporters.Exporter
+	if f := monCfg.ForwardSyslog; f != nil {
+		exp, err := exporters.NewSyslogExporter(f.Address, exporters.SyslogOptions{Facility: f.Facility, Format: f.Format, Tag: f.Tag})
+		if err != nil {
+			log.Printf("Failed to set up forward_syslog for monitor '%s': %v", monCfg.Name, err)
+			return nil
+		}
+		exps = append(exps, exp)
+	}
+	if f := monCfg.ForwardWebhook; f != nil {
+		exp, err := exporters.NewWebhookExporter(f.URL, exporters.WebhookOptions{Headers: f.Headers, Compress: f.Compress})
+		if err != nil {
+			log.Printf("Failed to set up forward_webhook for monitor '%s': %v", monCfg.Name, err)
+			return nil
+		}
+		exps = append(exps, exp)
+	}
+	if monCfg.OutputFile != "" {
+		exp, err := exporters.NewFileExporter(monCfg.OutputFile, exporters.RotateOptions{
+			MaxBytes: int64(monCfg.OutputMaxSize),
+			Backups:  cmp.Or(monCfg.OutputMaxFiles, 5),
+			Daily:    monCfg.OutputRotateDaily,
+		})
+		if err != nil {
+			log.Printf("Failed to open output_file for monitor '%s': %v", monCfg.Name, err)
+			for _, e := range exps {
+				e.Close()
+			}
+			return nil
+		}
+		exps = append(exps, exp)
+	}
+
+	// Prepare Sentry Options
+	sentryDSN := monCfg.Sentry.DSN
+	sentryEnv := monCfg.Sentry.Environment
+	sentryRelease := monCfg.Sentry.Release
+	sentryDist := monCfg.Sentry.Dist
+	sentrySampleRate := monCfg.Sentry.SampleRate
+
+	// Inherit global config if DSN is overridden but other fields are missing
+	if sentryDSN != "" {
+		if sentryEnv == "" {
+			sentryEnv = cfg.Sentry.Environment
+		}
+		if sentryRelease == "" {
+			sentryRelease = cfg.Sentry.Release
+		}
+		if sentryDist == "" {
+			sentryDist = cfg.Sentry.Dist
+		}
+		if sentrySampleRate == 0 {
+			sentrySampleRate = cfg.Sentry.SampleRate
+		}
+	}
+
+	// Mirror and route targets inherit the global settings when unset
+	var sentryTargets []monitor.SentryTarget
+	for _, t := range monCfg.SentryTargets {
+		sentryTargets = append(sentryTargets, inheritTarget(t, cfg.Sentry))
+	}
+	var routes []monitor.Route
+	for _, r := range monCfg.Routes {
+		routes = append(routes, monitor.Route{MinLevel: r.MinLevel, MaxLevel: r.MaxLevel, Target: inheritTarget(r.SentryConfig, cfg.Sentry)})
+	}
+
+	m, err := monitor.New(ctx, src, det, b.collector, monitor.Options{
+		Verbose:           cfg.Verbose,
+		ExcludePattern:    monCfg.ExcludePattern,
+		ExcludePatterns:   excludePatterns,
+		MaxInactivity:     monCfg.MaxInactivity,
+		RealertInterval:   monCfg.RealertInterval,
+		ReadTimeout:       monCfg.ReadTimeout,
+		StartupDeadline:   monCfg.StartupDeadline,
+		StartupUnhealthy:  monCfg.StartupUnhealthy,
+		FormatDrift:       monCfg.FormatDrift,
+		MatchDebug:        monCfg.MatchDebug,
+		TagAllowlist:      monCfg.TagAllowlist,
+		TagDenylist:       monCfg.TagDenylist,
+		MaxTagValueLength: monCfg.MaxTagValueLength,
+		ExpectPattern:     monCfg.ExpectPattern,
+		ExpectWithin:      monCfg.ExpectWithin,
+		RateLimitBurst:    monCfg.RateLimitBurst,
+		RateLimitWindow:   monCfg.RateLimitWindow,
+		RateLimitAlerts:   monCfg.RateLimitAlerts,
+		Cooldown:          monCfg.Cooldown,
+		DedupLifetime:     monCfg.DedupLifetime,
+		Warmup:            monCfg.Warmup,
+		SentryDSN:         sentryDSN,
+		SentryEnvironment: sentryEnv,
+		SentryRelease:     sentryRelease,
+		SentryDist:        sentryDist,
+		SentrySampleRate:  sentrySampleRate,
+		GroupBy:           groupByKey(monCfg),
+		MaxEventBytes:     monCfg.MaxEventBytes,
+		MaxFingerprints:   monCfg.MaxFingerprints,
+		ParallelDetect:    monCfg.ParallelDetect,
+		MaxBatchLines:     monCfg.MaxBatchLines,
+		MaxBatchBytes:     monCfg.MaxBatchBytes,
+		MaxBatchAge:       monCfg.MaxBatchAge,
+		GroupWindow:       monCfg.GroupWindow,
+		OmitRawLine:       monCfg.IncludeRawLine != nil && !*monCfg.IncludeRawLine,
+		OmitSysstat:       monCfg.AttachSysstat != nil && !*monCfg.AttachSysstat,
+		RawLineMaxBytes:   monCfg.RawLineMaxBytes,
+		MessageFrom:       monCfg.MessageFrom,
+		FingerprintFrom:   monCfg.FingerprintFrom,
+		TitleFrom:         monCfg.TitleFrom,
+		MinSendLevel:      monCfg.MinSendLevel,
+		BatchSeparator:    monCfg.BatchSeparator,
+		BatchOrder:        monCfg.BatchOrder,
+		MinLineLength:     monCfg.MinLineLength,
+		CollapseRepeats:   monCfg.CollapseRepeats,
+		IgnoreSubstrings:  monCfg.IgnoreSubstrings,
+		NoMatchLines:      monCfg.NoMatchLines,
+		TraceIDFrom:       monCfg.TraceIDFrom,
+		SpanIDFrom:        monCfg.SpanIDFrom,
+		UserFrom:          userFrom,
+		OnlyAfter:         monCfg.OnlyAfter,
+		OnlyBefore:        monCfg.OnlyBefore,
+		UntimedLines:      monCfg.UntimedLines,
+		Sequence:          monCfg.Sequence,
+		SequenceWithin:    monCfg.SequenceWithin,
+		ContextPattern:    monCfg.ContextPattern,
+		ContextWithin:     monCfg.ContextWithin,
+		Context:           monCfg.Context,
+		Redact:            monCfg.Redact,
+		StripSyslogHeader: monCfg.StripSyslogHeader,
+		Tags:              monCfg.Tags,
+		DeployInfo:        cfg.DeployInfo,
+		SentryTargets:     sentryTargets,
+		Routes:            routes,
+		GlobalRateLimiter: b.globalRateLimiter,
+		HTTPTransport:     b.httpTransport,
+		Exporters:         exps,
+
+		NormalizeControlChars:  monCfg.NormalizeControlChars,
+		FingerprintNormalizers: monCfg.FingerprintNormalizers,
+		ContextAttachLines:     monCfg.ContextAttachLines,
+	})
+	if err != nil {
+		log.Printf("Failed to create monitor '%s': %v", monCfg.Name, err)
+		for _, e := range exps {
+			e.Close()
+		}
+		return nil
+	}
+	// A replay ends with its file rather than restarting it
+	m.StopOnEOF = cfg.OneShot || monCfg.Replay
+	m.OnMatch = b.onMatch
+	return m
+}
+
+// enabledMonitors returns the monitor configs enabled in cfg's Sentry environment.
+func enabledMonitors(cfg *config.Config) []config.MonitorConfig {
+	var enabled []config.MonitorConfig
+	for _, monCfg := range cfg.Monitors {
+		if !monitorEnabled(monCfg, cfg.Sentry.Environment) {
+			if cfg.Verbose {
+				log.Printf("Skipping monitor '%s': not enabled for environment '%s'", monCfg.Name, cfg.Sentry.Environment)
+			}
+			continue
+		}
+		enabled = append(enabled, monCfg)
+	}
+	return enabled
+}
+
+// monitorGroup is a monitor config and the monitors running for it.
+type monitorGroup struct {
+	cfg      config.MonitorConfig
+	monitors []*monitor.Monitor
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// close asks the group's monitors to stop by closing their sources.
+func (g *monitorGroup) close() {
+	g.cancel()
+	for _, m := range g.monitors {
+		if err := m.Source.Close(); err != nil {
+			log.Printf("Error closing source %s: %v", m.Source.Name(), err)
+		}
+	}
+}
+
+// stop stops the group's monitors, waiting up to timeout for them to finish,
+// and flushes what they buffered.
+func (g *monitorGroup) stop(timeout time.Duration) {
+	g.close()
+	if !waitTimeout(&g.wg, timeout) {
+		log.Printf("Timeout waiting for monitor '%s' to stop", g.cfg.Name)
+	}
+	for _, m := range g.monitors {
+		m.Flush(2 * time.Second)
+	}
+}
+
+// waitTimeout waits for wg, reporting false if that takes longer than timeout.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// monitorSet holds the running monitor groups. A config reload replaces
+// groups while the health, stats and shutdown paths read them.
+type monitorSet struct {
+	mu     sync.Mutex
+	groups []*monitorGroup
+	wg     sync.WaitGroup // every monitor started, for --oneshot
+
+	reloadMu sync.Mutex // reloads run one at a time
+}
+
+// newGroup builds the monitors for monCfg without starting them. It returns
+// nil if no monitor could be created.
+func newGroup(ctx context.Context, b *monitorBuilder, monCfg config.MonitorConfig) *monitorGroup {
+	gctx, cancel := context.WithCancel(ctx)
+	monitors := b.build(gctx, monCfg)
+	if len(monitors) == 0 {
+		cancel()
+		return nil
+	}
+	return &monitorGroup{cfg: monCfg, monitors: monitors, cancel: cancel}
+}
+
+// start runs the group's monitors, each in its own goroutine.
+func (s *monitorSet) start(g *monitorGroup) {
+	for _, m := range g.monitors {
+		g.wg.Add(1)
+		s.wg.Add(1)
+		go func(mon *monitor.Monitor) {
+			defer s.wg.Done()
+			defer g.wg.Done()
+			mon.Start()
+		}(m)
+	}
+}
+
+// all returns the monitors of every group.
+func (s *monitorSet) all() []*monitor.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var monitors []*monitor.Monitor
+	for _, g := range s.groups {
+		monitors = append(monitors, g.monitors...)
+	}
+	return monitors
+}
+
+// stopAll stops every group, waiting up to timeout in total.
+func (s *monitorSet) stopAll(timeout time.Duration) {
+	s.mu.Lock()
+	groups := s.groups
+	s.mu.Unlock()
+
+	for _, g := range groups {
+		g.close()
+	}
+	if !waitTimeout(&s.wg, timeout) {
+		log.Println("Timeout waiting for monitors to stop")
+	}
+}
+
+// reload applies a new set of monitor configs in place. Monitors whose config
+// is unchanged keep running, so their sources are not restarted and files keep
+// their read position; changed and removed monitors are stopped (flushing what
+// they buffered) and changed and added ones are started.
+func (s *monitorSet) reload(ctx context.Context, b *monitorBuilder, monCfgs []config.MonitorConfig) (kept, stopped, started int) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.mu.Lock()
+	old := make(map[string]*monitorGroup, len(s.groups))
+	for _, g := range s.groups {
+		old[g.cfg.Name] = g
+	}
+	s.mu.Unlock()
+
+	// Stop first, so e.g. a changed syslog monitor can listen on its port again
+	var next []*monitorGroup
+	keep := make(map[string]bool)
+	for _, monCfg := range monCfgs {
+		if g := old[monCfg.Name]; g != nil && g.cfg.Equal(monCfg) {
+			keep[monCfg.Name] = true
+		}
+	}
+	for name, g := range old {
+		if keep[name] {
+			continue
+		}
+		g.stop(5 * time.Second)
+		stopped++
+	}
+
+	for _, monCfg := range monCfgs {
+		if keep[monCfg.Name] {
+			next = append(next, old[monCfg.Name])
+			kept++
+			continue
+		}
+		g := newGroup(ctx, b, monCfg)
+		if g == nil {
+			continue
+		}
+		s.start(g)
+		next = append(next, g)
+		started++
+	}
+
+	s.mu.Lock()
+	s.groups = next
+	s.mu.Unlock()
+	return kept, stopped, started
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+)
+
+// matchRecorder collects matched lines from all monitors.
+type matchRecorder struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (r *matchRecorder) add(line []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, string(line))
+}
+
+func (r *matchRecorder) count(line string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, l := range r.lines {
+		if l == line {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *matchRecorder) waitFor(t *testing.T, line string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for r.count(line) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %q to match %d times, got %d", line, n, r.count(line))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadKeepsUnchangedMonitors(t *testing.T) {
+	dir := t.TempDir()
+	appPath := filepath.Join(dir, "app.log")
+	dbPath := filepath.Join(dir, "db.log")
+	appendLine(t, appPath, "error app 1")
+	appendLine(t, dbPath, "error db 1")
+
+	// from_start only applies to the first read: a restarted source carries on
+	app := config.MonitorConfig{Name: "app", Type: "file", Path: appPath, Pattern: "error", FromStart: true}
+	db := config.MonitorConfig{Name: "db", Type: "file", Path: dbPath, Pattern: "error", FromStart: true}
+
+	rec := &matchRecorder{}
+	builder := &monitorBuilder{cfg: &config.Config{}, onMatch: rec.add}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	set := &monitorSet{}
+	for _, monCfg := range []config.MonitorConfig{app, db} {
+		g := newGroup(ctx, builder, monCfg)
+		if g == nil {
+			t.Fatalf("Failed to create monitor %s", monCfg.Name)
+		}
+		set.groups = append(set.groups, g)
+		set.start(g)
+	}
+	defer set.stopAll(5 * time.Second)
+
+	rec.waitFor(t, "error app 1", 1)
+	rec.waitFor(t, "error db 1", 1)
+	appMonitor := set.groups[0].monitors[0]
+
+	// Only db changes; an empty tags map is the same as none
+	app.Tags = map[string]string{}
+	db.Pattern = "(?i)error"
+	kept, stopped, started := set.reload(ctx, builder, []config.MonitorConfig{app, db})
+	if kept != 1 || stopped != 1 || started != 1 {
+		t.Fatalf("Expected 1 kept, 1 stopped and 1 started, got %d, %d and %d", kept, stopped, started)
+	}
+	if set.groups[0].monitors[0] != appMonitor {
+		t.Errorf("Expected the unchanged monitor to keep running")
+	}
+
+	// The restarted db monitor carries on from where the old one stopped...
+	appendLine(t, dbPath, "error db 2")
+	rec.waitFor(t, "error db 2", 1)
+	if n := rec.count("error db 1"); n != 1 {
+		t.Errorf("Expected the restarted monitor not to re-read its file, saw the first line %d times", n)
+	}
+
+	// ...while app carries on from where it was
+	appendLine(t, appPath, "error app 2")
+	rec.waitFor(t, "error app 2", 1)
+	if n := rec.count("error app 1"); n != 1 {
+		t.Errorf("Expected the unchanged monitor not to re-read its file, saw the first line %d times", n)
+	}
+
+	// Removing a monitor stops it
+	kept, stopped, started = set.reload(ctx, builder, []config.MonitorConfig{app})
+	if kept != 1 || stopped != 1 || started != 0 || len(set.all()) != 1 {
+		t.Errorf("Expected db to be removed, got %d kept, %d stopped, %d started", kept, stopped, started)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"syscall"
+	"time"
+)
+
+// execFunc replaces the current process. It is a variable so tests can stub it out.
+var execFunc = syscall.Exec
+
+// reexec removes the IPC socket and replaces the process with a fresh copy
+// of the same executable and arguments. It only returns if the exec fails.
+func reexec(socketPath string) error {
+	if socketPath != "" {
+		os.Remove(socketPath)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Re-executing %s %v", executable, os.Args[1:])
+	return execFunc(executable, os.Args, os.Environ())
+}
+
+// scheduleMaxLifetime calls restart once lifetime has elapsed, unless ctx is cancelled first.
+func scheduleMaxLifetime(ctx context.Context, lifetime time.Duration, restart func()) {
+	log.Printf("Maximum lifetime is %v, restart planned at %s", lifetime, time.Now().Add(lifetime).Format(time.RFC3339))
+
+	timer := time.NewTimer(lifetime)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+		log.Printf("Maximum lifetime of %v reached, restarting for a fresh process", lifetime)
+		restart()
+	}
+}
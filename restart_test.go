@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduleMaxLifetime(t *testing.T) {
+	var restarts int32
+	done := make(chan struct{})
+	go func() {
+		scheduleMaxLifetime(context.Background(), 50*time.Millisecond, func() {
+			atomic.AddInt32(&restarts, 1)
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for max lifetime restart")
+	}
+	if atomic.LoadInt32(&restarts) != 1 {
+		t.Errorf("Expected 1 restart, got %d", restarts)
+	}
+}
+
+func TestScheduleMaxLifetimeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var restarts int32
+	scheduleMaxLifetime(ctx, time.Hour, func() {
+		atomic.AddInt32(&restarts, 1)
+	})
+	if atomic.LoadInt32(&restarts) != 0 {
+		t.Errorf("Expected no restart after cancel, got %d", restarts)
+	}
+}
+
+func TestReexec(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "sentrylogmon.sock")
+	if err := os.WriteFile(socketPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var execPath string
+	var execArgs []string
+	origExec := execFunc
+	defer func() { execFunc = origExec }()
+	execFunc = func(argv0 string, argv []string, envv []string) error {
+		execPath = argv0
+		execArgs = argv
+		return nil
+	}
+
+	if err := reexec(socketPath); err != nil {
+		t.Fatalf("reexec failed: %v", err)
+	}
+
+	executable, _ := os.Executable()
+	if execPath != executable {
+		t.Errorf("Expected exec of %s, got %s", executable, execPath)
+	}
+	if len(execArgs) != len(os.Args) {
+		t.Errorf("Expected original args %v, got %v", os.Args, execArgs)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("Expected socket to be removed before exec, got err=%v", err)
+	}
+}
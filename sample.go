@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/detectors"
+)
+
+// sampleCollector keeps up to limit matched lines for --sample-matches,
+// scrubbed of personal data so they can be shared (e.g. in an issue).
+type sampleCollector struct {
+	limit    int
+	scrubber *detectors.Redactor
+
+	mu    sync.Mutex
+	lines []string
+	full  chan struct{} // closed once limit lines are collected
+}
+
+func newSampleCollector(limit int) (*sampleCollector, error) {
+	scrubber, err := detectors.NewRedactor(nil, true)
+	if err != nil {
+		return nil, err
+	}
+	return &sampleCollector{
+		limit:    limit,
+		scrubber: scrubber,
+		full:     make(chan struct{}),
+	}, nil
+}
+
+// add records a matched line, already redacted by its monitor. It is used as
+// monitor.Monitor.OnMatch and may be called from several monitors at once.
+func (c *sampleCollector) add(line []byte) {
+	scrubbed := string(c.scrubber.TransformMessage(line))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.lines) >= c.limit {
+		return
+	}
+	c.lines = append(c.lines, scrubbed)
+	if len(c.lines) == c.limit {
+		close(c.full)
+	}
+}
+
+// writeFile writes the collected lines to path and returns how many were written.
+func (c *sampleCollector) writeFile(path string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var sb strings.Builder
+	for _, line := range c.lines {
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return len(c.lines), os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+// prepareSampleConfig adjusts cfg for --sample-matches: monitors read their
+// input once from the start, and nothing is sent to Sentry.
+func prepareSampleConfig(cfg *config.Config) {
+	cfg.OneShot = true
+	cfg.Sentry.DSN = ""
+	for i := range cfg.Monitors {
+		cfg.Monitors[i].FromStart = true
+		cfg.Monitors[i].Sentry.DSN = ""
+		cfg.Monitors[i].SentryTargets = nil
+		cfg.Monitors[i].Routes = nil
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/monitor"
+)
+
+// stringSource is a LogSource that reads a fixed string once.
+type stringSource struct {
+	content string
+}
+
+func (s *stringSource) Name() string               { return "sample" }
+func (s *stringSource) Stream() (io.Reader, error) { return strings.NewReader(s.content), nil }
+func (s *stringSource) Close() error               { return nil }
+
+func TestSampleMatches(t *testing.T) {
+	input := strings.Join([]string{
+		"Error: login failed user=alice from 10.0.0.7",
+		"info: all good",
+		"Error: mail to bob@example.com bounced user=bob",
+		"Error: token=abc123 rejected user=carol",
+		"Error: fourth match user=dave",
+		"Error: fifth match user=erin",
+	}, "\n")
+
+	det, err := detectors.NewGenericDetector("Error")
+	if err != nil {
+		t.Fatalf("NewGenericDetector failed: %v", err)
+	}
+	mon, err := monitor.New(context.Background(), &stringSource{content: input}, det, nil, monitor.Options{
+		Redact: []string{`user=\w+`},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+
+	sample, err := newSampleCollector(3)
+	if err != nil {
+		t.Fatalf("newSampleCollector failed: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.OnMatch = sample.add
+	mon.Start()
+
+	select {
+	case <-sample.full:
+	default:
+		t.Error("Expected collector to report it is full")
+	}
+
+	path := filepath.Join(t.TempDir(), "sample.txt")
+	n, err := sample.writeFile(path)
+	if err != nil {
+		t.Fatalf("writeFile failed: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 lines written, got %d", n)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read sample: %v", err)
+	}
+	expected := "Error: login failed [REDACTED] from [REDACTED]\n" +
+		"Error: mail to [REDACTED] bounced [REDACTED]\n" +
+		"Error: token=[REDACTED] rejected [REDACTED]\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected sample:\n%s\nexpected:\n%s", data, expected)
+	}
+}
+
+func TestPrepareSampleConfig(t *testing.T) {
+	cfg := &config.Config{
+		Sentry: config.SentryConfig{DSN: "https://key@sentry.example.com/1"},
+		Monitors: []config.MonitorConfig{
+			{
+				Name:          "app",
+				Type:          "file",
+				Path:          "/var/log/app.log",
+				Sentry:        config.SentryConfig{DSN: "https://key@sentry.example.com/2"},
+				SentryTargets: []config.SentryConfig{{DSN: "https://key@sentry.example.com/3"}},
+			},
+		},
+	}
+
+	prepareSampleConfig(cfg)
+
+	if !cfg.OneShot {
+		t.Error("Expected sampling to run once")
+	}
+	if cfg.Sentry.DSN != "" || cfg.Monitors[0].Sentry.DSN != "" || len(cfg.Monitors[0].SentryTargets) != 0 {
+		t.Errorf("Expected all Sentry DSNs to be cleared, got %+v", cfg)
+	}
+	if !cfg.Monitors[0].FromStart {
+		t.Error("Expected file monitors to read from the start")
+	}
+}
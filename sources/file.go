@@ -1,6 +1,7 @@
 package sources
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"log"
@@ -12,29 +13,200 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
+// Follow modes for FileSource, matching tail's --follow=name and --follow=descriptor.
+const (
+	// FollowName follows the path: on rotation the old file is drained and the
+	// new file at the same path is opened once it appears.
+	FollowName = "name"
+	// FollowDescriptor follows the open file: it keeps being read after it is
+	// renamed or removed, and a new file at the path is ignored.
+	FollowDescriptor = "descriptor"
+)
+
+// FileOptions configures a FileSource.
+type FileOptions struct {
+	// FromStart reads existing content before tailing, instead of starting at the end.
+	// It only applies the first time the file is read: a source streamed again,
+	// or created again for the same path after the last one was closed, carries
+	// on from where reading stopped.
+	FromStart bool
+	// Follow is FollowName (default) or FollowDescriptor.
+	Follow string
+	// ReadBufferSize is the size of each read from the file (default: DefaultReadBufferSize).
+	ReadBufferSize int
+}
+
 type FileSource struct {
 	name      string
 	path      string
 	watcher   *fsnotify.Watcher
 	reader    *io.PipeReader
 	writer    *io.PipeWriter
+	fromStart bool
+	follow    string
+	bufSize   int
 	closeChan chan struct{}
 	wg        sync.WaitGroup
+
+	// Which file each part of the stream was read from, for LinesAround and
+	// to carry on where the last stream stopped; updated by run.
+	mu       sync.Mutex
+	segments []fileSegment
+	streamed int64 // bytes written to the stream so far
+}
+
+// fileSegment maps the stream from byte stream on to a file from offset on.
+type fileSegment struct {
+	stream int64
+	info   os.FileInfo
+	offset int64
 }
 
-func NewFileSource(name string, path string) *FileSource {
+// maxFileSegments bounds the segments a FileSource keeps, one per file
+// opened during a stream (e.g. after each rotation).
+const maxFileSegments = 16
+
+// filePosition is where in a file a stream stopped reading.
+type filePosition struct {
+	info   os.FileInfo
+	offset int64
+}
+
+// filePositions holds where closed FileSources stopped reading, by path, so a
+// source created again for the same file (e.g. by a configuration reload)
+// carries on from there instead of rereading it with FromStart.
+var filePositions = struct {
+	sync.Mutex
+	m map[string]filePosition
+}{m: make(map[string]filePosition)}
+
+// NewFileSource creates a source that tails path. By default only lines written
+// after startup are read and the path is followed across rotations.
+func NewFileSource(name string, path string, opts FileOptions) *FileSource {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		// Fallback to original if Abs fails, though unlikely
 		absPath = path
 	}
+	follow := opts.Follow
+	if follow == "" {
+		follow = FollowName
+	}
+	bufSize := opts.ReadBufferSize
+	if bufSize <= 0 {
+		bufSize = DefaultReadBufferSize
+	}
 	return &FileSource{
 		name:      name,
 		path:      absPath,
+		fromStart: opts.FromStart,
+		follow:    follow,
+		bufSize:   bufSize,
 		closeChan: make(chan struct{}),
 	}
 }
 
+// contextScanBytes bounds how much LinesAround reads on each side of a line.
+const contextScanBytes = 1024 * 1024
+
+// LinesAround rereads the file the stream's bytes at offset came from, at the
+// matching position, for line and the lines around it. If the file was
+// rotated away or no longer has line there (e.g. it was truncated), it
+// returns nil.
+func (s *FileSource) LinesAround(offset int64, line []byte, n int) ([]byte, error) {
+	s.mu.Lock()
+	var seg *fileSegment
+	for i := len(s.segments) - 1; i >= 0; i-- {
+		if s.segments[i].stream <= offset {
+			seg = &s.segments[i]
+			break
+		}
+	}
+	s.mu.Unlock()
+	if seg == nil {
+		return nil, nil
+	}
+	pos := seg.offset + offset - seg.stream
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !os.SameFile(info, seg.info) || pos >= info.Size() {
+		return nil, nil
+	}
+
+	start := max(pos-contextScanBytes, 0)
+	buf := make([]byte, min(info.Size(), pos+int64(len(line))+contextScanBytes)-start)
+	if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
+		return nil, err
+	}
+	before, after := buf[:pos-start], buf[pos-start:]
+	if !bytes.HasPrefix(after, line) {
+		return nil, nil
+	}
+
+	var window [][]byte
+	if len(before) > 0 {
+		lines := bytes.Split(bytes.TrimSuffix(before, []byte("\n")), []byte("\n"))
+		if start > 0 {
+			// Skip the partial first line
+			lines = lines[1:]
+		}
+		window = lines[max(len(lines)-n, 0):]
+	}
+	lines := bytes.Split(after, []byte("\n"))
+	if len(lines) > 1 && (len(lines[len(lines)-1]) == 0 || start+int64(len(buf)) < info.Size()) {
+		// Drop what follows the last newline, or the cut off last line
+		lines = lines[:len(lines)-1]
+	}
+	window = append(window, lines[:min(n+1, len(lines))]...)
+	for i, l := range window {
+		window[i] = bytes.TrimSuffix(l, []byte("\r"))
+	}
+	return append(bytes.Join(window, []byte("\n")), '\n'), nil
+}
+
+// positionLocked returns where the last stream stopped reading, if it opened a
+// file. s.mu must be held.
+func (s *FileSource) positionLocked() (filePosition, bool) {
+	if len(s.segments) == 0 {
+		return filePosition{}, false
+	}
+	last := s.segments[len(s.segments)-1]
+	return filePosition{info: last.info, offset: last.offset + s.streamed - last.stream}, true
+}
+
+// resumeOffset returns where in the file now at the path to carry on from
+// pos: the same offset if it is the same file and not truncated, or its start
+// if it was replaced in the meantime.
+func (s *FileSource) resumeOffset(pos filePosition) int64 {
+	info, err := os.Stat(s.path)
+	if err != nil || !os.SameFile(info, pos.info) || info.Size() < pos.offset {
+		return 0
+	}
+	return pos.offset
+}
+
+// startSegment records that the stream continues with f from offset on.
+func (s *FileSource) startSegment(f *os.File, offset int64) {
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.segments = append(s.segments, fileSegment{stream: s.streamed, info: info, offset: offset})
+	if len(s.segments) > maxFileSegments {
+		s.segments = s.segments[1:]
+	}
+}
+
 func (s *FileSource) Name() string {
 	return s.name
 }
@@ -53,6 +225,14 @@ func (s *FileSource) Close() error {
 
 	s.wg.Wait()
 
+	s.mu.Lock()
+	if pos, ok := s.positionLocked(); ok {
+		filePositions.Lock()
+		filePositions.m[s.path] = pos
+		filePositions.Unlock()
+	}
+	s.mu.Unlock()
+
 	if s.watcher != nil {
 		return s.watcher.Close()
 	}
@@ -66,23 +246,48 @@ func (s *FileSource) Stream() (io.Reader, error) {
 	}
 	s.watcher = watcher
 
+	select {
+	case <-s.closeChan:
+		s.closeChan = make(chan struct{})
+	default:
+	}
+
+	// Carry on where this source, or the last one closed for the path, stopped
+	s.mu.Lock()
+	filePositions.Lock()
+	last, resume := filePositions.m[s.path]
+	delete(filePositions.m, s.path)
+	filePositions.Unlock()
+	if pos, ok := s.positionLocked(); ok {
+		last, resume = pos, true
+	}
+	s.segments, s.streamed = nil, 0
+	s.mu.Unlock()
+	offset := int64(-1)
+	if resume {
+		offset = s.resumeOffset(last)
+	} else if s.fromStart {
+		offset = 0
+	}
+
 	pr, pw := io.Pipe()
 	s.reader = pr
 	s.writer = pw
 
 	s.wg.Add(1)
-	go s.run(watcher, pw)
+	go s.run(watcher, pw, s.closeChan, offset)
 
 	return pr, nil
 }
 
-func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter) {
+// run streams the file from offset on, or from its end if offset is negative.
+func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter, closeChan chan struct{}, offset int64) {
 	defer s.wg.Done()
 	defer pw.Close()
 
 	var file *os.File
-	// Reuse buffer to avoid allocation in loop. Increased to 32KB for better I/O performance.
-	buf := make([]byte, 32768)
+	// Reuse buffer to avoid allocation in loop. Larger buffers mean fewer reads on busy files.
+	buf := make([]byte, s.bufSize)
 
 	// Helper to safely read from file
 	readUntilEOF := func() {
@@ -92,7 +297,11 @@ func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter) {
 		for {
 			n, err := file.Read(buf)
 			if n > 0 {
-				if _, wErr := pw.Write(buf[:n]); wErr != nil {
+				w, wErr := pw.Write(buf[:n])
+				s.mu.Lock()
+				s.streamed += int64(w)
+				s.mu.Unlock()
+				if wErr != nil {
 					return // Pipe closed
 				}
 			}
@@ -106,7 +315,8 @@ func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter) {
 		}
 	}
 
-	openFile := func(seekEnd bool) {
+	// openFile opens the path at offset, or at its end if offset is negative.
+	openFile := func(offset int64) {
 		if file != nil {
 			file.Close()
 			file = nil
@@ -114,15 +324,21 @@ func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter) {
 		f, err := os.Open(s.path)
 		if err == nil {
 			file = f
-			if seekEnd {
-				file.Seek(0, io.SeekEnd)
+			if offset < 0 {
+				offset, _ = file.Seek(0, io.SeekEnd)
+			} else if offset > 0 {
+				offset, _ = file.Seek(offset, io.SeekStart)
 			}
+			s.startSegment(file, offset)
 			watcher.Add(s.path)
 		}
 	}
 
 	// Initial setup
-	openFile(true)
+	openFile(offset)
+	if offset >= 0 {
+		readUntilEOF()
+	}
 
 	parent := filepath.Dir(s.path)
 	if err := watcher.Add(parent); err != nil {
@@ -135,7 +351,7 @@ func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter) {
 
 	for {
 		select {
-		case <-s.closeChan:
+		case <-closeChan:
 			if file != nil {
 				file.Close()
 			}
@@ -144,10 +360,13 @@ func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter) {
 		case <-ticker.C:
 			// If file is missing, try to open it
 			if file == nil {
-				openFile(false) // Start from beginning if it reappeared
+				openFile(0) // Start from beginning if it reappeared
 				if file != nil {
 					readUntilEOF()
 				}
+			} else if s.follow == FollowDescriptor {
+				// Once rotated away, the file no longer gets events under our path
+				readUntilEOF()
 			}
 			// Ensure parent watch is active (idempotent)
 			watcher.Add(parent)
@@ -161,6 +380,11 @@ func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter) {
 				if event.Has(fsnotify.Write) {
 					readUntilEOF()
 				}
+				if s.follow == FollowDescriptor {
+					// Keep reading the open file; whatever now lives at the path is ignored
+					readUntilEOF()
+					continue
+				}
 				if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
 					// File rotated. Read remaining content if any.
 					readUntilEOF()
@@ -173,7 +397,7 @@ func (s *FileSource) run(watcher *fsnotify.Watcher, pw *io.PipeWriter) {
 				if event.Has(fsnotify.Create) {
 					// File created (should come from parent watch, but if we somehow watched s.path before??)
 					// Actually, Create event on s.path only happens if we are watching parent.
-					openFile(false)
+					openFile(0)
 					readUntilEOF()
 				}
 			}
@@ -2,7 +2,10 @@ package sources
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -54,3 +57,40 @@ func BenchmarkReadLoop(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkFileSourceReadBufferSize reads a 10MB file through FileSource with
+// different read_buffer_size values. reads/op counts the chunks written to the
+// pipe, one per file read.
+func BenchmarkFileSourceReadBufferSize(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "high-volume.log")
+	line := []byte("2024-01-01T00:00:00Z INFO request handled path=/api/v1/items status=200 duration=12ms\n")
+	data := bytes.Repeat(line, (10*1024*1024)/len(line))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, size := range []int{MinReadBufferSize, DefaultReadBufferSize, 1024 * 1024} {
+		b.Run(fmt.Sprintf("%dKB", size/1024), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			buf := make([]byte, MaxReadBufferSize)
+			var reads int
+			for i := 0; i < b.N; i++ {
+				src := NewFileSource("bench", path, FileOptions{FromStart: true, ReadBufferSize: size})
+				r, err := src.Stream()
+				if err != nil {
+					b.Fatal(err)
+				}
+				for total := 0; total < len(data); {
+					n, err := r.Read(buf)
+					if err != nil {
+						b.Fatal(err)
+					}
+					total += n
+					reads++
+				}
+				src.Close()
+			}
+			b.ReportMetric(float64(reads)/float64(b.N), "reads/op")
+		})
+	}
+}
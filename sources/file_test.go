@@ -2,8 +2,10 @@ package sources
 
 import (
 	"bufio"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -28,7 +30,7 @@ func TestFileSourceRotation(t *testing.T) {
 	f.Close()
 
 	// Start source
-	src := NewFileSource("test", logPath)
+	src := NewFileSource("test", logPath, FileOptions{})
 	stream, err := src.Stream()
 	if err != nil {
 		t.Fatal(err)
@@ -97,3 +99,244 @@ func TestFileSourceRotation(t *testing.T) {
 		t.Errorf("Expected 'line 2', got '%s'", line)
 	}
 }
+
+func TestFileSourceFromStart(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sentrylogmon_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	logPath := filepath.Join(tmpDir, "test.log")
+	if err := os.WriteFile(logPath, []byte("existing 1\nexisting 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewFileSource("test", logPath, FileOptions{FromStart: true})
+	stream, err := src.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	readLine := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			return "TIMEOUT"
+		}
+	}
+
+	// Pre-existing content is read first
+	for _, want := range []string{"existing 1", "existing 2"} {
+		if line := readLine(); line != want {
+			t.Errorf("Expected '%s', got '%s'", want, line)
+		}
+	}
+
+	// Then the source keeps tailing
+	time.Sleep(200 * time.Millisecond)
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("appended\n")
+	f.Sync()
+	f.Close()
+
+	if line := readLine(); line != "appended" {
+		t.Errorf("Expected 'appended', got '%s'", line)
+	}
+}
+
+func TestFileSourceResumesAfterClose(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(logPath, []byte("existing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	appendLine := func(line string) {
+		f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(line + "\n")
+		f.Close()
+	}
+	// readLine reads the next line of the stream, which must be want
+	readLine := func(stream io.Reader, want string) {
+		t.Helper()
+		got := make(chan string, 1)
+		go func() {
+			buf := make([]byte, len(want)+1)
+			n, _ := io.ReadFull(stream, buf)
+			got <- string(buf[:n])
+		}()
+		select {
+		case line := <-got:
+			if line != want+"\n" {
+				t.Errorf("Expected %q, got %q", want+"\n", line)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %q", want)
+		}
+	}
+
+	src := NewFileSource("test", logPath, FileOptions{FromStart: true})
+	stream, err := src.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	readLine(stream, "existing")
+	src.Close()
+
+	// Streaming again carries on where the source stopped...
+	appendLine("while stopped")
+	stream, err = src.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	readLine(stream, "while stopped")
+	src.Close()
+
+	// ...and so does a new source for the same file
+	appendLine("after reload")
+	src = NewFileSource("test", logPath, FileOptions{FromStart: true})
+	defer src.Close()
+	stream, err = src.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	readLine(stream, "after reload")
+}
+
+func TestFileSourceFollowDescriptor(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sentrylogmon_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	logPath := filepath.Join(tmpDir, "test.log")
+	if err := os.WriteFile(logPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewFileSource("test", logPath, FileOptions{Follow: FollowDescriptor})
+	stream, err := src.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	readLine := func(timeout time.Duration) string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(timeout):
+			return "TIMEOUT"
+		}
+	}
+
+	appendLine := func(path, line string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(line + "\n")
+		f.Sync()
+		f.Close()
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	appendLine(logPath, "line 1")
+	if line := readLine(2 * time.Second); line != "line 1" {
+		t.Errorf("Expected 'line 1', got '%s'", line)
+	}
+
+	// Rotate: the writer keeps appending to the renamed file, and a new file appears at the path
+	rotatedPath := logPath + ".1"
+	if err := os.Rename(logPath, rotatedPath); err != nil {
+		t.Fatal(err)
+	}
+	appendLine(logPath, "new file line")
+	appendLine(rotatedPath, "line 2")
+
+	// The rotated-away file keeps being drained
+	if line := readLine(3 * time.Second); line != "line 2" {
+		t.Errorf("Expected 'line 2' from rotated file, got '%s'", line)
+	}
+	// The new file at the path is not followed
+	if line := readLine(1500 * time.Millisecond); line != "TIMEOUT" {
+		t.Errorf("Expected no lines from the new file, got '%s'", line)
+	}
+}
+
+func TestFileSourceDefaultFollowName(t *testing.T) {
+	if src := NewFileSource("test", "test.log", FileOptions{}); src.follow != FollowName {
+		t.Errorf("Expected default follow mode %q, got %q", FollowName, src.follow)
+	}
+}
+
+func TestFileSourceLinesAround(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	content := "boot\nstarting\nFATAL crash\nstack frame 1\nstack frame 2\nrestarting\nFATAL crash\ndone\n"
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := NewFileSource("test", logPath, FileOptions{FromStart: true})
+	defer src.Close()
+	reader, err := src.Stream()
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if _, err := io.ReadFull(reader, make([]byte, len(content))); err != nil {
+		t.Fatalf("Failed to read the stream: %v", err)
+	}
+
+	first := int64(strings.Index(content, "FATAL crash"))
+	second := int64(strings.LastIndex(content, "FATAL crash"))
+	tests := []struct {
+		name   string
+		offset int64
+		line   string
+		n      int
+		want   string
+	}{
+		{"first occurrence", first, "FATAL crash", 1, "starting\nFATAL crash\nstack frame 1\n"},
+		{"second occurrence", second, "FATAL crash", 1, "restarting\nFATAL crash\ndone\n"},
+		{"clipped at the start", 5, "starting", 3, "boot\nstarting\nFATAL crash\nstack frame 1\nstack frame 2\n"},
+		{"clipped at the end", int64(len(content) - 5), "done", 2, "restarting\nFATAL crash\ndone\n"},
+		{"different line at offset", first, "no such line", 2, ""},
+		{"past the end", int64(len(content)), "done", 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := src.LinesAround(tt.offset, []byte(tt.line), tt.n)
+			if err != nil {
+				t.Fatalf("LinesAround returned error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("LinesAround(%d, %q, %d) = %q, want %q", tt.offset, tt.line, tt.n, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package sources
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// HTTPStreamSource streams the body of a long-lived HTTP GET response, such
+// as a chunked log-streaming endpoint. A gzip-encoded body is decompressed as
+// it arrives. When the server ends the response, the monitor requests it
+// again.
+type HTTPStreamSource struct {
+	name   string
+	url    string
+	client *http.Client
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func NewHTTPStreamSource(name, url string) *HTTPStreamSource {
+	return &HTTPStreamSource{
+		name: name,
+		url:  url,
+		// No timeout, since the response never ends
+		client: &http.Client{},
+	}
+}
+
+func (s *HTTPStreamSource) Name() string {
+	return s.name
+}
+
+func (s *HTTPStreamSource) Stream() (io.Reader, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// Asking for gzip ourselves turns off the transport's own decompression,
+	// so the body is decompressed below whatever the server chooses
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("%s returned status: %s", s.url, resp.Status)
+	}
+
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return &gzipStreamReader{body: resp.Body}, nil
+	}
+	return resp.Body, nil
+}
+
+func (s *HTTPStreamSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	return nil
+}
+
+// gzipStreamReader decompresses a body that may never end. The gzip header is
+// only read on the first Read, so Stream does not wait for the server's first
+// chunk, and each Read returns what the data received so far decodes to
+// (servers flush whole deflate blocks with each chunk) instead of waiting for
+// more.
+type gzipStreamReader struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+}
+
+func (g *gzipStreamReader) Read(p []byte) (int, error) {
+	if g.zr == nil {
+		zr, err := gzip.NewReader(g.body)
+		if err != nil {
+			g.body.Close()
+			return 0, err
+		}
+		g.zr = zr
+	}
+	n, err := g.zr.Read(p)
+	if err != nil {
+		g.body.Close()
+	}
+	return n, err
+}
@@ -0,0 +1,96 @@
+package sources
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPStreamSourceGzip(t *testing.T) {
+	// next releases the server's next line, so lines can only arrive one by one
+	next := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Expected Accept-Encoding: gzip, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush() // headers only; the gzip header comes with the first line
+		zw := gzip.NewWriter(w)
+		for _, line := range []string{"starting", "ERROR db timeout", "recovered"} {
+			select {
+			case <-next:
+			case <-r.Context().Done():
+				return
+			}
+			zw.Write([]byte(line + "\n"))
+			zw.Flush()
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done() // a stream that never ends
+	}))
+	defer server.Close()
+
+	src := NewHTTPStreamSource("stream", server.URL)
+	defer src.Close() // before server.Close, which waits for the request
+	reader, err := src.Stream()
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	for _, want := range []string{"starting", "ERROR db timeout", "recovered"} {
+		next <- struct{}{}
+		select {
+		case got := <-lines:
+			if got != want {
+				t.Errorf("Expected line %q, got %q", want, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %q; lines are not decoded as they arrive", want)
+		}
+	}
+}
+
+func TestHTTPStreamSourcePlain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("one\ntwo\n"))
+	}))
+	defer server.Close()
+
+	src := NewHTTPStreamSource("stream", server.URL)
+	defer src.Close()
+	reader, err := src.Stream()
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	var got []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Errorf("Expected [one two], got %v", got)
+	}
+}
+
+func TestHTTPStreamSourceStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := NewHTTPStreamSource("stream", server.URL).Stream(); err == nil {
+		t.Error("Expected an error for a 404 response")
+	}
+}
@@ -0,0 +1,371 @@
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Paths of the service account credentials mounted into every pod.
+const (
+	k8sServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	k8sTokenFile         = k8sServiceAccountDir + "/token"
+	k8sCAFile            = k8sServiceAccountDir + "/ca.crt"
+	k8sNamespaceFile     = k8sServiceAccountDir + "/namespace"
+)
+
+// DefaultK8sResync is how often K8sSource lists pods to pick up new ones.
+const DefaultK8sResync = 10 * time.Second
+
+// Labels that K8sSource attaches to each line, see LineLabeler.
+const (
+	K8sNamespaceLabel = "k8s_namespace"
+	K8sPodLabel       = "k8s_pod"
+	K8sContainerLabel = "k8s_container"
+)
+
+// LineLabeler is implemented by sources that prefix each line with where it
+// came from. Labels splits a line into those labels and the original line; a
+// line without labels is returned unchanged with nil labels.
+type LineLabeler interface {
+	Labels(line []byte) (map[string]string, []byte)
+}
+
+// K8sOptions configures a K8sSource. Inside a cluster only Namespace and
+// LabelSelector are usually needed; the API server and credentials default to
+// the pod's service account.
+type K8sOptions struct {
+	Namespace     string // empty: the pod's own namespace in-cluster, otherwise all namespaces
+	LabelSelector string // e.g. "app=web,tier!=cache"; empty selects all pods
+	Container     string // only stream this container; empty streams all of them
+	APIServer     string // e.g. https://10.0.0.1:6443; empty uses the in-cluster address
+	TokenFile     string // bearer token file; empty uses the service account token in-cluster
+	CAFile        string // CA bundle for the API server; empty uses the service account CA in-cluster
+	Resync        time.Duration
+
+	// HTTPClient overrides the client built from CAFile, e.g. in tests.
+	HTTPClient *http.Client
+}
+
+// K8sSource streams the logs of the pods matching a label selector through
+// the Kubernetes API, one request per container. Pods are listed every
+// Resync so new pods are picked up and streams of restarted containers are
+// reopened; lines are prefixed with "[namespace/pod/container] " like
+// `kubectl logs --prefix`, and Labels turns the prefix into tags.
+type K8sSource struct {
+	name   string
+	opts   K8sOptions
+	client *http.Client
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	writer  *io.PipeWriter
+	streams map[string]bool      // namespace/pod/container being streamed
+	since   map[string]time.Time // where a reopened stream continues from
+	wg      sync.WaitGroup
+}
+
+func NewK8sSource(name string, opts K8sOptions) (*K8sSource, error) {
+	inCluster := opts.APIServer == ""
+	if inCluster {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("not running in a cluster (KUBERNETES_SERVICE_HOST is unset); set k8s.api_server")
+		}
+		opts.APIServer = "https://" + net.JoinHostPort(host, port)
+		if opts.TokenFile == "" {
+			opts.TokenFile = k8sTokenFile
+		}
+		if opts.CAFile == "" {
+			opts.CAFile = k8sCAFile
+		}
+		if opts.Namespace == "" {
+			if ns, err := os.ReadFile(k8sNamespaceFile); err == nil {
+				opts.Namespace = strings.TrimSpace(string(ns))
+			}
+		}
+	}
+	if opts.Resync <= 0 {
+		opts.Resync = DefaultK8sResync
+	}
+
+	s := &K8sSource{name: name, opts: opts, client: opts.HTTPClient}
+	// Fail fast on a missing token; it is read again for each request
+	if _, err := s.bearerToken(); err != nil {
+		return nil, err
+	}
+	if s.client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if opts.CAFile != "" {
+			pem, err := os.ReadFile(opts.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %v", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", opts.CAFile)
+			}
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		}
+		s.client = &http.Client{Transport: transport}
+	}
+	return s, nil
+}
+
+func (s *K8sSource) Name() string {
+	return s.name
+}
+
+func (s *K8sSource) Stream() (io.Reader, error) {
+	// Fail fast on bad credentials or selectors instead of streaming nothing
+	ctx, cancel := context.WithCancel(context.Background())
+	pods, err := s.listPods(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	s.mu.Lock()
+	s.cancel = cancel
+	s.writer = pw
+	s.streams = make(map[string]bool)
+	if s.since == nil {
+		s.since = make(map[string]time.Time)
+	}
+	s.mu.Unlock()
+
+	start := time.Now()
+	s.reconcile(ctx, pw, pods, start)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.opts.Resync)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pods, err := s.listPods(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Printf("k8s source %s: failed to list pods: %v", s.name, err)
+					}
+					continue
+				}
+				s.reconcile(ctx, pw, pods, start)
+			}
+		}
+	}()
+	return pr, nil
+}
+
+func (s *K8sSource) Close() error {
+	s.mu.Lock()
+	cancel, writer := s.cancel, s.writer
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if writer != nil {
+		writer.Close()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// Labels splits the "[namespace/pod/container] " prefix off a line.
+func (s *K8sSource) Labels(line []byte) (map[string]string, []byte) {
+	if len(line) == 0 || line[0] != '[' {
+		return nil, line
+	}
+	end := bytes.Index(line, []byte("] "))
+	if end < 0 {
+		return nil, line
+	}
+	parts := strings.Split(string(line[1:end]), "/")
+	if len(parts) != 3 {
+		return nil, line
+	}
+	return map[string]string{
+		K8sNamespaceLabel: parts[0],
+		K8sPodLabel:       parts[1],
+		K8sContainerLabel: parts[2],
+	}, line[end+2:]
+}
+
+type k8sPod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		Containers []struct {
+			Name string `json:"name"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+func (s *K8sSource) listPods(ctx context.Context) ([]k8sPod, error) {
+	path := "/api/v1/pods"
+	if s.opts.Namespace != "" {
+		path = "/api/v1/namespaces/" + url.PathEscape(s.opts.Namespace) + "/pods"
+	}
+	query := url.Values{}
+	if s.opts.LabelSelector != "" {
+		query.Set("labelSelector", s.opts.LabelSelector)
+	}
+
+	resp, err := s.get(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Items []k8sPod `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode pod list: %v", err)
+	}
+	return list.Items, nil
+}
+
+// reconcile starts a log stream for every running container not already streamed.
+func (s *K8sSource) reconcile(ctx context.Context, pw *io.PipeWriter, pods []k8sPod, start time.Time) {
+	for _, pod := range pods {
+		if pod.Status.Phase != "Running" {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			if s.opts.Container != "" && c.Name != s.opts.Container {
+				continue
+			}
+			key := pod.Metadata.Namespace + "/" + pod.Metadata.Name + "/" + c.Name
+
+			s.mu.Lock()
+			if s.streams[key] {
+				s.mu.Unlock()
+				continue
+			}
+			s.streams[key] = true
+			since, ok := s.since[key]
+			if !ok {
+				since = start
+			}
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go s.streamContainer(ctx, pw, pod.Metadata.Namespace, pod.Metadata.Name, c.Name, key, since)
+		}
+	}
+}
+
+// streamContainer copies one container's log to the pipe until the request
+// ends (container exit, pod deletion or Close).
+func (s *K8sSource) streamContainer(ctx context.Context, pw *io.PipeWriter, namespace, pod, container, key string, since time.Time) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, key)
+		// A reopened stream (e.g. after a container restart) continues from here
+		s.since[key] = time.Now()
+		s.mu.Unlock()
+	}()
+
+	query := url.Values{}
+	query.Set("follow", "true")
+	query.Set("container", container)
+	query.Set("sinceTime", since.UTC().Format(time.RFC3339))
+	path := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods/" + url.PathEscape(pod) + "/log"
+
+	resp, err := s.get(ctx, path, query)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("k8s source %s: failed to stream %s: %v", s.name, key, err)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	prefix := []byte("[" + key + "] ")
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if line[len(line)-1] != '\n' {
+				line = append(line, '\n')
+			}
+			// One write per line so lines from different containers don't interleave
+			out := make([]byte, 0, len(prefix)+len(line))
+			out = append(out, prefix...)
+			out = append(out, line...)
+			if _, wErr := pw.Write(out); wErr != nil {
+				return // Pipe closed
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *K8sSource) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := strings.TrimSuffix(s.opts.APIServer, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.bearerToken()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// bearerToken reads the token file, if any. It is read for each request, as
+// client-go does, since projected service account tokens expire and the
+// kubelet rewrites the file with a fresh one.
+func (s *K8sSource) bearerToken() (string, error) {
+	if s.opts.TokenFile == "" {
+		return "", nil
+	}
+	token, err := os.ReadFile(s.opts.TokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %v", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
@@ -0,0 +1,190 @@
+package sources
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeK8sAPI serves a pod list and follows container logs like the Kubernetes API.
+// Pods listed after the first request include web-3, to exercise resyncs.
+func fakeK8sAPI(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	var lists int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/namespaces/default/pods", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if got := r.URL.Query().Get("labelSelector"); got != "app=web" {
+			t.Errorf("Expected labelSelector app=web, got %q", got)
+		}
+		pods := `{"metadata":{"name":"web-1","namespace":"default"},"spec":{"containers":[{"name":"app"},{"name":"sidecar"}]},"status":{"phase":"Running"}},
+			{"metadata":{"name":"web-2","namespace":"default"},"spec":{"containers":[{"name":"app"}]},"status":{"phase":"Pending"}}`
+		if atomic.AddInt32(&lists, 1) > 1 {
+			pods += `,{"metadata":{"name":"web-3","namespace":"default"},"spec":{"containers":[{"name":"app"}]},"status":{"phase":"Running"}}`
+		}
+		fmt.Fprintf(w, `{"items":[%s]}`, pods)
+	})
+	logs := map[string]string{
+		"web-1/app":     "starting\nERROR db timeout\n",
+		"web-1/sidecar": "proxy ready\n",
+		"web-3/app":     "ERROR late pod\n",
+	}
+	for _, pod := range []string{"web-1", "web-2", "web-3"} {
+		pod := pod
+		mux.HandleFunc("/api/v1/namespaces/default/pods/"+pod+"/log", func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			if q.Get("follow") != "true" || q.Get("sinceTime") == "" {
+				t.Errorf("Expected a followed log request with sinceTime, got %s", r.URL.RawQuery)
+			}
+			body, ok := logs[pod+"/"+q.Get("container")]
+			if !ok {
+				t.Errorf("Unexpected log request for %s/%s", pod, q.Get("container"))
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, body)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done() // keep following until the source closes
+		})
+	}
+	return httptest.NewServer(mux)
+}
+
+func TestK8sSource(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	server := fakeK8sAPI(t, "s3cret")
+	defer server.Close()
+
+	src, err := NewK8sSource("pods", K8sOptions{
+		Namespace:     "default",
+		LabelSelector: "app=web",
+		APIServer:     server.URL,
+		TokenFile:     tokenFile,
+		Resync:        50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewK8sSource failed: %v", err)
+	}
+	defer src.Close() // before server.Close, which waits for the followed requests
+	reader, err := src.Stream()
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	got := make(map[string]bool)
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	timeout := time.After(3 * time.Second)
+	for len(got) < 4 {
+		select {
+		case line := <-lines:
+			got[line] = true
+		case <-timeout:
+			t.Fatalf("Timed out, got %v", got)
+		}
+	}
+
+	for _, want := range []string{
+		"[default/web-1/app] starting",
+		"[default/web-1/app] ERROR db timeout",
+		"[default/web-1/sidecar] proxy ready",
+		"[default/web-3/app] ERROR late pod",
+	} {
+		if !got[want] {
+			t.Errorf("Expected line %q, got %v", want, got)
+		}
+	}
+
+	labels, line := src.Labels([]byte("[default/web-1/app] ERROR db timeout"))
+	if string(line) != "ERROR db timeout" {
+		t.Errorf("Expected prefix stripped, got %q", line)
+	}
+	if labels[K8sNamespaceLabel] != "default" || labels[K8sPodLabel] != "web-1" || labels[K8sContainerLabel] != "app" {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		src.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not stop the log streams")
+	}
+}
+
+func TestK8sSourceUnauthorized(t *testing.T) {
+	server := fakeK8sAPI(t, "s3cret")
+	defer server.Close()
+
+	src, err := NewK8sSource("pods", K8sOptions{Namespace: "default", LabelSelector: "app=web", APIServer: server.URL})
+	if err != nil {
+		t.Fatalf("NewK8sSource failed: %v", err)
+	}
+	if _, err := src.Stream(); err == nil {
+		t.Error("Expected Stream to fail without a valid token")
+	}
+}
+
+func TestK8sSourceNotInCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	if _, err := NewK8sSource("pods", K8sOptions{}); err == nil {
+		t.Error("Expected error outside a cluster without api_server")
+	}
+}
+
+func TestK8sSourceRereadsToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var valid atomic.Value
+	valid.Store("first")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid.Load().(string) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"items":[]}`)
+	}))
+	defer server.Close()
+
+	src, err := NewK8sSource("pods", K8sOptions{Namespace: "default", APIServer: server.URL, TokenFile: tokenFile})
+	if err != nil {
+		t.Fatalf("NewK8sSource failed: %v", err)
+	}
+	defer src.Close()
+	if _, err := src.Stream(); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	src.Close()
+
+	// The kubelet rotates the projected token; the old one is rejected
+	valid.Store("second")
+	if err := os.WriteFile(tokenFile, []byte("second\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Stream(); err != nil {
+		t.Errorf("Expected the rotated token to be used, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// PollOptions configures a PollingCommandSource.
+type PollOptions struct {
+	Interval time.Duration
+	// NewLinesOnly passes on only the lines that were not in the previous
+	// run's output, instead of the whole output every time.
+	NewLinesOnly bool
+}
+
+// PollingCommandSource runs a point-in-time command (e.g. "zpool status" or
+// "smartctl -a /dev/sda") every interval and streams its output, so it can be
+// monitored like a log. The first run starts immediately.
+type PollingCommandSource struct {
+	name    string
+	command string
+	args    []string
+	opts    PollOptions
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func NewPollingCommandSource(name string, opts PollOptions, command string, args ...string) *PollingCommandSource {
+	return &PollingCommandSource{
+		name:    name,
+		command: command,
+		args:    args,
+		opts:    opts,
+	}
+}
+
+func (s *PollingCommandSource) Stream() (io.Reader, error) {
+	if _, err := exec.LookPath(s.command); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	pr, pw := io.Pipe()
+	go func() {
+		// Also unblocks a write nobody reads anymore
+		<-ctx.Done()
+		pw.Close()
+	}()
+	go func() {
+		defer pw.Close()
+		var prev map[string]int
+		for {
+			out := s.run(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if s.opts.NewLinesOnly {
+				out, prev = newLines(out, prev)
+			}
+			if _, err := pw.Write(out); err != nil {
+				return
+			}
+
+			timer := time.NewTimer(s.opts.Interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return pr, nil
+}
+
+// run runs the command once and returns its output, ending in a newline
+// unless empty. A non-zero exit is only logged, since diagnostic commands
+// (e.g. smartctl) use it to report what they found.
+func (s *PollingCommandSource) run(ctx context.Context) []byte {
+	out, err := exec.CommandContext(ctx, s.command, s.args...).Output()
+	if err != nil && ctx.Err() == nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			log.Printf("Polling command source '%s' (%s) failed: %v", s.name, s.command, err)
+			return nil
+		}
+		log.Printf("Polling command source '%s' (%s) exited with error: %v", s.name, s.command, err)
+	}
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return out
+}
+
+// newLines returns the lines of out that are not in the previous output, as
+// counted in prev (a line repeated three times where it was there once
+// yields two), along with the counts of out for the next run.
+func newLines(out []byte, prev map[string]int) ([]byte, map[string]int) {
+	counts := make(map[string]int)
+	var fresh []byte
+	for _, line := range bytes.SplitAfter(out, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		key := string(line)
+		counts[key]++
+		if counts[key] > prev[key] {
+			fresh = append(fresh, line...)
+		}
+	}
+	return fresh, counts
+}
+
+func (s *PollingCommandSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	return nil
+}
+
+func (s *PollingCommandSource) Name() string {
+	return s.name
+}
@@ -0,0 +1,82 @@
+package sources
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPollingCommandSource(t *testing.T) {
+	status := filepath.Join(t.TempDir(), "status")
+	if err := os.WriteFile(status, []byte("pool: tank\nstate: ONLINE\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name         string
+		newLinesOnly bool
+		want         []string
+	}{
+		{"Whole Output", false, []string{"pool: tank", "state: ONLINE", "pool: tank", "state: DEGRADED"}},
+		{"New Lines Only", true, []string{"pool: tank", "state: ONLINE", "state: DEGRADED"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := os.WriteFile(status, []byte("pool: tank\nstate: ONLINE\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			src := NewPollingCommandSource("zpool", PollOptions{Interval: 200 * time.Millisecond, NewLinesOnly: tc.newLinesOnly}, "cat", status)
+			reader, err := src.Stream()
+			if err != nil {
+				t.Fatalf("Stream failed: %v", err)
+			}
+			defer src.Close()
+
+			lines := make(chan string)
+			go func() {
+				scanner := bufio.NewScanner(reader)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+				close(lines)
+			}()
+
+			for i, want := range tc.want {
+				if i == 2 {
+					// The first run has been read; change the output before the next one
+					if err := os.WriteFile(status, []byte("pool: tank\nstate: DEGRADED\n"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				select {
+				case got := <-lines:
+					if got != want {
+						t.Errorf("Line %d: expected %q, got %q", i, want, got)
+					}
+				case <-time.After(2 * time.Second):
+					t.Fatalf("Timed out waiting for %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestPollingCommandSourceMissingCommand(t *testing.T) {
+	src := NewPollingCommandSource("missing", PollOptions{Interval: time.Second}, "no-such-command-sentrylogmon")
+	if _, err := src.Stream(); err == nil {
+		src.Close()
+		t.Error("Expected an error for a missing command")
+	}
+}
+
+func TestNewLines(t *testing.T) {
+	out, prev := newLines([]byte("a\nb\n"), nil)
+	if string(out) != "a\nb\n" {
+		t.Errorf("Expected the whole first output, got %q", out)
+	}
+	out, _ = newLines([]byte("b\nc\nb\n"), prev)
+	if string(out) != "c\nb\n" {
+		t.Errorf("Expected only the new lines, got %q", out)
+	}
+}
@@ -0,0 +1,113 @@
+package sources
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/angch/sentrylogmon/detectors"
+)
+
+// ReplayOptions configures a ReplaySource.
+type ReplayOptions struct {
+	// Speed divides the recorded gaps between lines: 1 (default) replays in
+	// real time, 60 replays an hour of logs in a minute.
+	Speed float64
+}
+
+// ReplaySource reads a captured log file once, pacing lines by the gaps
+// between their timestamps, so grouping, rate limiting and inactivity alerts
+// see realistic timing. Lines without a timestamp are emitted right away.
+// The stream ends at the end of the file.
+type ReplaySource struct {
+	name      string
+	path      string
+	speed     float64
+	writer    *io.PipeWriter
+	closeChan chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewReplaySource(name string, path string, opts ReplayOptions) *ReplaySource {
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	return &ReplaySource{
+		name:      name,
+		path:      path,
+		speed:     speed,
+		closeChan: make(chan struct{}),
+	}
+}
+
+func (s *ReplaySource) Name() string {
+	return s.name
+}
+
+func (s *ReplaySource) Stream() (io.Reader, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %v", err)
+	}
+
+	pr, pw := io.Pipe()
+	s.writer = pw
+	s.wg.Add(1)
+	go s.run(f, pw)
+	return pr, nil
+}
+
+func (s *ReplaySource) run(f *os.File, pw *io.PipeWriter) {
+	defer s.wg.Done()
+	defer f.Close()
+	defer pw.Close()
+
+	r := bufio.NewReader(f)
+	var last float64
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			if ts, _ := detectors.ExtractTimestamp(line); ts > 0 {
+				if last > 0 && ts > last {
+					gap := time.Duration((ts - last) / s.speed * float64(time.Second))
+					if !s.wait(gap) {
+						return
+					}
+				}
+				last = ts
+			}
+			if _, wErr := pw.Write(line); wErr != nil {
+				return // Pipe closed
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// wait sleeps for d and reports false if the source was closed meanwhile.
+func (s *ReplaySource) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.closeChan:
+		return false
+	}
+}
+
+func (s *ReplaySource) Close() error {
+	s.closeOnce.Do(func() { close(s.closeChan) })
+	if s.writer != nil {
+		s.writer.Close() // unblocks a pending write
+	}
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,90 @@
+package sources
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReplaySource_Pacing(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "captured.log")
+	content := "2024-01-01T00:00:00Z first\n" +
+		"untimed continuation\n" +
+		"2024-01-01T00:00:02Z second\n" +
+		"2024-01-01T00:00:03Z third\n"
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 20x: the 2s and 1s gaps become 100ms and 50ms
+	src := NewReplaySource("replay", logPath, ReplayOptions{Speed: 20})
+	reader, err := src.Stream()
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	defer src.Close()
+
+	var lines []string
+	var times []time.Time
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		times = append(times, time.Now())
+	}
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d: %q", len(lines), lines)
+	}
+
+	checkGap := func(name string, got, want time.Duration) {
+		t.Helper()
+		if got < want*8/10 || got > want*3 {
+			t.Errorf("%s: expected a gap of about %v, got %v", name, want, got)
+		}
+	}
+	if gap := times[1].Sub(times[0]); gap > 30*time.Millisecond {
+		t.Errorf("Expected untimed line right away, got %v", gap)
+	}
+	checkGap("first to second", times[2].Sub(times[0]), 100*time.Millisecond)
+	checkGap("second to third", times[3].Sub(times[2]), 50*time.Millisecond)
+}
+
+func TestReplaySource_CloseDuringWait(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "captured.log")
+	content := "2024-01-01T00:00:00Z first\n2024-01-01T01:00:00Z an hour later\n"
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewReplaySource("replay", logPath, ReplayOptions{})
+	reader, err := src.Stream()
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	scanner := bufio.NewScanner(reader)
+	if !scanner.Scan() {
+		t.Fatal("Expected the first line")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		src.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not interrupt the wait for the next line")
+	}
+	if scanner.Scan() {
+		t.Errorf("Expected no more lines after Close, got %q", scanner.Text())
+	}
+}
+
+func TestReplaySource_MissingFile(t *testing.T) {
+	src := NewReplaySource("replay", filepath.Join(t.TempDir(), "missing.log"), ReplayOptions{})
+	if _, err := src.Stream(); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
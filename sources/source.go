@@ -13,3 +13,19 @@ type LogSource interface {
 	// Name returns the name of the source (e.g. for logging).
 	Name() string
 }
+
+// ContextSource is implemented by sources that can reread the lines they
+// streamed, such as files, so events can carry the lines around a match.
+type ContextSource interface {
+	// LinesAround returns line, which starts at offset in what the last
+	// Stream returned, with up to n lines before and after it, or nil if
+	// line is no longer there.
+	LinesAround(offset int64, line []byte, n int) ([]byte, error)
+}
+
+// Bounds for the read_buffer_size option (FileOptions and SyslogOptions).
+const (
+	DefaultReadBufferSize = 32 * 1024
+	MinReadBufferSize     = 4 * 1024
+	MaxReadBufferSize     = 16 * 1024 * 1024
+)
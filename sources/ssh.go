@@ -0,0 +1,68 @@
+package sources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sshCommand is the ssh client binary; tests replace it with a fake.
+var sshCommand = "ssh"
+
+// SSHOptions configures how SSHSource connects to the remote host.
+type SSHOptions struct {
+	KeyFile        string // private key (ssh -i); empty uses the ssh client defaults
+	KnownHostsFile string // known_hosts file; empty uses the ssh client defaults
+	Port           int    // 0 uses the ssh client default
+}
+
+// SSHSource tails a file on a remote host by running `tail -F` over the system ssh client.
+// When the connection drops the stream ends, and the monitor restarts it (reconnecting).
+type SSHSource struct {
+	*CommandSource
+}
+
+// ParseSSHTarget splits a "[user@]host:/path" spec into the ssh destination and remote path.
+func ParseSSHTarget(spec string) (string, string, error) {
+	host, path, ok := strings.Cut(spec, ":")
+	if !ok || host == "" || path == "" || strings.HasSuffix(host, "@") {
+		return "", "", fmt.Errorf("invalid ssh target %q, expected [user@]host:/path", spec)
+	}
+	return host, path, nil
+}
+
+func NewSSHSource(name string, spec string, opts SSHOptions) (*SSHSource, error) {
+	host, path, err := ParseSSHTarget(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &SSHSource{
+		CommandSource: NewCommandSource(name, sshCommand, sshArgs(host, path, opts)...),
+	}, nil
+}
+
+func sshArgs(host, path string, opts SSHOptions) []string {
+	args := []string{
+		// Never prompt: a monitor has no terminal to answer on
+		"-o", "BatchMode=yes",
+		// Detect dead connections so the stream ends and is restarted
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=3",
+	}
+	if opts.KeyFile != "" {
+		args = append(args, "-i", opts.KeyFile)
+	}
+	if opts.KnownHostsFile != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+opts.KnownHostsFile, "-o", "StrictHostKeyChecking=yes")
+	}
+	if opts.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(opts.Port))
+	}
+	// Like FileSource, only report lines written after we connect
+	return append(args, host, "tail", "-F", "-n", "0", "--", shellQuote(path))
+}
+
+// shellQuote quotes s for the remote shell that ssh runs the command in.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,94 @@
+package sources
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSSHTarget(t *testing.T) {
+	tests := []struct {
+		spec     string
+		wantHost string
+		wantPath string
+		wantErr  bool
+	}{
+		{"user@host:/var/log/syslog", "user@host", "/var/log/syslog", false},
+		{"host:/var/log/app.log", "host", "/var/log/app.log", false},
+		{"host", "", "", true},
+		{"host:", "", "", true},
+		{":/var/log/syslog", "", "", true},
+		{"user@:/var/log/syslog", "", "", true},
+	}
+
+	for _, tt := range tests {
+		host, path, err := ParseSSHTarget(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSSHTarget(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if host != tt.wantHost || path != tt.wantPath {
+			t.Errorf("ParseSSHTarget(%q) = %q, %q, want %q, %q", tt.spec, host, path, tt.wantHost, tt.wantPath)
+		}
+	}
+}
+
+func TestSSHArgs(t *testing.T) {
+	got := sshArgs("user@host", "/var/log/it's.log", SSHOptions{
+		KeyFile:        "/etc/sentrylogmon/id_ed25519",
+		KnownHostsFile: "/etc/sentrylogmon/known_hosts",
+		Port:           2222,
+	})
+	want := []string{
+		"-o", "BatchMode=yes",
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=3",
+		"-i", "/etc/sentrylogmon/id_ed25519",
+		"-o", "UserKnownHostsFile=/etc/sentrylogmon/known_hosts", "-o", "StrictHostKeyChecking=yes",
+		"-p", "2222",
+		"user@host", "tail", "-F", "-n", "0", "--", `'/var/log/it'\''s.log'`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sshArgs() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSSHSourceStream(t *testing.T) {
+	// Fake ssh client that prints the remote command it was asked to run, then some log lines
+	fake := filepath.Join(t.TempDir(), "ssh")
+	script := "#!/bin/sh\n" +
+		"for last; do :; done\n" +
+		"echo \"path=$last\"\n" +
+		"echo 'line 1'\n" +
+		"echo 'line 2'\n" +
+		"sleep 1\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	orig := sshCommand
+	sshCommand = fake
+	defer func() { sshCommand = orig }()
+
+	src, err := NewSSHSource("remote", "user@host:/var/log/syslog", SSHOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	stream, err := src.Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(stream)
+	for _, want := range []string{"path='/var/log/syslog'", "line 1", "line 2"} {
+		if !scanner.Scan() {
+			t.Fatalf("Stream ended early, expected %q (err: %v)", want, scanner.Err())
+		}
+		if got := scanner.Text(); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
@@ -6,61 +6,120 @@ import (
 	"io"
 	"log"
 	"net"
+	"net/netip"
+	"strconv"
 	"strings"
 	"sync"
 )
 
+// SyslogOptions configures a SyslogSource.
+type SyslogOptions struct {
+	// ReadBufferSize sets the socket receive buffer (SO_RCVBUF), so bursts are
+	// not dropped by the kernel before they are read. 0 keeps the OS default.
+	ReadBufferSize int
+}
+
+// syslogAddr is one address a SyslogSource listens on.
+type syslogAddr struct {
+	network string // udp or tcp
+	address string
+	err     error // from ParseSyslogAddress, returned by Stream
+}
+
+// SyslogSource receives syslog lines on one or more UDP and TCP addresses,
+// all feeding the same stream.
 type SyslogSource struct {
 	name      string
-	network   string
-	address   string
-	listener  io.Closer
+	addrs     []syslogAddr
+	bufSize   int
+	listeners []io.Closer
 	reader    *io.PipeReader
 	writer    *io.PipeWriter
 	wg        sync.WaitGroup
 	closeChan chan struct{}
 }
 
-func NewSyslogSource(name string, address string) *SyslogSource {
-	// Parse network from address if present (e.g. "tcp:0.0.0.0:514")
-	network := "udp"
-	addr := address
-	if strings.Contains(address, ":") {
-		// Detect if it starts with tcp: or udp:
-		if strings.HasPrefix(address, "tcp:") {
-			network = "tcp"
-			addr = strings.TrimPrefix(address, "tcp:")
-		} else if strings.HasPrefix(address, "udp:") {
-			network = "udp"
-			addr = strings.TrimPrefix(address, "udp:")
-		}
-	}
+func NewSyslogSource(name string, address string, opts SyslogOptions) *SyslogSource {
+	return NewMultiSyslogSource(name, []string{address}, opts)
+}
 
-	return &SyslogSource{
+// NewMultiSyslogSource returns a source listening on every address, e.g.
+// udp:0.0.0.0:514 and tcp:0.0.0.0:514, as one stream.
+func NewMultiSyslogSource(name string, addresses []string, opts SyslogOptions) *SyslogSource {
+	s := &SyslogSource{
 		name:      name,
-		network:   network,
-		address:   addr,
+		bufSize:   opts.ReadBufferSize,
 		closeChan: make(chan struct{}),
 	}
+	for _, address := range addresses {
+		network, addr, err := ParseSyslogAddress(address)
+		s.addrs = append(s.addrs, syslogAddr{network: network, address: addr, err: err})
+	}
+	return s
+}
+
+// ParseSyslogAddress splits a syslog listen address into its network, udp
+// (the default) or tcp, and host:port, and checks the host:port. The network
+// is given as a "udp:"/"tcp:" or "udp://"/"tcp://" prefix. IPv6 addresses
+// go in brackets and may name a zone, e.g. "udp:[fe80::1%eth0]:514" to bind
+// a link-local address on one interface. The host may be empty (":5514"
+// listens on all addresses).
+func ParseSyslogAddress(address string) (network, addr string, err error) {
+	network, addr = "udp", address
+	for _, n := range []string{"udp", "tcp"} {
+		if rest, ok := strings.CutPrefix(address, n+"://"); ok {
+			network, addr = n, rest
+			break
+		}
+		if rest, ok := strings.CutPrefix(address, n+":"); ok {
+			network, addr = n, rest
+			break
+		}
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		if strings.Count(addr, ":") > 1 && !strings.HasPrefix(addr, "[") {
+			return network, addr, fmt.Errorf("invalid syslog address %q: put IPv6 addresses in brackets, e.g. %s:[fe80::1%%eth0]:514", address, network)
+		}
+		return network, addr, fmt.Errorf("invalid syslog address %q: %v (use host:port, e.g. %s:0.0.0.0:514 or :5514)", address, err, network)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return network, addr, fmt.Errorf("invalid syslog address %q: port %q is not a number from 0 to 65535", address, port)
+	}
+	if strings.Contains(host, "%") {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return network, addr, fmt.Errorf("invalid syslog address %q: a %%zone is only valid after an IPv6 address: %v", address, err)
+		}
+	}
+	return network, addr, nil
 }
 
 func (s *SyslogSource) Name() string {
 	return s.name
 }
 
+// Addr returns the address of the first listener, or nil if not listening.
 func (s *SyslogSource) Addr() net.Addr {
-	if s.listener == nil {
-		return nil
-	}
-	if l, ok := s.listener.(net.Listener); ok {
-		return l.Addr()
-	}
-	if c, ok := s.listener.(net.PacketConn); ok {
-		return c.LocalAddr()
+	if addrs := s.Addrs(); len(addrs) > 0 {
+		return addrs[0]
 	}
 	return nil
 }
 
+// Addrs returns the addresses of all listeners, in the order they were configured.
+func (s *SyslogSource) Addrs() []net.Addr {
+	var addrs []net.Addr
+	for _, listener := range s.listeners {
+		if l, ok := listener.(net.Listener); ok {
+			addrs = append(addrs, l.Addr())
+		} else if c, ok := listener.(net.PacketConn); ok {
+			addrs = append(addrs, c.LocalAddr())
+		}
+	}
+	return addrs
+}
+
 func (s *SyslogSource) Close() error {
 	select {
 	case <-s.closeChan:
@@ -69,15 +128,11 @@ func (s *SyslogSource) Close() error {
 		close(s.closeChan)
 	}
 
-	if s.listener != nil {
-		s.listener.Close()
+	// Closing the listeners ends their loops; the writer is closed once all of them have exited.
+	for _, listener := range s.listeners {
+		listener.Close()
 	}
 
-	// We don't close writer here immediately, we let the goroutine do it when listener closes/fails
-	// to ensure we drain or finish properly?
-	// Actually, if we close listener, the loop exits, then it defers pw.Close().
-	// So we just wait.
-
 	s.wg.Wait()
 	return nil
 }
@@ -87,37 +142,53 @@ func (s *SyslogSource) Stream() (io.Reader, error) {
 	s.reader = pr
 	s.writer = pw
 
-	var err error
-	if s.network == "tcp" {
-		err = s.startTCP(pw)
-	} else {
-		err = s.startUDP(pw)
+	// The pipe is shared, so it is only closed after every listener loop has exited
+	var loops sync.WaitGroup
+	for _, a := range s.addrs {
+		err := a.err
+		if err == nil && a.network == "tcp" {
+			err = s.startTCP(a.address, pw, &loops)
+		} else if err == nil {
+			err = s.startUDP(a.address, pw, &loops)
+		}
+		if err != nil {
+			s.Close()
+			loops.Wait()
+			pw.Close()
+			return nil, err
+		}
 	}
 
-	if err != nil {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		loops.Wait()
 		pw.Close()
-		return nil, err
-	}
+	}()
 
 	return pr, nil
 }
 
-func (s *SyslogSource) startUDP(pw *io.PipeWriter) error {
-	addr, err := net.ResolveUDPAddr("udp", s.address)
+func (s *SyslogSource) startUDP(address string, pw *io.PipeWriter, loops *sync.WaitGroup) error {
+	addr, err := net.ResolveUDPAddr("udp", address)
 	if err != nil {
-		return fmt.Errorf("failed to resolve UDP address %s: %v", s.address, err)
+		return fmt.Errorf("failed to resolve UDP address %s: %v", address, err)
 	}
 
 	conn, err := net.ListenUDP("udp", addr)
 	if err != nil {
-		return fmt.Errorf("failed to listen on UDP %s: %v", s.address, err)
+		return fmt.Errorf("failed to listen on UDP %s: %v", address, err)
+	}
+	s.listeners = append(s.listeners, conn)
+	if s.bufSize > 0 {
+		if err := conn.SetReadBuffer(s.bufSize); err != nil {
+			log.Printf("Failed to set UDP read buffer to %d bytes: %v", s.bufSize, err)
+		}
 	}
-	s.listener = conn
 
-	s.wg.Add(1)
+	loops.Add(1)
 	go func() {
-		defer s.wg.Done()
-		defer pw.Close()
+		defer loops.Done()
 
 		buf := make([]byte, 65536) // Max UDP size
 		for {
@@ -157,22 +228,21 @@ func (s *SyslogSource) startUDP(pw *io.PipeWriter) error {
 	return nil
 }
 
-func (s *SyslogSource) startTCP(pw *io.PipeWriter) error {
-	addr, err := net.ResolveTCPAddr("tcp", s.address)
+func (s *SyslogSource) startTCP(address string, pw *io.PipeWriter, loops *sync.WaitGroup) error {
+	addr, err := net.ResolveTCPAddr("tcp", address)
 	if err != nil {
-		return fmt.Errorf("failed to resolve TCP address %s: %v", s.address, err)
+		return fmt.Errorf("failed to resolve TCP address %s: %v", address, err)
 	}
 
 	ln, err := net.ListenTCP("tcp", addr)
 	if err != nil {
-		return fmt.Errorf("failed to listen on TCP %s: %v", s.address, err)
+		return fmt.Errorf("failed to listen on TCP %s: %v", address, err)
 	}
-	s.listener = ln
+	s.listeners = append(s.listeners, ln)
 
-	s.wg.Add(1)
+	loops.Add(1)
 	go func() {
-		defer s.wg.Done()
-		defer pw.Close()
+		defer loops.Done()
 
 		for {
 			conn, err := ln.AcceptTCP()
@@ -188,6 +258,12 @@ func (s *SyslogSource) startTCP(pw *io.PipeWriter) error {
 				}
 			}
 
+			if s.bufSize > 0 {
+				if err := conn.SetReadBuffer(s.bufSize); err != nil {
+					log.Printf("Failed to set TCP read buffer to %d bytes: %v", s.bufSize, err)
+				}
+			}
+
 			s.wg.Add(1)
 			go func(c *net.TCPConn) {
 				defer s.wg.Done()
@@ -10,7 +10,7 @@ import (
 
 func TestSyslogSource_UDP(t *testing.T) {
 	// Use port 0 to let OS pick one
-	source := NewSyslogSource("test_udp", "udp:127.0.0.1:0")
+	source := NewSyslogSource("test_udp", "udp:127.0.0.1:0", SyslogOptions{})
 	reader, err := source.Stream()
 	if err != nil {
 		t.Fatalf("Failed to stream: %v", err)
@@ -68,7 +68,7 @@ func TestSyslogSource_UDP(t *testing.T) {
 }
 
 func TestSyslogSource_TCP(t *testing.T) {
-	source := NewSyslogSource("test_tcp", "tcp:127.0.0.1:0")
+	source := NewSyslogSource("test_tcp", "tcp:127.0.0.1:0", SyslogOptions{})
 	reader, err := source.Stream()
 	if err != nil {
 		t.Fatalf("Failed to stream: %v", err)
@@ -125,7 +125,7 @@ func TestSyslogSource_TCP(t *testing.T) {
 }
 
 func TestSyslogSource_Close(t *testing.T) {
-	source := NewSyslogSource("test_close", "udp:127.0.0.1:0")
+	source := NewSyslogSource("test_close", "udp:127.0.0.1:0", SyslogOptions{})
 	reader, err := source.Stream()
 	if err != nil {
 		t.Fatalf("Failed to stream: %v", err)
@@ -150,3 +150,134 @@ func TestSyslogSource_Close(t *testing.T) {
 	// If channel is closed, the goroutines will exit immediately.
 	// So SyslogSource is not reusable after Close.
 }
+
+func TestSyslogSource_MultipleAddresses(t *testing.T) {
+	source := NewMultiSyslogSource("test_multi", []string{"udp:127.0.0.1:0", "tcp:127.0.0.1:0"}, SyslogOptions{})
+	reader, err := source.Stream()
+	if err != nil {
+		t.Fatalf("Failed to stream: %v", err)
+	}
+	defer source.Close()
+
+	addrs := source.Addrs()
+	if len(addrs) != 2 {
+		t.Fatalf("Expected 2 listener addresses, got %d", len(addrs))
+	}
+
+	udpConn, err := net.Dial("udp", addrs[0].String())
+	if err != nil {
+		t.Fatalf("Failed to dial UDP: %v", err)
+	}
+	defer udpConn.Close()
+	tcpConn, err := net.Dial("tcp", addrs[1].String())
+	if err != nil {
+		t.Fatalf("Failed to dial TCP: %v", err)
+	}
+	defer tcpConn.Close()
+
+	if _, err := fmt.Fprint(udpConn, "from udp"); err != nil {
+		t.Fatalf("Failed to write to UDP: %v", err)
+	}
+	if _, err := fmt.Fprintf(tcpConn, "from tcp\n"); err != nil {
+		t.Fatalf("Failed to write to TCP: %v", err)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	got := make(map[string]bool)
+	for len(got) < 2 {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("Stream closed before both lines arrived")
+			}
+			got[line] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for lines, got %v", got)
+		}
+	}
+	if !got["from udp"] || !got["from tcp"] {
+		t.Errorf("Expected a line from each listener, got %v", got)
+	}
+}
+
+func TestParseSyslogAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		addr    string
+		wantErr bool
+	}{
+		{":5514", "udp", ":5514", false},
+		{"127.0.0.1:5514", "udp", "127.0.0.1:5514", false},
+		{"tcp:0.0.0.0:514", "tcp", "0.0.0.0:514", false},
+		{"tcp://0.0.0.0:6514", "tcp", "0.0.0.0:6514", false},
+		{"udp://:514", "udp", ":514", false},
+		{"udp:[::1]:5514", "udp", "[::1]:5514", false},
+		{"[::]:514", "udp", "[::]:514", false},
+		{"udp:[fe80::1%eth0]:514", "udp", "[fe80::1%eth0]:514", false},
+		{"tcp:[fe80::1%2]:514", "tcp", "[fe80::1%2]:514", false},
+		{"udp:fe80::1%eth0:514", "", "", true}, // IPv6 without brackets
+		{"udp:0.0.0.0", "", "", true},          // no port
+		{"tcp:0.0.0.0:syslog", "", "", true},   // port is not a number
+		{"udp:0.0.0.0:70000", "", "", true},
+		{"udp:[127.0.0.1%eth0]:514", "", "", true}, // zone on an IPv4 address
+		{"", "", "", true},
+	}
+	for _, tt := range tests {
+		network, addr, err := ParseSyslogAddress(tt.address)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseSyslogAddress(%q) = %s %s, expected an error", tt.address, network, addr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSyslogAddress(%q) failed: %v", tt.address, err)
+			continue
+		}
+		if network != tt.network || addr != tt.addr {
+			t.Errorf("ParseSyslogAddress(%q) = %s %s, expected %s %s", tt.address, network, addr, tt.network, tt.addr)
+		}
+	}
+}
+
+func TestSyslogSource_IPv6Zone(t *testing.T) {
+	// A zone names the interface a link-local address is bound on; check it
+	// reaches the resolver
+	_, addr, err := ParseSyslogAddress("udp:[fe80::1%eth0]:514")
+	if err != nil {
+		t.Fatalf("ParseSyslogAddress failed: %v", err)
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		t.Fatalf("ResolveUDPAddr(%q) failed: %v", addr, err)
+	}
+	if udpAddr.Zone != "eth0" || udpAddr.Port != 514 {
+		t.Errorf("Expected zone eth0 and port 514, got %+v", udpAddr)
+	}
+
+	source := NewSyslogSource("test_ipv6", "tcp:[::1]:0", SyslogOptions{})
+	if _, err := source.Stream(); err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	defer source.Close()
+	if a, ok := source.Addr().(*net.TCPAddr); !ok || !a.IP.Equal(net.IPv6loopback) {
+		t.Errorf("Expected to listen on [::1], got %v", source.Addr())
+	}
+}
+
+func TestSyslogSource_InvalidAddress(t *testing.T) {
+	source := NewSyslogSource("test_invalid", "udp:fe80::1:514", SyslogOptions{})
+	if _, err := source.Stream(); err == nil {
+		source.Close()
+		t.Fatal("Expected an error for an unbracketed IPv6 address")
+	}
+}
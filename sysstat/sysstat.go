@@ -2,7 +2,10 @@ package sysstat
 
 import (
 	"fmt"
+	"log"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -46,19 +49,77 @@ type SystemState struct {
 	TopCPU         []ProcessInfo          `json:"top_cpu"`
 	TopMem         []ProcessInfo          `json:"top_mem"`
 	ProcessSummary string                 `json:"process_summary"`
+	Zombies        int                    `json:"zombies"`
 }
 
+// processCounts aggregates processes by their state in /proc/<pid>/stat.
+type processCounts struct {
+	Total   int
+	Threads int
+	States  map[string]int // keyed by state letter, e.g. "R", "S", "Z"
+}
+
+// summaryStates are the states listed in the process summary, always shown
+// even when zero so a spike of e.g. zombies stands out against a baseline.
+var summaryStates = []string{"R", "S", "Z", "D", "T"}
+
+// Summary formats the counts, e.g. "Total: 412 (R:3 S:400 Z:9 D:0 T:0), Threads: 980".
+func (pc processCounts) Summary() string {
+	parts := make([]string, len(summaryStates))
+	for i, st := range summaryStates {
+		parts[i] = fmt.Sprintf("%s:%d", st, pc.States[st])
+	}
+	return fmt.Sprintf("Total: %d (%s), Threads: %d", pc.Total, strings.Join(parts, " "), pc.Threads)
+}
+
+// DefaultStartupJitter bounds the random delay before the first collection, so a fleet
+// of instances started at the same time does not collect in lockstep.
+const DefaultStartupJitter = 5 * time.Second
+
 type Collector struct {
 	mu    sync.RWMutex
 	state *SystemState
+
+	// startupJitter bounds the random delay before the first collection in Run.
+	startupJitter time.Duration
+	rng           *rand.Rand
+
+	// procRoot is the procfs mount point. Process and pressure stats are only
+	// collected if it is available, which is checked once on first collection.
+	procRoot      string
+	procOnce      sync.Once
+	procAvailable bool
 }
 
 func New() *Collector {
+	return newCollector("/proc")
+}
+
+func newCollector(procRoot string) *Collector {
 	return &Collector{
-		state: &SystemState{},
+		state:         &SystemState{},
+		procRoot:      procRoot,
+		startupJitter: DefaultStartupJitter,
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
+// hasProc reports whether procfs is usable. On macOS, Windows or restricted
+// containers it is not, and only the cross-platform load/memory stats are collected.
+func (c *Collector) hasProc() bool {
+	c.procOnce.Do(func() {
+		if runtime.GOOS != "linux" {
+			return
+		}
+		if _, err := os.Stat(filepath.Join(c.procRoot, "stat")); err != nil {
+			log.Printf("sysstat: %s not available, skipping process stats: %v", c.procRoot, err)
+			return
+		}
+		c.procAvailable = true
+	})
+	return c.procAvailable
+}
+
 // ToMap converts the SystemState to a map[string]interface{}.
 // This is optimized to avoid double JSON marshaling (struct -> json -> map -> json)
 // when sending context to Sentry.
@@ -74,6 +135,7 @@ func (s *SystemState) ToMap() map[string]interface{} {
 		"top_cpu":         s.TopCPU,
 		"top_mem":         s.TopMem,
 		"process_summary": s.ProcessSummary,
+		"zombies":         s.Zombies,
 	}
 	if s.DiskPressure != nil {
 		m["disk_pressure"] = s.DiskPressure
@@ -87,8 +149,17 @@ func (c *Collector) GetState() *SystemState {
 	return c.state
 }
 
+// jitter returns a random delay in [0, startupJitter).
+func (c *Collector) jitter() time.Duration {
+	if c.startupJitter <= 0 {
+		return 0
+	}
+	return time.Duration(c.rng.Int63n(int64(c.startupJitter)))
+}
+
 func (c *Collector) Run() {
-	// Initial collection
+	// Initial collection, after a random delay to avoid a thundering herd on fleet restarts
+	time.Sleep(c.jitter())
 	c.collect()
 
 	for {
@@ -122,11 +193,25 @@ func (c *Collector) collect() {
 	if m, err := mem.VirtualMemory(); err == nil {
 		newState.Memory = m
 	}
-	newState.DiskPressure = getDiskPressure()
 
-	procs, summary, err := getProcessStats(newState.Uptime, newState.Memory.Total)
+	if !c.hasProc() {
+		newState.ProcessSummary = "Process stats unavailable"
+		c.mu.Lock()
+		c.state = newState
+		c.mu.Unlock()
+		return
+	}
+
+	newState.DiskPressure = getDiskPressure(c.procRoot)
+
+	var totalMem uint64
+	if newState.Memory != nil {
+		totalMem = newState.Memory.Total
+	}
+	procs, counts, err := getProcessStats(c.procRoot, newState.Uptime, totalMem)
 	if err == nil {
-		newState.ProcessSummary = summary
+		newState.ProcessSummary = counts.Summary()
+		newState.Zombies = counts.States["Z"]
 
 		// Get Top CPU
 		newState.TopCPU = getTopKProcesses(procs, 5, func(i, j ProcessInfo) bool {
@@ -152,8 +237,8 @@ func (c *Collector) collect() {
 	c.mu.Unlock()
 }
 
-func getDiskPressure() *PressureInfo {
-	content, err := os.ReadFile("/proc/pressure/io")
+func getDiskPressure(procRoot string) *PressureInfo {
+	content, err := os.ReadFile(filepath.Join(procRoot, "pressure", "io"))
 	if err != nil {
 		return nil
 	}
@@ -203,16 +288,18 @@ func fetchCommand(p *ProcessInfo) {
 	p.Command = SanitizeCommand(cmd)
 }
 
-func getProcessStats(uptime uint64, totalMem uint64) ([]ProcessInfo, string, error) {
-	fs, err := procfs.NewFS("/proc")
+func getProcessStats(procRoot string, uptime uint64, totalMem uint64) ([]ProcessInfo, processCounts, error) {
+	counts := processCounts{States: make(map[string]int)}
+	fs, err := procfs.NewFS(procRoot)
 	if err != nil {
-		return nil, "", err
+		return nil, counts, err
 	}
 
 	procs, err := fs.AllProcs()
 	if err != nil {
-		return nil, "", err
+		return nil, counts, err
 	}
+	counts.Total = len(procs)
 
 	var results []ProcessInfo
 	pageSize := os.Getpagesize()
@@ -226,6 +313,8 @@ func getProcessStats(uptime uint64, totalMem uint64) ([]ProcessInfo, string, err
 		if err != nil {
 			continue
 		}
+		counts.States[stat.State]++
+		counts.Threads += stat.NumThreads
 
 		// CPU Usage: (utime + stime) / (uptime - starttime)
 		// Times are in jiffies.
@@ -259,8 +348,7 @@ func getProcessStats(uptime uint64, totalMem uint64) ([]ProcessInfo, string, err
 		})
 	}
 
-	summary := fmt.Sprintf("Total Processes: %d", len(procs))
-	return results, summary, nil
+	return results, counts, nil
 }
 
 func getTopKProcesses(procs []ProcessInfo, k int, more func(i, j ProcessInfo) bool) []ProcessInfo {
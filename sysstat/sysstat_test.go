@@ -1,6 +1,10 @@
 package sysstat
 
 import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -41,3 +45,122 @@ func TestCollect(t *testing.T) {
 		t.Error("Timestamp is too old")
 	}
 }
+
+func TestCollectWithoutProc(t *testing.T) {
+	c := newCollector(filepath.Join(t.TempDir(), "proc"))
+	c.collect()
+	// Second collection must not retry the /proc check
+	c.collect()
+
+	state := c.GetState()
+	if state.Load == nil {
+		t.Error("Load should still be collected without /proc")
+	}
+	if state.Memory == nil {
+		t.Error("Memory should still be collected without /proc")
+	}
+	if len(state.TopCPU) != 0 || len(state.TopMem) != 0 {
+		t.Errorf("Expected empty process lists, got %d/%d", len(state.TopCPU), len(state.TopMem))
+	}
+	if state.DiskPressure != nil {
+		t.Error("DiskPressure should be nil without /proc")
+	}
+	if state.ProcessSummary != "Process stats unavailable" {
+		t.Errorf("Unexpected process summary: %q", state.ProcessSummary)
+	}
+}
+
+func TestRunStartupJitter(t *testing.T) {
+	const maxJitter = 300 * time.Millisecond
+
+	c := New()
+	c.startupJitter = maxJitter
+	c.rng = rand.New(rand.NewSource(42))
+
+	// The same seed yields the same delay
+	expected := (&Collector{startupJitter: maxJitter, rng: rand.New(rand.NewSource(42))}).jitter()
+	if expected < 0 || expected >= maxJitter {
+		t.Fatalf("Jitter %v outside [0, %v)", expected, maxJitter)
+	}
+
+	start := time.Now()
+	go c.Run()
+
+	deadline := time.After(maxJitter + time.Second)
+	for c.GetState().Timestamp.IsZero() {
+		select {
+		case <-deadline:
+			t.Fatal("First collection did not happen within the jitter window")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	elapsed := c.GetState().Timestamp.Sub(start)
+	if elapsed < expected {
+		t.Errorf("First collection after %v, expected no earlier than %v", elapsed, expected)
+	}
+	if elapsed > maxJitter+200*time.Millisecond {
+		t.Errorf("First collection after %v, expected within %v", elapsed, maxJitter)
+	}
+}
+
+func TestJitterDisabled(t *testing.T) {
+	c := New()
+	c.startupJitter = 0
+	if d := c.jitter(); d != 0 {
+		t.Errorf("Expected no jitter, got %v", d)
+	}
+}
+
+// writeProcStat writes a synthetic /proc/<pid>/stat with the given state and thread count.
+func writeProcStat(t *testing.T, procRoot string, pid int, state string, threads int) {
+	t.Helper()
+	dir := filepath.Join(procRoot, fmt.Sprint(pid))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stat := fmt.Sprintf("%d (proc%d) %s 1 %d %d 0 -1 4194304 81 0 0 0 10 5 0 0 20 0 %d 0 82242 2703360 285 "+
+		"18446744073709551615 1 1 1 0 0 0 0 0 0 0 0 0 17 0 0 0 0 0 0 1 1 1 1 1 1 1 0\n", pid, pid, state, pid, pid, threads)
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetProcessStatsStateCounts(t *testing.T) {
+	procRoot := t.TempDir()
+	procs := []struct {
+		state   string
+		threads int
+	}{
+		{"R", 4},
+		{"S", 1},
+		{"S", 2},
+		{"S", 1},
+		{"Z", 1},
+		{"Z", 1},
+		{"D", 3},
+	}
+	for i, p := range procs {
+		writeProcStat(t, procRoot, 100+i, p.state, p.threads)
+	}
+
+	results, counts, err := getProcessStats(procRoot, 1000, 1<<30)
+	if err != nil {
+		t.Fatalf("getProcessStats failed: %v", err)
+	}
+	if len(results) != len(procs) {
+		t.Errorf("Expected %d processes, got %d", len(procs), len(results))
+	}
+	if counts.Total != 7 || counts.Threads != 13 {
+		t.Errorf("Expected 7 processes and 13 threads, got %d and %d", counts.Total, counts.Threads)
+	}
+	want := map[string]int{"R": 1, "S": 3, "Z": 2, "D": 1}
+	for state, n := range want {
+		if counts.States[state] != n {
+			t.Errorf("Expected %d processes in state %s, got %d", n, state, counts.States[state])
+		}
+	}
+	if got, want := counts.Summary(), "Total: 7 (R:1 S:3 Z:2 D:1 T:0), Threads: 13"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
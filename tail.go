@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/angch/sentrylogmon/ipc"
+	"github.com/angch/sentrylogmon/monitor"
+)
+
+// How often --tail asks the running instances for new events
+const tailInterval = 1 * time.Second
+
+// recentEvents merges the recent events of all monitors, oldest first, for
+// the IPC server's /events/recent.
+func recentEvents(monitors []*monitor.Monitor) []ipc.RecentEvent {
+	var events []ipc.RecentEvent
+	for _, m := range monitors {
+		for _, ev := range m.RecentEvents() {
+			events = append(events, ipc.RecentEvent{
+				Seq:     ev.Seq,
+				Time:    ev.Time,
+				Source:  ev.Source,
+				Level:   ev.Level,
+				Message: ev.Message,
+				Lines:   ev.Lines,
+			})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+	return events
+}
+
+// tailInstances prints the recent events of the running instances in
+// socketDir to w, then keeps printing new ones until ctx is done.
+func tailInstances(ctx context.Context, socketDir string, w io.Writer, interval time.Duration) error {
+	type seen struct {
+		started time.Time
+		after   uint64
+	}
+	last := make(map[int]seen)
+	for {
+		instances, err := ipc.ListInstances(socketDir)
+		if err != nil {
+			return err
+		}
+		for _, inst := range instances {
+			s := last[inst.PID]
+			if !s.started.Equal(inst.StartTime) {
+				// A new or restarted instance numbers its events from 1 again
+				s = seen{started: inst.StartTime}
+			}
+			events, err := ipc.RecentEvents(ipc.SocketPath(socketDir, inst.PID), s.after)
+			if err != nil {
+				continue
+			}
+			for _, ev := range events {
+				printRecentEvent(w, inst.PID, ev)
+				s.after = ev.Seq
+			}
+			last[inst.PID] = s
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+func printRecentEvent(w io.Writer, pid int, ev ipc.RecentEvent) {
+	more := ""
+	if ev.Lines > 1 {
+		more = fmt.Sprintf(" (+%d lines)", ev.Lines-1)
+	}
+	fmt.Fprintf(w, "%s [%d] %s %s: %s%s\n", ev.Time.Format("2006-01-02 15:04:05"), pid, ev.Source, ev.Level, ev.Message, more)
+}
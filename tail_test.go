@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/detectors"
+	"github.com/angch/sentrylogmon/ipc"
+	"github.com/angch/sentrylogmon/monitor"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestRecentEventsOverIPC(t *testing.T) {
+	prev := sentry.CurrentHub().Client()
+	t.Cleanup(func() { sentry.CurrentHub().BindClient(prev) })
+	if err := sentry.Init(sentry.ClientOptions{Transport: &recordingTransport{}}); err != nil {
+		t.Fatalf("Failed to init sentry: %v", err)
+	}
+
+	// Lines 10s apart are reported as separate events.
+	input := `[100.0] error: disk full password=hunter2
+[110.0] error: link down
+[111.0] error: link still down
+`
+	det, err := detectors.NewGenericDetector("error")
+	if err != nil {
+		t.Fatalf("NewGenericDetector failed: %v", err)
+	}
+	mon, err := monitor.New(context.Background(), &stringSource{content: input}, det, nil, monitor.Options{
+		Redact: []string{`password=\S+`},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	mon.StopOnEOF = true
+	mon.Start()
+	sentry.Flush(time.Second)
+
+	socketDir := t.TempDir()
+	socketPath := ipc.SocketPath(socketDir, os.Getpid())
+	go func() {
+		_ = ipc.StartServer(socketPath, func() *config.Config { return &config.Config{} }, nil, nil, func() []ipc.RecentEvent {
+			return recentEvents([]*monitor.Monitor{mon})
+		})
+	}()
+	if !waitFor(2*time.Second, func() bool {
+		_, err := os.Stat(socketPath)
+		return err == nil
+	}) {
+		t.Fatal("IPC socket was not created")
+	}
+
+	events, err := ipc.RecentEvents(socketPath, 0)
+	if err != nil {
+		t.Fatalf("RecentEvents failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 recent events, got %+v", events)
+	}
+	if events[0].Message != "[100.0] error: disk full [REDACTED]" || events[0].Source != "sample" || events[0].Level != "info" {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[1].Message != "[110.0] error: link down" || events[1].Lines != 2 {
+		t.Errorf("Unexpected second event: %+v", events[1])
+	}
+
+	// Only events after the last one seen are returned
+	newer, err := ipc.RecentEvents(socketPath, events[0].Seq)
+	if err != nil {
+		t.Fatalf("RecentEvents failed: %v", err)
+	}
+	if len(newer) != 1 || newer[0].Seq != events[1].Seq {
+		t.Errorf("Expected only the second event after seq %d, got %+v", events[0].Seq, newer)
+	}
+
+	var out bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := tailInstances(ctx, socketDir, &out, time.Millisecond); err != nil {
+		t.Fatalf("tailInstances failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], "sample info: [110.0] error: link down (+1 lines)") {
+		t.Errorf("Unexpected --tail output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+var (
+	// Delay before restarting a failed server, doubled on each failure up to serverRetryMax
+	serverRetryMin = 1 * time.Second
+	serverRetryMax = 5 * time.Minute
+	// A server that ran this long before failing is considered to have been healthy,
+	// so the next failure is reported again and retried without delay build-up
+	serverHealthyAfter = 1 * time.Minute
+)
+
+// superviseServer runs serve, e.g. the metrics or IPC server, and restarts it
+// with backoff whenever it returns until ctx is done. The first failure of an
+// outage is reported to hub as a warning tagged alert_type=server_down.
+func superviseServer(ctx context.Context, name string, serve func() error, hub *sentry.Hub) {
+	backoff := serverRetryMin
+	down := false
+	for {
+		start := time.Now()
+		err := serve()
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) >= serverHealthyAfter {
+			backoff = serverRetryMin
+			down = false
+		}
+
+		log.Printf("%s server stopped: %v; restarting in %v", name, err, backoff)
+		if !down {
+			down = true
+			sendServerDown(hub, name, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, serverRetryMax)
+	}
+}
+
+// sendServerDown reports that one of sentrylogmon's own servers failed.
+func sendServerDown(hub *sentry.Hub, name string, err error) {
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("alert_type", "server_down")
+		scope.SetTag("server", name)
+		scope.SetLevel(sentry.LevelWarning)
+		hub.CaptureMessage("sentrylogmon " + name + " server down: " + err.Error())
+	})
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/angch/sentrylogmon/config"
+	"github.com/angch/sentrylogmon/ipc"
+	"github.com/getsentry/sentry-go"
+)
+
+// waitFor polls cond until it holds or timeout passes.
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestSuperviseServerRecreatesIPCSocket(t *testing.T) {
+	oldRetryMin, oldCheckInterval := serverRetryMin, ipc.SocketCheckInterval
+	serverRetryMin = 10 * time.Millisecond
+	ipc.SocketCheckInterval = 20 * time.Millisecond
+	t.Cleanup(func() {
+		serverRetryMin = oldRetryMin
+		ipc.SocketCheckInterval = oldCheckInterval
+	})
+
+	transport := &recordingTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	socketDir := t.TempDir()
+	socketPath := ipc.SocketPath(socketDir, os.Getpid())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go superviseServer(ctx, "IPC", func() error {
+		return ipc.StartServer(socketPath, func() *config.Config { return &config.Config{} }, nil, nil, nil)
+	}, hub)
+
+	exists := func() bool {
+		_, err := os.Stat(socketPath)
+		return err == nil
+	}
+	if !waitFor(2*time.Second, exists) {
+		t.Fatal("IPC socket was not created")
+	}
+
+	if err := os.Remove(socketPath); err != nil {
+		t.Fatalf("Failed to remove socket: %v", err)
+	}
+	if !waitFor(2*time.Second, exists) {
+		t.Fatal("IPC socket was not recreated after being removed")
+	}
+
+	instances, err := ipc.ListInstances(socketDir)
+	if err != nil {
+		t.Fatalf("ListInstances failed: %v", err)
+	}
+	if len(instances) != 1 || instances[0].PID != os.Getpid() {
+		t.Errorf("Expected the recreated socket to answer, got %+v", instances)
+	}
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 server_down event, got %d", len(transport.events))
+	}
+	event := transport.events[0]
+	if event.Tags["alert_type"] != "server_down" || event.Tags["server"] != "IPC" || event.Level != sentry.LevelWarning {
+		t.Errorf("Unexpected event: level=%s tags=%v", event.Level, event.Tags)
+	}
+}
@@ -3,15 +3,15 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 	"time"
 
 	"github.com/angch/sentrylogmon/config"
 	"github.com/fsnotify/fsnotify"
-	"gopkg.in/yaml.v3"
 )
 
-func watchConfig(ctx context.Context, configPath string, onReload func()) {
+// watchConfig calls onReload when any of the config files changes and,
+// overlaid in order, they still make a valid configuration.
+func watchConfig(ctx context.Context, configPaths []string, onReload func()) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		log.Printf("Failed to create file watcher: %v", err)
@@ -19,13 +19,14 @@ func watchConfig(ctx context.Context, configPath string, onReload func()) {
 	}
 	defer watcher.Close()
 
-	if err := watcher.Add(configPath); err != nil {
-		log.Printf("Failed to watch config file %s: %v", configPath, err)
-		return
+	for _, configPath := range configPaths {
+		if err := watcher.Add(configPath); err != nil {
+			log.Printf("Failed to watch config file %s: %v", configPath, err)
+			return
+		}
+		log.Printf("Watching config file %s for changes...", configPath)
 	}
 
-	log.Printf("Watching config file %s for changes...", configPath)
-
 	var debounceTimer *time.Timer
 	const debounceDuration = 500 * time.Millisecond
 
@@ -47,10 +48,10 @@ func watchConfig(ctx context.Context, configPath string, onReload func()) {
 				if event.Has(fsnotify.Rename) || event.Has(fsnotify.Remove) {
 					// Wait a bit for the new file to appear
 					time.Sleep(100 * time.Millisecond)
-					if err := watcher.Add(configPath); err != nil {
+					if err := watcher.Add(event.Name); err != nil {
 						// If we can't re-add, maybe it's gone for good or permission issue.
 						// Log and continue (maybe retry later? but we keep loop)
-						log.Printf("Config file %s renamed/removed and could not be re-watched: %v", configPath, err)
+						log.Printf("Config file %s renamed/removed and could not be re-watched: %v", event.Name, err)
 						continue
 					}
 				}
@@ -60,15 +61,9 @@ func watchConfig(ctx context.Context, configPath string, onReload func()) {
 				}
 				debounceTimer = time.AfterFunc(debounceDuration, func() {
 					// Validate config
-					data, err := os.ReadFile(configPath)
+					cfg, err := config.ReadFiles(configPaths...)
 					if err != nil {
-						log.Printf("Failed to read config file during reload check: %v", err)
-						return
-					}
-
-					var cfg config.Config
-					if err := yaml.Unmarshal(data, &cfg); err != nil {
-						log.Printf("Config file changed but is invalid (YAML error), ignoring reload: %v", err)
+						log.Printf("Config file changed but could not be read or is invalid (YAML error), ignoring reload: %v", err)
 						return
 					}
 
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -39,7 +40,7 @@ monitors:
 	}
 
 	// Start watcher
-	go watchConfig(ctx, tmpfile.Name(), onReload)
+	go watchConfig(ctx, []string{tmpfile.Name()}, onReload)
 
 	// Wait for watcher to start (naive sleep, but fsnotify startup is fast)
 	time.Sleep(100 * time.Millisecond)
@@ -90,7 +91,7 @@ sentry:
 		reloadCh <- struct{}{}
 	}
 
-	go watchConfig(ctx, tmpfile.Name(), onReload)
+	go watchConfig(ctx, []string{tmpfile.Name()}, onReload)
 	time.Sleep(100 * time.Millisecond)
 
 	// Test Case 2: Invalid Change (Bad YAML)
@@ -110,3 +111,41 @@ sentry:
 		// Success: should NOT be called
 	}
 }
+
+func TestWatchConfig_Overlay(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	overlay := filepath.Join(dir, "overlay.yaml")
+	// Only valid together: the DSN is in the base, the monitors in the overlay
+	if err := os.WriteFile(base, []byte("sentry:\n  dsn: \"https://example@sentry.io/123\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overlay, []byte("monitors:\n  - name: app\n    type: file\n    path: /tmp/app.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloadCh := make(chan struct{}, 1)
+	onReload := func() {
+		select {
+		case reloadCh <- struct{}{}:
+		default:
+		}
+	}
+
+	go watchConfig(ctx, []string{base, overlay}, onReload)
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.WriteFile(overlay, []byte("monitors:\n  - name: app-reloaded\n    type: file\n    path: /tmp/app.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-reloadCh:
+		// Success: the overlay is validated together with the base
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for reload callback on overlay change")
+	}
+}